@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/qzeleza/zlogger"
+)
+
+// connect подключается к уже запущенному серверу логгера
+// Отсутствие сокета и нехватка прав сообщаются понятным текстом, а не ошибкой сокета.
+func connect(socketPath string) (*zlogger.Logger, error) {
+	if _, err := os.Stat(socketPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("сокет %s не найден: сервер логгера не запущен или указан неверный путь (флаг -socket)", socketPath)
+	}
+
+	config := &zlogger.Config{
+		Level:                "info",
+		SocketPath:           socketPath,
+		ReconnectMaxAttempts: 1, // Утилита не должна ждать перезапуска сервера
+	}
+
+	log, err := zlogger.Connect(config)
+	switch {
+	case errors.Is(err, zlogger.ErrSocketPermission):
+		return nil, fmt.Errorf("нет прав доступа к сокету %s: запустите команду от пользователя группы сокета", socketPath)
+	case errors.Is(err, zlogger.ErrServerUnavailable):
+		return nil, fmt.Errorf("сервер логгера на сокете %s не отвечает: %w", socketPath, err)
+	case err != nil:
+		return nil, err
+	}
+	return log, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/qzeleza/zlogger"
+)
+
+// runExport выгружает записи лога запущенного сервера в NDJSON или CSV
+//
+// Пример: zlogger export -format ndjson -service API | jq .message
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	socketPath := fs.String("socket", defaultSocketPath, "путь к сокету сервера логгера")
+	format := fs.String("format", "ndjson", "формат выгрузки: ndjson или csv")
+	fields := fs.String("fields", "none", "выгрузка дополнительных полей в CSV: none, json или columns")
+	output := fs.String("output", "", "файл для выгрузки (по умолчанию stdout)")
+	var filterArgs filterFlags
+	filterArgs.register(fs, 0)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter, err := filterArgs.options()
+	if err != nil {
+		return err
+	}
+
+	var csvOptions zlogger.CSVOptions
+	switch *fields {
+	case "none":
+	case "json":
+		csvOptions.Fields = zlogger.CSVFieldsJSON
+	case "columns":
+		csvOptions.Fields = zlogger.CSVFieldsColumns
+	default:
+		return fmt.Errorf("неизвестный способ выгрузки полей: %s", *fields)
+	}
+
+	if *format != "ndjson" && *format != "csv" {
+		return fmt.Errorf("неизвестный формат выгрузки: %s (ожидается ndjson или csv)", *format)
+	}
+
+	log, err := connect(*socketPath)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	export := func(w io.Writer) error {
+		if *format == "csv" {
+			return log.ExportCSVWithOptions(w, filter, csvOptions)
+		}
+		return log.ExportNDJSON(w, filter)
+	}
+
+	if *output == "" {
+		return export(os.Stdout)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла выгрузки: %w", err)
+	}
+	if err := export(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qzeleza/zlogger"
+)
+
+// filterFlags флаги фильтрации записей, общие для команд чтения лога
+type filterFlags struct {
+	service  string
+	level    string
+	limit    int
+	since    string
+	until    string
+	contains string
+	rotated  bool
+}
+
+// register добавляет флаги фильтрации в набор флагов команды
+func (f *filterFlags) register(fs *flag.FlagSet, defaultLimit int) {
+	fs.StringVar(&f.service, "service", "", "сервис или несколько сервисов через запятую")
+	fs.StringVar(&f.level, "level", "", "минимальный уровень записей (trace, debug, info, warn, error, fatal, panic)")
+	fs.IntVar(&f.limit, "limit", defaultLimit, "максимальное количество записей (0 - без ограничения)")
+	fs.StringVar(&f.since, "since", "", "начало интервала: время RFC3339 или давность (например, 15m, 2h)")
+	fs.StringVar(&f.until, "until", "", "конец интервала: время RFC3339 или давность")
+	fs.StringVar(&f.contains, "contains", "", "подстрока, которую должно содержать сообщение")
+	fs.BoolVar(&f.rotated, "rotated", false, "искать и в резервных копиях лога, если -since раньше начала файла")
+}
+
+// options преобразует флаги в FilterOptions
+func (f *filterFlags) options() (zlogger.FilterOptions, error) {
+	filter := zlogger.FilterOptions{
+		Limit:         f.limit,
+		Contains:      f.contains,
+		SearchRotated: f.rotated,
+	}
+
+	if services := strings.Split(f.service, ","); len(services) > 1 {
+		for _, service := range services {
+			if service = strings.TrimSpace(service); service != "" {
+				filter.Services = append(filter.Services, service)
+			}
+		}
+	} else {
+		filter.Service = strings.TrimSpace(f.service)
+	}
+
+	if f.level != "" {
+		level, err := zlogger.ParseLevel(f.level)
+		if err != nil {
+			return filter, err
+		}
+		filter.MinLevel = &level
+	}
+
+	if f.since != "" {
+		since, err := parseTimeFlag("since", f.since)
+		if err != nil {
+			return filter, err
+		}
+		filter.StartTime = &since
+	}
+
+	if f.until != "" {
+		until, err := parseTimeFlag("until", f.until)
+		if err != nil {
+			return filter, err
+		}
+		filter.EndTime = &until
+	}
+
+	return filter, nil
+}
+
+// parseTimeFlag разбирает время в формате RFC3339 или давность относительно текущего момента
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("неверное значение -%s: %s (ожидается время RFC3339 или давность, например 15m)", name, value)
+}
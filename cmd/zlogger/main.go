@@ -1,14 +1,71 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/qzeleza/zlogger"
 )
 
+// defaultSocketPath путь к сокету сервера по умолчанию для подкоманд
+const defaultSocketPath = "/tmp/zlogger.sock"
+
 func main() {
+	// Без аргументов запускается демонстрация возможностей библиотеки
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+	runDemo()
+}
+
+// runCommand выполняет подкоманду и возвращает код завершения
+func runCommand(name string, args []string) int {
+	var err error
+	switch name {
+	case "serve":
+		err = runServe(args)
+	case "query":
+		err = runQuery(args)
+	case "export":
+		err = runExport(args)
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Неизвестная команда: %s\n\n", name)
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printUsage выводит список подкоманд
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Использование: zlogger <команда> [флаги]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Команды:")
+	fmt.Fprintln(w, "  serve    запустить сервер логгера отдельным процессом")
+	fmt.Fprintln(w, "  query    вывести записи лога, подходящие под фильтр")
+	fmt.Fprintln(w, "  export   выгрузить записи лога в NDJSON или CSV")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Без команды запускается демонстрация возможностей библиотеки.")
+	fmt.Fprintln(w, "Флаги команды: zlogger <команда> -h")
+}
+
+// runDemo демонстрирует возможности библиотеки на временном логе
+func runDemo() {
 	// Создаем конфигурацию с настройками по умолчанию
 	config := zlogger.NewConfig("/tmp/example.log", "/tmp/example.sock")
 
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runQuery выводит записи лога запущенного сервера, подходящие под фильтр
+//
+// Пример: zlogger query -service API -level warn -since 1h -contains timeout
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	socketPath := fs.String("socket", defaultSocketPath, "путь к сокету сервера логгера")
+	asJSON := fs.Bool("json", false, "выводить записи в JSON (по одной на строку)")
+	var filterArgs filterFlags
+	filterArgs.register(fs, 100)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter, err := filterArgs.options()
+	if err != nil {
+		return err
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	// Текстовый вывод печатает записи в том виде, в каком они хранятся в файле
+	filter.IncludeRaw = !*asJSON
+
+	log, err := connect(*socketPath)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	entries, err := log.GetLogEntries(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "Записи не найдены")
+		return nil
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, entry := range entries {
+		if *asJSON {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+			continue
+		}
+		// Raw содержит запись в том виде, в каком она хранится в файле лога
+		fmt.Println(entry.Raw)
+	}
+	return nil
+}
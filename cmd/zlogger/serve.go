@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/qzeleza/zlogger"
+)
+
+// runServe запускает сервер логгера отдельным процессом до получения SIGTERM или SIGINT
+//
+// Пример: zlogger serve -log-file /var/log/app.log -socket /run/app.sock -level debug
+//
+// С флагом -config параметры читаются из файла YAML или JSON, а явно указанные
+// флаги переопределяют значения из файла. SIGHUP перечитывает файл и применяет
+// параметры, не требующие перезапуска (уровень, ротация, интервал сброса).
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configPath := fs.String("config", "", "файл конфигурации YAML или JSON")
+	logFile := fs.String("log-file", "/tmp/zlogger.log", "путь к файлу лога")
+	socketPath := fs.String("socket", defaultSocketPath, "путь к сокету, через который подключаются клиенты")
+	level := fs.String("level", "info", "минимальный уровень записываемых сообщений")
+	maxSize := fs.Float64("max-size", 1, "размер файла лога в МБ, после которого выполняется ротация")
+	maxFiles := fs.Int("max-files", 3, "количество хранимых файлов лога вместе с текущим")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// applyFlags переносит в конфигурацию флаги; без файла - все, с файлом - только указанные явно
+	applyFlags := func(config *zlogger.Config, all bool) {
+		set := func(f *flag.Flag) {
+			switch f.Name {
+			case "log-file":
+				config.LogFile = *logFile
+			case "socket":
+				config.SocketPath = *socketPath
+			case "level":
+				config.Level = *level
+			case "max-size":
+				config.MaxFileSize = *maxSize
+			case "max-files":
+				config.MaxFiles = *maxFiles
+			}
+		}
+		if all {
+			fs.VisitAll(set)
+		} else {
+			fs.Visit(set)
+		}
+	}
+
+	loadConfig := func() (*zlogger.Config, error) {
+		if *configPath == "" {
+			config := zlogger.NewConfig(*logFile, *socketPath)
+			applyFlags(config, true)
+			return config, nil
+		}
+		config, err := zlogger.LoadConfig(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		applyFlags(config, false)
+		return config, nil
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := zlogger.ParseLevel(config.Level); err != nil {
+		return err
+	}
+
+	server, err := zlogger.NewServer(config)
+	if err != nil {
+		return fmt.Errorf("ошибка создания сервера: %w", err)
+	}
+	if err := server.Start(); err != nil {
+		return fmt.Errorf("ошибка запуска сервера: %w", err)
+	}
+
+	fmt.Println("Сервер логгера запущен:")
+	printConfig(os.Stdout, config)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for sig := range signals {
+		if sig != syscall.SIGHUP {
+			fmt.Printf("Получен сигнал %v, остановка сервера\n", sig)
+			break
+		}
+		if *configPath == "" {
+			fmt.Println("Получен SIGHUP, но файл конфигурации не задан (-config)")
+			continue
+		}
+
+		// Ошибка перечитывания не останавливает сервер: продолжаем с прежней конфигурацией
+		newConfig, err := loadConfig()
+		if err == nil {
+			err = server.Reload(newConfig)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка перечитывания конфигурации: %v\n", err)
+			continue
+		}
+		fmt.Println("Конфигурация перечитана:")
+		printConfig(os.Stdout, newConfig)
+	}
+
+	if err := server.Stop(); err != nil {
+		return fmt.Errorf("ошибка остановки сервера: %w", err)
+	}
+	return nil
+}
+
+// printConfig выводит действующую конфигурацию сервера
+func printConfig(w io.Writer, config *zlogger.Config) {
+	for _, line := range [][2]string{
+		{"файл лога", config.LogFile},
+		{"сокет", config.SocketPath},
+		{"уровень", config.Level},
+		{"размер файла", fmt.Sprintf("%g МБ", config.MaxFileSize)},
+		{"файлов лога", fmt.Sprint(config.MaxFiles)},
+		{"буфер", fmt.Sprintf("%d сообщений", config.BufferSize)},
+		{"интервал сброса", config.FlushInterval.String()},
+	} {
+		fmt.Fprintf(w, "  %-16s %s\n", line[0]+":", line[1])
+	}
+}
@@ -210,7 +210,7 @@ func TestLogServerHelperMethods(t *testing.T) {
 
 	// Создаем минимальную структуру сервера для тестирования методов
 	server := &LogServer{
-		file:        file,
+		logFile:     FileSink{file: file},
 		currentSize: 0,
 		stats:       ServerStats{StartTime: time.Now()},
 		config: &LoggingConfig{
@@ -267,58 +267,68 @@ func TestLogServerHelperMethods(t *testing.T) {
  * @param t *testing.T - тестовый контекст
  */
 func TestSecurityCleanupExtended(t *testing.T) {
+	clock := NewFakeClock(time.Now())
 	config := DefaultSecurityConfig()
 	config.RateLimitPerSecond = 1
 	config.BanDuration = time.Millisecond * 100 // Короткий бан для тестов
+	config.Clock = clock
 
 	limiter := NewRateLimiter(config)
 	defer limiter.Close()
 
 	// Добавляем клиента в бан
 	clientID := "test-client"
+	limiter.mu.Lock()
 	limiter.clients[clientID] = &ClientInfo{
 		MessageCount:  config.RateLimitPerSecond + 1,
-		LastAccess:    time.Now(),
-		BannedUntil:   time.Now().Add(config.BanDuration),
+		LastAccess:    clock.Now(),
+		BannedUntil:   clock.Now().Add(config.BanDuration),
 		TotalMessages: int64(config.RateLimitPerSecond + 1),
 	}
+	limiter.mu.Unlock()
 
-	// Ждем окончания бана
-	time.Sleep(config.BanDuration + time.Millisecond*50)
-
-	// Сбрасываем счетчик сообщений для клиента, чтобы он мог снова делать запросы
-	limiter.mu.Lock()
-	if clientInfo, exists := limiter.clients[clientID]; exists {
-		clientInfo.MessageCount = 0          // Сбрасываем счетчик
-		clientInfo.BannedUntil = time.Time{} // Убираем бан
+	if limiter.IsAllowed(clientID) {
+		t.Error("клиент должен быть забанен до истечения времени бана")
 	}
-	limiter.mu.Unlock()
 
-	// Проверяем, что клиент может снова делать запросы
+	// Бан заканчивается по часам ограничителя
+	clock.Advance(config.BanDuration + time.Millisecond*50)
 	if !limiter.IsAllowed(clientID) {
 		t.Error("клиент должен быть разбанен после истечения времени бана")
 	}
 
-	// Тестируем очистку старых записей
-	// Добавляем старую запись
+	// Старый клиент, бан которого закончился без новых сообщений: очистка
+	// сообщает об окончании бана и удаляет запись
+	unbanned := make(chan BanEvent, 1)
+	limiter.SetBanHandler(func(event BanEvent) { unbanned <- event })
+
 	oldClientID := "old-client"
+	limiter.mu.Lock()
 	limiter.clients[oldClientID] = &ClientInfo{
 		MessageCount:  1,
-		LastAccess:    time.Now().Add(-time.Hour), // Очень старая запись
-		BannedUntil:   time.Time{},
+		LastAccess:    clock.Now().Add(-2 * time.Hour), // Очень старая запись
+		BannedUntil:   clock.Now().Add(-2 * time.Hour),
 		TotalMessages: 1,
+		banned:        true,
 	}
+	limiter.mu.Unlock()
 
-	// Даем время cleanup горутине поработать
-	time.Sleep(time.Millisecond * 200)
+	// Срабатывание таймера очистки (раз в 10 минут)
+	clock.Advance(10 * time.Minute)
+
+	select {
+	case event := <-unbanned:
+		if event.ClientID != oldClientID || event.Banned {
+			t.Errorf("ожидалось окончание бана %s, получено %+v", oldClientID, event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("очистка не выполнена после срабатывания таймера")
+	}
 
-	// Проверяем, что старые записи могут быть очищены
-	// (это зависит от реализации cleanup, но мы тестируем, что нет паники)
 	limiter.mu.RLock()
-	clientsCount := len(limiter.clients)
+	_, exists := limiter.clients[oldClientID]
 	limiter.mu.RUnlock()
-
-	if clientsCount < 0 {
-		t.Error("количество клиентов не может быть отрицательным")
+	if exists {
+		t.Error("неактивный клиент должен быть удален очисткой")
 	}
 }
@@ -0,0 +1,123 @@
+// batch.go - Объединение сообщений клиента в пакеты протокола
+package logger
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// frameOverhead запас на обертку ProtocolMessage вокруг сообщения или массива сообщений в байтах
+const frameOverhead = 64
+
+// batchEntry сообщение, ожидающее отправки в пакете
+type batchEntry struct {
+	msg LogMessage      // Сообщение для вывода в stderr при ошибке отправки
+	raw json.RawMessage // Сообщение, закодированное при добавлении в пакет
+}
+
+// batching сообщает, объединяет ли клиент сообщения в пакеты
+func (c *LogClient) batching() bool {
+	return c.config != nil && c.config.BatchSize > 1
+}
+
+// batchInterval максимальная задержка отправки неполного пакета
+func (c *LogClient) batchInterval() time.Duration {
+	if c.config.BatchInterval > 0 {
+		return c.config.BatchInterval
+	}
+	return time.Duration(DEFAULT_BATCH_INTERVAL_MS) * time.Millisecond
+}
+
+// enqueueBatch добавляет сообщение в пакет
+// Пакет отправляется при достижении BatchSize или предельного размера кадра,
+// по таймеру BatchInterval и сразу после сообщения уровня ERROR и выше, чтобы
+// важные сообщения не задерживались. Ошибка отправки возвращается вызову,
+// который ее инициировал, а сообщения пакета выводятся в stderr.
+func (c *LogClient) enqueueBatch(msg LogMessage) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		c.fallbackToStderr(msg.Service, msg.Level, msg.Message, msg.Timestamp, msg.Fields)
+		return err
+	}
+
+	c.batchMu.Lock()
+	defer c.batchMu.Unlock()
+
+	if c.closed.Load() {
+		c.fallbackToStderr(msg.Service, msg.Level, msg.Message, msg.Timestamp, msg.Fields)
+		return ErrClosed
+	}
+
+	// Сообщение не помещается в кадр вместе с накопленными - отправляем их
+	if len(c.batch) > 0 && c.batchBytes+len(raw)+1+frameOverhead > c.frameLimit() {
+		err = c.sendBatchLocked()
+	}
+
+	c.batch = append(c.batch, batchEntry{msg: msg, raw: raw})
+	c.batchBytes += len(raw) + 1
+
+	if len(c.batch) >= c.config.BatchSize || msg.Level >= ERROR {
+		if sendErr := c.sendBatchLocked(); err == nil {
+			err = sendErr
+		}
+		return err
+	}
+
+	if c.batchTimer == nil {
+		c.batchTimer = time.AfterFunc(c.batchInterval(), func() { _ = c.flushBatch() })
+	}
+	return err
+}
+
+// flushBatch отправляет накопленный пакет
+func (c *LogClient) flushBatch() error {
+	c.batchMu.Lock()
+	defer c.batchMu.Unlock()
+	return c.sendBatchLocked()
+}
+
+// sendBatchLocked отправляет накопленные сообщения (вызывается под batchMu)
+// Сервер без поддержки MsgTypeLogBatch получает сообщения по одному.
+func (c *LogClient) sendBatchLocked() error {
+	if c.batchTimer != nil {
+		c.batchTimer.Stop()
+		c.batchTimer = nil
+	}
+	if len(c.batch) == 0 {
+		return nil
+	}
+
+	batch := c.batch
+	defer func() {
+		clear(batch)
+		c.batch = batch[:0]
+		c.batchBytes = 0
+	}()
+
+	capabilities := c.serverCaps.Load()
+	if len(batch) == 1 || capabilities == nil || !capabilities.Supports(MsgTypeLogBatch) {
+		var firstErr error
+		for _, entry := range batch {
+			if err := c.sendFrame(ProtocolMessage{Type: MsgTypeLog, Data: entry.raw}); err != nil {
+				c.fallbackToStderr(entry.msg.Service, entry.msg.Level, entry.msg.Message, entry.msg.Timestamp, entry.msg.Fields)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+		return firstErr
+	}
+
+	messages := make([]json.RawMessage, len(batch))
+	for i, entry := range batch {
+		messages[i] = entry.raw
+	}
+
+	if err := c.sendFrame(ProtocolMessage{Type: MsgTypeLogBatch, Data: messages}); err != nil {
+		for _, entry := range batch {
+			c.fallbackToStderr(entry.msg.Service, entry.msg.Level, entry.msg.Message, entry.msg.Timestamp, entry.msg.Fields)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// batch_test.go - Тесты объединения сообщений клиента в пакеты
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logFileContains проверяет наличие подстроки в файле лога после сброса буфера сервера
+func logFileContains(t *testing.T, server *LogServer, substr string) bool {
+	t.Helper()
+
+	server.Flush()
+	content, err := os.ReadFile(server.config.LogFile)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("не удалось прочитать лог: %v", err)
+	}
+	return strings.Contains(string(content), substr)
+}
+
+// TestClientBatching проверяет отправку пакета по размеру, по уровню ERROR и при Flush
+func TestClientBatching(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.BatchSize = 3
+	config.BatchInterval = time.Hour // Отправка по таймеру не должна вмешиваться
+	server, client := startTestServerWithClient(t, config)
+
+	if caps := client.serverCaps.Load(); caps == nil || !caps.Supports(MsgTypeLogBatch) {
+		t.Fatal("сервер должен объявлять поддержку пакетов")
+	}
+
+	// Неполный пакет остается у клиента до Flush
+	_ = client.Info("ожидает-1")
+	_ = client.Info("ожидает-2")
+	if logFileContains(t, server, "ожидает-1") {
+		t.Fatal("неполный пакет не должен отправляться до Flush")
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка Flush: %v", err)
+	}
+	if !logFileContains(t, server, "ожидает-2") {
+		t.Fatal("Flush должен отправить накопленный пакет")
+	}
+
+	// Полный пакет отправляется сразу
+	for i := 1; i <= 3; i++ {
+		_ = client.Info(fmt.Sprintf("полный-%d", i))
+	}
+	waitForLogContent(t, server, "полный-3")
+
+	// ERROR отправляет пакет вместе с накопленными сообщениями
+	_ = client.Info("перед-ошибкой")
+	_ = client.Error("ошибка")
+	waitForLogContent(t, server, "ошибка")
+	if !logFileContains(t, server, "перед-ошибкой") {
+		t.Error("сообщения до ERROR должны отправляться вместе с ним")
+	}
+
+	// Порядок сообщений сохраняется
+	content, _ := os.ReadFile(config.LogFile)
+	text := string(content)
+	if strings.Index(text, "ожидает-1") > strings.Index(text, "полный-1") ||
+		strings.Index(text, "полный-3") > strings.Index(text, "перед-ошибкой") {
+		t.Errorf("нарушен порядок сообщений:\n%s", text)
+	}
+}
+
+// TestClientBatchingInterval проверяет отправку неполного пакета по таймеру
+func TestClientBatchingInterval(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.BatchSize = 100
+	server, client := startTestServerWithClient(t, config)
+
+	_ = client.Info("по таймеру")
+	waitForLogContent(t, server, "по таймеру")
+}
+
+// TestClientBatchingFrameLimit проверяет, что пакет не превышает MaxMessageSize сервера
+func TestClientBatchingFrameLimit(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.BatchSize = 100
+	config.BatchInterval = time.Hour
+	config.RateLimit = 1000
+	server, client := startTestServerWithClient(t, config)
+
+	payload := strings.Repeat("x", 200)
+	for i := 0; i < 50; i++ {
+		if err := client.Info(fmt.Sprintf("кадр-%02d %s", i, payload)); err != nil {
+			t.Fatalf("ошибка отправки сообщения %d: %v", i, err)
+		}
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка Flush: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		if !logFileContains(t, server, fmt.Sprintf("кадр-%02d", i)) {
+			t.Fatalf("сообщение %d не записано: кадр пакета превысил лимит сервера", i)
+		}
+	}
+}
+
+// TestClientBatchingOldServer проверяет отправку по одному, если сервер не знает пакетов
+func TestClientBatchingOldServer(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.BatchSize = 10
+	config.BatchInterval = time.Hour
+	server, client := startTestServerWithClient(t, config)
+
+	client.serverCaps.Store(&Capabilities{ProtocolVersion: PROTOCOL_VERSION, MessageTypes: []string{MsgTypeLog, MsgTypeFlush}})
+
+	_ = client.Info("старый-1")
+	_ = client.Info("старый-2")
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка Flush: %v", err)
+	}
+	if !logFileContains(t, server, "старый-1") || !logFileContains(t, server, "старый-2") {
+		t.Error("сообщения должны доставляться старому серверу по одному")
+	}
+}
+
+// TestClientBatchingClose проверяет отправку накопленного пакета при закрытии клиента
+func TestClientBatchingClose(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.BatchSize = 10
+	config.BatchInterval = time.Hour
+	server, client := startTestServerWithClient(t, config)
+
+	_ = client.Info("при закрытии")
+	if err := client.Close(); err != nil {
+		t.Fatalf("ошибка Close: %v", err)
+	}
+	waitForLogContent(t, server, "при закрытии")
+
+	if err := client.Info("после закрытия"); err != ErrClosed {
+		t.Errorf("ожидалась ErrClosed, получено %v", err)
+	}
+}
@@ -4,6 +4,7 @@ package logger
 import (
 	"container/list"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 )
@@ -17,6 +18,12 @@ type LogCache struct {
 	ttl     time.Duration            // Время жизни записей
 	stats   CacheStats               // Статистика кеша
 	done    chan struct{}            // Канал для остановки cleanup горутины
+	clock   Clock                    // Источник времени для TTL
+
+	// Отслеживание полноты кеша для ответа на запросы без чтения файла
+	nextSeq      uint64    // Порядковый номер следующей добавленной записи
+	maxSeen      time.Time // Максимальное время записи, когда-либо добавленной в кеш
+	evictedUntil time.Time // Максимальное время записи, удаленной из кеша
 }
 
 // CacheEntry элемент кеша с метаданными
@@ -24,6 +31,7 @@ type CacheEntry struct {
 	Key       string    // Ключ записи
 	Entry     LogEntry  // Запись лога
 	Timestamp time.Time // Время добавления в кеш
+	seq       uint64    // Порядок добавления (для восстановления хронологии)
 }
 
 // CacheStats статистика работы кеша
@@ -36,17 +44,24 @@ type CacheStats struct {
 
 // NewLogCache создает новый кеш записей лога
 func NewLogCache(maxSize int, ttl time.Duration) *LogCache {
+	return NewLogCacheWithClock(maxSize, ttl, nil)
+}
+
+// NewLogCacheWithClock создает кеш, отсчитывающий TTL по часам clock (nil - SystemClock)
+func NewLogCacheWithClock(maxSize int, ttl time.Duration, clock Clock) *LogCache {
 	cache := &LogCache{
 		entries: list.New(),
 		lookup:  make(map[string]*list.Element),
 		maxSize: maxSize,
 		ttl:     ttl,
 		done:    make(chan struct{}),
+		clock:   clockOrSystem(clock),
 	}
 
-	// Запускаем фоновую очистку устаревших записей
+	// Запускаем фоновую очистку устаревших записей; таймер создается до запуска
+	// горутины, чтобы срабатывания управляемых часов не терялись
 	if ttl > 0 {
-		go cache.cleanupExpired()
+		go cache.cleanupExpired(cache.clock.NewTicker(ttl / 2)) // Проверяем дважды за TTL
 	}
 
 	// Регистрируем финалайзер, чтобы гарантировать остановку goroutine,
@@ -59,6 +74,7 @@ func NewLogCache(maxSize int, ttl time.Duration) *LogCache {
 }
 
 // Get получает запись из кеша
+// Найденная запись становится самой свежей по обращению и вытесняется последней (LRU)
 func (c *LogCache) Get(key string) (*LogEntry, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -72,7 +88,7 @@ func (c *LogCache) Get(key string) (*LogEntry, bool) {
 	entry := element.Value.(*CacheEntry)
 
 	// Проверяем TTL
-	if c.ttl > 0 && time.Since(entry.Timestamp) > c.ttl {
+	if c.ttl > 0 && c.clock.Now().Sub(entry.Timestamp) > c.ttl {
 		c.removeElement(element)
 		c.stats.Misses++
 		return nil, false
@@ -95,7 +111,7 @@ func (c *LogCache) Put(key string, entry LogEntry) {
 		// Обновляем существующую запись
 		cacheEntry := element.Value.(*CacheEntry)
 		cacheEntry.Entry = entry
-		cacheEntry.Timestamp = time.Now()
+		cacheEntry.Timestamp = c.clock.Now()
 		c.entries.MoveToFront(element)
 		return
 	}
@@ -104,7 +120,12 @@ func (c *LogCache) Put(key string, entry LogEntry) {
 	cacheEntry := &CacheEntry{
 		Key:       key,
 		Entry:     entry,
-		Timestamp: time.Now(),
+		Timestamp: c.clock.Now(),
+		seq:       c.nextSeq,
+	}
+	c.nextSeq++
+	if entry.Timestamp.After(c.maxSeen) {
+		c.maxSeen = entry.Timestamp
 	}
 
 	element := c.entries.PushFront(cacheEntry)
@@ -129,23 +150,25 @@ func (c *LogCache) evictOldest() {
 // removeElement удаляет элемент из кеша
 func (c *LogCache) removeElement(element *list.Element) {
 	entry := element.Value.(*CacheEntry)
+	if entry.Entry.Timestamp.After(c.evictedUntil) {
+		c.evictedUntil = entry.Entry.Timestamp
+	}
 	delete(c.lookup, entry.Key)
 	c.entries.Remove(element)
 	c.stats.Size--
 }
 
 // cleanupExpired очищает устаревшие записи в фоне
-func (c *LogCache) cleanupExpired() {
-	ticker := time.NewTicker(c.ttl / 2) // Проверяем дважды за TTL
+func (c *LogCache) cleanupExpired(ticker Ticker) {
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-c.done:
 			return // Завершаем горутину
-		case <-ticker.C:
+		case <-ticker.C():
 			c.mu.Lock()
-			now := time.Now()
+			now := c.clock.Now()
 
 			// Проходим по списку с конца (самые старые)
 			for element := c.entries.Back(); element != nil; {
@@ -180,6 +203,41 @@ func (c *LogCache) Clear() {
 	c.entries.Init()
 	c.lookup = make(map[string]*list.Element)
 	c.stats.Size = 0
+	if c.maxSeen.After(c.evictedUntil) {
+		c.evictedUntil = c.maxSeen
+	}
+}
+
+// EntriesSince возвращает записи со временем не раньше since в порядке добавления
+// Второе значение false означает, что кеш не может гарантировать полноту результата:
+// из него уже удалялись записи с временем since или позже.
+func (c *LogCache) EntriesSince(since time.Time) ([]LogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !since.After(c.evictedUntil) {
+		return nil, false
+	}
+
+	matched := make([]*CacheEntry, 0, c.entries.Len())
+	for element := c.entries.Front(); element != nil; element = element.Next() {
+		cacheEntry := element.Value.(*CacheEntry)
+		if !cacheEntry.Entry.Timestamp.Before(since) {
+			matched = append(matched, cacheEntry)
+		}
+	}
+
+	// Порядок списка зависит от обращений (LRU), восстанавливаем порядок добавления
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].seq < matched[j].seq
+	})
+
+	entries := make([]LogEntry, len(matched))
+	for i, cacheEntry := range matched {
+		entries[i] = cacheEntry.Entry
+	}
+
+	return entries, true
 }
 
 // Close останавливает cleanup горутину LogCache
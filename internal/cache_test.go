@@ -234,10 +234,78 @@ func TestLogCacheEviction(t *testing.T) {
 	}
 }
 
+// TestLogCacheEvictionLRU проверяет, что вытесняется запись, к которой дольше всего не обращались
+func TestLogCacheEvictionLRU(t *testing.T) {
+	maxSize := 3
+	cache := NewLogCache(maxSize, 0)
+	defer cache.Close()
+
+	for i := 0; i < maxSize; i++ {
+		cache.Put(fmt.Sprintf("key_%d", i), LogEntry{Message: fmt.Sprintf("message %d", i)})
+	}
+
+	// Читаем самую старую по добавлению запись
+	if _, found := cache.Get("key_0"); !found {
+		t.Fatal("запись key_0 должна быть в кеше")
+	}
+
+	// Добавление сверх лимита вытесняет key_1, а не прочитанную key_0
+	cache.Put("key_overflow", LogEntry{Message: "overflow"})
+
+	if _, found := cache.Get("key_0"); !found {
+		t.Error("недавно прочитанная запись не должна вытесняться")
+	}
+	if _, found := cache.Get("key_1"); found {
+		t.Error("должна быть вытеснена запись, к которой дольше всего не обращались")
+	}
+}
+
+// TestLogCacheEntriesSince проверяет выборку записей и контроль полноты кеша
+func TestLogCacheEntriesSince(t *testing.T) {
+	cache := NewLogCache(3, 0)
+	defer cache.Close()
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		cache.Put(fmt.Sprintf("key_%d", i), LogEntry{
+			Message:   fmt.Sprintf("message %d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	// Обращение меняет порядок LRU, но не порядок выдачи
+	_, _ = cache.Get("key_0")
+
+	entries, ok := cache.EntriesSince(base.Add(time.Second))
+	if !ok {
+		t.Fatal("кеш должен быть полным, пока записи не удалялись")
+	}
+	if len(entries) != 2 || entries[0].Message != "message 1" || entries[1].Message != "message 2" {
+		t.Fatalf("неожиданные записи: %+v", entries)
+	}
+
+	// Вытеснение записи делает кеш неполным для интервалов, включающих ее время
+	cache.Put("key_3", LogEntry{Message: "message 3", Timestamp: base.Add(3 * time.Second)})
+	evicted := base.Add(time.Second) // key_0 был прочитан, вытесняется key_1
+	if _, ok := cache.EntriesSince(evicted); ok {
+		t.Error("кеш не должен считаться полным после вытеснения записи из интервала")
+	}
+	if entries, ok := cache.EntriesSince(evicted.Add(time.Second)); !ok || len(entries) != 2 {
+		t.Errorf("ожидались 2 записи после вытесненной, получено %d (ok=%v)", len(entries), ok)
+	}
+
+	// После очистки кеш не может ответить ни на один из прошлых интервалов
+	cache.Clear()
+	if _, ok := cache.EntriesSince(base.Add(3 * time.Second)); ok {
+		t.Error("кеш не должен считаться полным после очистки")
+	}
+}
+
 // TestLogCacheTTL проверяет работу TTL (время жизни записей)
 func TestLogCacheTTL(t *testing.T) {
 	ttl := 100 * time.Millisecond
-	cache := NewLogCache(10, ttl)
+	clock := NewFakeClock(time.Now())
+	cache := NewLogCacheWithClock(10, ttl, clock)
 	defer cache.Close() // Закрываем cleanup горутину после теста
 
 	key := "ttl_key"
@@ -259,8 +327,8 @@ func TestLogCacheTTL(t *testing.T) {
 		t.Error("результат не должен быть nil для существующей записи")
 	}
 
-	// Ждем истечения TTL
-	time.Sleep(ttl + 50*time.Millisecond)
+	// Переводим часы за пределы TTL вместо ожидания
+	clock.Advance(ttl + time.Millisecond)
 
 	// После истечения TTL запись должна быть недоступна
 	result, found = cache.Get(key)
@@ -278,6 +346,32 @@ func TestLogCacheTTL(t *testing.T) {
 	}
 }
 
+// TestLogCacheCleanupExpired проверяет фоновое удаление просроченных записей по таймеру часов
+func TestLogCacheCleanupExpired(t *testing.T) {
+	ttl := time.Minute
+	clock := NewFakeClock(time.Now())
+	cache := NewLogCacheWithClock(10, ttl, clock)
+	defer cache.Close()
+
+	cache.Put("old", LogEntry{Service: "TEST", Message: "старая запись"})
+	clock.Advance(ttl / 2)
+	cache.Put("new", LogEntry{Service: "TEST", Message: "новая запись"})
+
+	// Очистка срабатывает каждые ttl/2; к этому моменту устарела только первая запись
+	clock.Advance(ttl/2 + time.Second)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cache.GetStats().Size != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if size := cache.GetStats().Size; size != 1 {
+		t.Fatalf("после очистки должна остаться 1 запись, осталось %d", size)
+	}
+	if _, found := cache.Get("new"); !found {
+		t.Error("актуальная запись не должна удаляться")
+	}
+}
+
 // TestLogCacheClear проверяет очистку кеша
 func TestLogCacheClear(t *testing.T) {
 	cache := NewLogCache(10, 0)
@@ -0,0 +1,183 @@
+// catalog.go - Каталог служебных сообщений сервера на разных языках
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Языки служебных сообщений сервера (LoggingConfig.Locale)
+const (
+	LocaleRussian = "ru" // Русский (по умолчанию)
+	LocaleEnglish = "en" // Английский
+)
+
+// messageKey идентификатор служебного сообщения в каталоге
+type messageKey int
+
+const (
+	// Служебные сообщения сервера в файле лога (сервис SLOG)
+	msgServerStarted       messageKey = iota // Сервер запущен
+	msgServerStopping                        // Сервер останавливается
+	msgLevelChanged                          // Изменен общий уровень логирования
+	msgServiceLevelChanged                   // Изменен уровень сервиса
+	msgServiceLevelReset                     // Уровень сервиса сброшен к общему
+	msgConfigReloaded                        // Конфигурация перезагружена
+	msgRejectedServices                      // Отброшены сообщения от неразрешенных сервисов
+	msgRotation                              // Файл лога ротирован
+	msgClientBanned                          // Клиент заблокирован за превышение лимита скорости
+	msgClientUnbanned                        // Бан клиента истек
+	msgServiceAdded                          // Сервис зарегистрирован во время работы
+
+	// Диагностика сервера в stderr
+	msgWriteRecovered  // Запись в файл лога восстановлена
+	msgWriteFailed     // Ошибка записи в файл лога
+	msgSinkWriteFailed // Ошибка записи в приемник
+	msgSinkCloseFailed // Ошибка закрытия приемника
+
+	// Ошибки протокола, отправляемые клиенту
+	msgAtCapacity          // Достигнут лимит подключений
+	msgReadTimeout         // Таймаут чтения
+	msgUnknownMessageType  // Неизвестный тип сообщения
+	msgRateLimited         // Превышен лимит скорости
+	msgInvalidFilter       // Неверный формат фильтра
+	msgFilterValidation    // Ошибка валидации фильтра
+	msgGetEntriesFailed    // Ошибка получения записей
+	msgInvalidRange        // Неверный формат диапазона
+	msgRangeValidation     // Ошибка валидации диапазона
+	msgInvalidLevelFormat  // Неверный формат уровня
+	msgInvalidLevel        // Недопустимый уровень
+	msgInvalidServiceLevel // Неверный формат запроса уровня сервиса
+	msgInvalidServiceName  // Недопустимое имя сервиса
+	msgLevelUpdated        // Ответ на изменение общего уровня
+	msgServiceLevelUpdated // Ответ на изменение уровня сервиса
+	msgServiceRegistered   // Ответ на регистрацию сервиса
+
+	// Причины неработоспособности записи (HealthStatus.Error)
+	msgHealthNotOpen     // Файл лога не открыт
+	msgHealthWriteError  // Последняя запись завершилась ошибкой
+	msgHealthNoSpace     // Нет свободного места
+	msgHealthNotWritable // Файл недоступен для записи
+
+	// Ошибки HTTP API
+	msgMethodNotAllowed // Метод не поддерживается
+
+	messageKeyCount // Количество сообщений в каталоге
+)
+
+// messageCatalog тексты служебных сообщений одного языка (шаблоны fmt)
+type messageCatalog [messageKeyCount]string
+
+// catalogs каталоги служебных сообщений по языкам
+// Русские тексты ошибок о лимите подключений и неизвестном типе сообщения
+// совпадают с теми, по которым клиенты старых версий распознают эти ошибки.
+var catalogs = map[string]*messageCatalog{
+	LocaleRussian: {
+		msgServerStarted:       "Сервер логгера запущен",
+		msgServerStopping:      "Сервер логгера останавливается",
+		msgLevelChanged:        "Уровень логирования изменен на %s",
+		msgServiceLevelChanged: "Уровень логирования сервиса %s изменен на %s",
+		msgServiceLevelReset:   "Уровень логирования сервиса %s сброшен к общему",
+		msgConfigReloaded:      "Конфигурация перезагружена, уровень логирования %s",
+		msgRejectedServices:    "Отброшены сообщения от сервисов вне списка разрешенных: %s",
+		msgRotation:            "Файл лога ротирован",
+		msgClientBanned:        "Клиент %s заблокирован на %s за превышение лимита скорости (ступень %d)",
+		msgClientUnbanned:      "Бан клиента %s истек",
+		msgServiceAdded:        "Зарегистрирован сервис %s",
+
+		msgWriteRecovered:  "Запись в лог восстановлена после ошибки: %v",
+		msgWriteFailed:     "Ошибка записи в лог: %v",
+		msgSinkWriteFailed: "Ошибка записи в приемник %T: %v",
+		msgSinkCloseFailed: "Ошибка закрытия приемника %T: %v",
+
+		msgAtCapacity:          serverAtCapacityMessage,
+		msgReadTimeout:         "Таймаут чтения",
+		msgUnknownMessageType:  unknownMessageTypeMessage + " %q: сервер поддерживает протокол версии %d, обновите сервер или используйте клиент той же версии",
+		msgRateLimited:         "Превышен лимит скорости сообщений",
+		msgInvalidFilter:       "Неверный формат фильтра",
+		msgFilterValidation:    "Ошибка валидации фильтра: %v",
+		msgGetEntriesFailed:    "Ошибка получения записей: %v",
+		msgInvalidRange:        "Неверный формат диапазона",
+		msgRangeValidation:     "Ошибка валидации диапазона: %v",
+		msgInvalidLevelFormat:  "Неверный формат уровня",
+		msgInvalidLevel:        "Недопустимый уровень: %v",
+		msgInvalidServiceLevel: "Неверный формат запроса уровня сервиса",
+		msgInvalidServiceName:  "Недопустимое имя сервиса: %v",
+		msgLevelUpdated:        "Уровень логирования обновлен",
+		msgServiceLevelUpdated: "Уровень логирования сервиса обновлен",
+		msgServiceRegistered:   "Сервис зарегистрирован",
+
+		msgHealthNotOpen:     "файл лога не открыт",
+		msgHealthWriteError:  "последняя запись в файл лога завершилась ошибкой: %v",
+		msgHealthNoSpace:     "нет свободного места на диске",
+		msgHealthNotWritable: "файл лога недоступен для записи: %v",
+
+		msgMethodNotAllowed: "метод не поддерживается",
+	},
+	LocaleEnglish: {
+		msgServerStarted:       "Logger server started",
+		msgServerStopping:      "Logger server stopping",
+		msgLevelChanged:        "Log level changed to %s",
+		msgServiceLevelChanged: "Log level of service %s changed to %s",
+		msgServiceLevelReset:   "Log level of service %s reset to the global level",
+		msgConfigReloaded:      "Configuration reloaded, log level %s",
+		msgRejectedServices:    "Dropped messages from services outside the allowed list: %s",
+		msgRotation:            "Log file rotated",
+		msgClientBanned:        "Client %s banned for %s for exceeding the rate limit (level %d)",
+		msgClientUnbanned:      "Ban of client %s expired",
+		msgServiceAdded:        "Service %s registered",
+
+		msgWriteRecovered:  "Log write recovered after error: %v",
+		msgWriteFailed:     "Log write error: %v",
+		msgSinkWriteFailed: "Sink %T write error: %v",
+		msgSinkCloseFailed: "Sink %T close error: %v",
+
+		msgAtCapacity:          "server at capacity: connection limit reached",
+		msgReadTimeout:         "Read timeout",
+		msgUnknownMessageType:  "Unknown message type %q: server supports protocol version %d, upgrade the server or use a client of the same version",
+		msgRateLimited:         "Message rate limit exceeded",
+		msgInvalidFilter:       "Invalid filter format",
+		msgFilterValidation:    "Filter validation error: %v",
+		msgGetEntriesFailed:    "Failed to read entries: %v",
+		msgInvalidRange:        "Invalid range format",
+		msgRangeValidation:     "Range validation error: %v",
+		msgInvalidLevelFormat:  "Invalid level format",
+		msgInvalidLevel:        "Invalid level: %v",
+		msgInvalidServiceLevel: "Invalid service level request format",
+		msgInvalidServiceName:  "Invalid service name: %v",
+		msgLevelUpdated:        "Log level updated",
+		msgServiceLevelUpdated: "Service log level updated",
+		msgServiceRegistered:   "Service registered",
+
+		msgHealthNotOpen:     "log file is not open",
+		msgHealthWriteError:  "last log file write failed: %v",
+		msgHealthNoSpace:     "no free disk space",
+		msgHealthNotWritable: "log file is not writable: %v",
+
+		msgMethodNotAllowed: "method not allowed",
+	},
+}
+
+// catalogFor возвращает каталог для языка; пустой язык означает русский
+func catalogFor(locale string) (*messageCatalog, error) {
+	if locale == "" {
+		return catalogs[LocaleRussian], nil
+	}
+	if catalog, ok := catalogs[strings.ToLower(locale)]; ok {
+		return catalog, nil
+	}
+	return nil, fmt.Errorf("неизвестный язык сообщений %q (допустимые значения: %s, %s)", locale, LocaleRussian, LocaleEnglish)
+}
+
+// text возвращает служебное сообщение на языке сервера
+// Аргументы подставляются в шаблон так же, как в fmt.Sprintf.
+func (s *LogServer) text(key messageKey, args ...interface{}) string {
+	catalog := s.messages
+	if catalog == nil {
+		catalog = catalogs[LocaleRussian]
+	}
+	if len(args) == 0 {
+		return catalog[key]
+	}
+	return fmt.Sprintf(catalog[key], args...)
+}
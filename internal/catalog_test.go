@@ -0,0 +1,82 @@
+// catalog_test.go - Тесты каталога служебных сообщений сервера
+package logger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// formatVerbPattern находит подстановки fmt в шаблоне сообщения
+var formatVerbPattern = regexp.MustCompile(`%[a-zA-Z]`)
+
+// TestCatalogsComplete проверяет, что каждый язык содержит все сообщения
+// с теми же подстановками, что и русский каталог
+func TestCatalogsComplete(t *testing.T) {
+	russian := catalogs[LocaleRussian]
+	for locale, catalog := range catalogs {
+		for key := messageKey(0); key < messageKeyCount; key++ {
+			if catalog[key] == "" {
+				t.Errorf("в каталоге %s нет сообщения %d", locale, key)
+				continue
+			}
+			want := strings.Join(formatVerbPattern.FindAllString(russian[key], -1), "")
+			got := strings.Join(formatVerbPattern.FindAllString(catalog[key], -1), "")
+			if got != want {
+				t.Errorf("каталог %s, сообщение %d: подстановки %q, ожидались %q", locale, key, got, want)
+			}
+		}
+	}
+
+	// Клиенты старых версий распознают эти ошибки по тексту
+	if !strings.HasPrefix(russian[msgUnknownMessageType], unknownMessageTypeMessage) {
+		t.Error("русский текст о неизвестном типе сообщения должен начинаться с unknownMessageTypeMessage")
+	}
+	if russian[msgAtCapacity] != serverAtCapacityMessage {
+		t.Error("русский текст о лимите подключений должен совпадать с serverAtCapacityMessage")
+	}
+}
+
+// TestCatalogFor проверяет выбор каталога по языку
+func TestCatalogFor(t *testing.T) {
+	for _, locale := range []string{"", "ru", "EN"} {
+		if _, err := catalogFor(locale); err != nil {
+			t.Errorf("язык %q должен поддерживаться: %v", locale, err)
+		}
+	}
+	if _, err := catalogFor("de"); err == nil {
+		t.Error("неизвестный язык должен отклоняться")
+	}
+
+	config := createTestServerConfig(t)
+	config.Locale = "de"
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("сервер с неизвестным языком не должен создаваться")
+	}
+}
+
+// TestServerLocaleEnglish проверяет служебные сообщения и ошибки сервера на английском
+func TestServerLocaleEnglish(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Locale = LocaleEnglish
+	server, client := startTestServerWithClient(t, config)
+
+	waitForLogContent(t, server, "Logger server started")
+
+	if err := client.SetServerLevel(WARN); err != nil {
+		t.Fatalf("ошибка изменения уровня: %v", err)
+	}
+	waitForLogContent(t, server, "Log level changed to WARN")
+
+	var output strings.Builder
+	server.handleGetEntries(json.RawMessage(`"not a filter"`), json.NewEncoder(&output), false)
+	if !strings.Contains(output.String(), "Invalid filter format") {
+		t.Errorf("ошибка протокола должна быть на английском: %s", output.String())
+	}
+
+	// Сервер без каталога (создан без NewLogServer) использует русский язык
+	if got := (&LogServer{}).text(msgServerStarted); got != "Сервер логгера запущен" {
+		t.Errorf("ожидалось русское сообщение по умолчанию, получено %q", got)
+	}
+}
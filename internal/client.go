@@ -2,31 +2,96 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
 	"sort"
+	"strings"
 
 	"net"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
-// Переменная для подмены в тестах
+// truncatedMarker отметка в конце текста, укороченного до размера кадра
+const truncatedMarker = "...[truncated]"
+
+// messageEnvelopeSize запас на имена полей, уровень и время закодированного LogMessage в байтах
+const messageEnvelopeSize = 128
+
+// Переменные для подмены в тестах
 var netDialTimeout = net.DialTimeout
 
+// exitFunc завершает процесс после Fatal; тесты подменяют ее, чтобы проверить
+// отправку сообщения без запуска отдельного процесса
+var exitFunc = os.Exit
+
+// streamConn отдельное соединение с сервером со своей блокировкой
+// Используется для соединений пула и для соединения запросов.
+type streamConn struct {
+	mu      sync.Mutex    // Мьютекс использования соединения
+	conn    net.Conn      // Соединение с сервером
+	encoder *json.Encoder // Энкодер соединения
+	decoder *json.Decoder // Декодер ответов (нужен только соединению запросов)
+}
+
+// attach привязывает к потоку установленное соединение (вызывается под sc.mu)
+func (sc *streamConn) attach(conn net.Conn) {
+	sc.conn = conn
+	sc.encoder = json.NewEncoder(conn)
+	sc.decoder = json.NewDecoder(conn)
+}
+
+// close закрывает соединение потока (вызывается под sc.mu)
+func (sc *streamConn) close() error {
+	var err error
+	if sc.conn != nil {
+		err = sc.conn.Close()
+	}
+	sc.conn = nil
+	sc.encoder = nil
+	sc.decoder = nil
+	return err
+}
+
 // LogClient клиентская часть логгера для подключения к серверу
 type LogClient struct {
-	config         *LoggingConfig            // Конфигурация клиента
-	conn           net.Conn                  // Соединение с сервером
-	encoder        *json.Encoder             // Энкодер для отправки JSON
-	decoder        *json.Decoder             // Декодер для чтения ответов
-	mu             sync.Mutex                // Мьютекс для синхронизации записи
-	level          LogLevel                  // Локальный уровень логирования
-	reconnectMu    sync.Mutex                // Мьютекс для переподключения
-	serviceLoggers map[string]*ServiceLogger // Кеш логгеров сервисов
-	servicesMu     sync.RWMutex              // Мьютекс для карты сервисов
-	connected      bool                      // Флаг состояния подключения
+	config         *LoggingConfig               // Конфигурация клиента
+	conn           net.Conn                     // Соединение с сервером
+	encoder        *json.Encoder                // Энкодер для отправки JSON
+	decoder        *json.Decoder                // Декодер для чтения ответов
+	mu             sync.Mutex                   // Мьютекс для синхронизации записи
+	level          LogLevel                     // Локальный уровень логирования
+	reconnectMu    sync.Mutex                   // Мьютекс для переподключения
+	serviceLoggers map[string]*ServiceLogger    // Кеш логгеров сервисов
+	serviceLevels  map[string]LogLevel          // Локальные уровни отдельных сервисов
+	servicesMu     sync.RWMutex                 // Мьютекс для карт сервисов
+	connected      bool                         // Флаг состояния подключения
+	capacityUntil  time.Time                    // До этого момента сервер считается перегруженным
+	done           chan struct{}                // Канал остановки фонового переподключения
+	stopOnce       sync.Once                    // Однократная остановка фонового переподключения
+	wg             sync.WaitGroup               // Ожидание фоновых горутин
+	pool           []*streamConn                // Пул соединений для сообщений лога (если ClientPoolSize > 1)
+	poolNext       uint32                       // Счетчик для циклического выбора соединения пула
+	control        streamConn                   // Соединение для запросов (устанавливается при первом запросе)
+	serverCaps     atomic.Pointer[Capabilities] // Возможности сервера из последнего приветствия (nil для старых серверов)
+	closed         atomic.Bool                  // Клиент закрыт, переподключение запрещено
+	closing        chan struct{}                // Закрывается в Close, прерывая ожидание переподключения
+	truncated      atomic.Int64                 // Значения полей, укороченные или отброшенные из-за размера кадра
+	hooks          []MessageHook                // Обработчики сообщений перед отправкой (см. AddHook)
+	hooksMu        sync.RWMutex                 // Мьютекс для списка обработчиков
+	batch          []batchEntry                 // Сообщения, ожидающие отправки пакетом (см. BatchSize)
+	batchBytes     int                          // Размер закодированных сообщений пакета в байтах
+	batchTimer     *time.Timer                  // Таймер отправки неполного пакета
+	batchMu        sync.Mutex                   // Мьютекс пакета; удерживается на время отправки, сохраняя порядок сообщений
+	serviceNames   *SecurityConfig              // Правила имен сервисов (LoggingConfig.Security и AllowedServiceChars)
 }
 
 // NewLogClient создает новый клиент логгера
@@ -34,6 +99,23 @@ func NewLogClient(config *LoggingConfig) (*LogClient, error) {
 	if config == nil {
 		return nil, fmt.Errorf("конфигурация не может быть nil")
 	}
+	if config.ReconnectMaxAttempts < 0 {
+		return nil, fmt.Errorf("количество попыток переподключения не может быть отрицательным: %d", config.ReconnectMaxAttempts)
+	}
+	if config.ReconnectInitialBackoff < 0 || config.ReconnectMaxBackoff < 0 {
+		return nil, fmt.Errorf("задержка переподключения не может быть отрицательной")
+	}
+	if config.MaxMessageSize < 0 {
+		return nil, fmt.Errorf("максимальный размер сообщения должен быть положительным: %d", config.MaxMessageSize)
+	}
+	if config.BatchSize < 0 || config.BatchInterval < 0 {
+		return nil, fmt.Errorf("параметры объединения сообщений не могут быть отрицательными")
+	}
+
+	serviceNames, err := securityConfigFor(config)
+	if err != nil {
+		return nil, err
+	}
 
 	level, err := ParseLevel(config.Level)
 	if err != nil {
@@ -45,15 +127,109 @@ func NewLogClient(config *LoggingConfig) (*LogClient, error) {
 		level:          level,
 		serviceLoggers: make(map[string]*ServiceLogger),
 		connected:      false,
+		closing:        make(chan struct{}),
+		serviceNames:   serviceNames,
 	}
 
-	if err := client.connect(); err != nil {
+	// Сообщения лога идут через основное соединение или пул соединений,
+	// запросы - через отдельное соединение (см. sendRequest)
+	if config.ClientPoolSize > 1 {
+		client.pool = make([]*streamConn, config.ClientPoolSize)
+		for i := range client.pool {
+			client.pool[i] = &streamConn{}
+			if err := client.dialStream(client.pool[i]); err != nil {
+				_ = client.Close()
+				return nil, fmt.Errorf("ошибка подключения к серверу логгера: %w", err)
+			}
+		}
+	} else if err := client.connect(); err != nil {
 		return nil, fmt.Errorf("ошибка подключения к серверу логгера: %w", err)
 	}
 
+	if config.ReconnectInterval > 0 {
+		client.done = make(chan struct{})
+		client.wg.Add(1)
+		go client.backgroundReconnect(config.ReconnectInterval)
+	}
+
 	return client, nil
 }
 
+// now возвращает текущее время по часам из конфигурации
+func (c *LogClient) now() time.Time {
+	if c.config == nil {
+		return time.Now()
+	}
+	return clockOrSystem(c.config.Clock).Now()
+}
+
+// backgroundReconnect периодически восстанавливает потерянное соединение,
+// чтобы первые сообщения после перезапуска сервера не уходили в stderr
+func (c *LogClient) backgroundReconnect(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := clockOrSystem(c.config.Clock).NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.tryReconnect()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// tryReconnect выполняет одну попытку подключения, если соединение потеряно
+// Порядок блокировок совпадает с sendMessage (c.mu, затем reconnectMu),
+// поэтому фоновая попытка не пересекается с переподключением при отправке.
+func (c *LogClient) tryReconnect() {
+	// Соединения пула переподключаются независимо друг от друга
+	for _, sc := range c.pool {
+		sc.mu.Lock()
+		if sc.encoder == nil && !c.closed.Load() {
+			_ = c.dialStream(sc)
+		}
+		sc.mu.Unlock()
+	}
+	if len(c.pool) > 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() || (c.connected && c.conn != nil) {
+		return
+	}
+
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	if c.now().Before(c.capacityUntil) {
+		return
+	}
+
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+
+	if err := c.connect(); errors.Is(err, ErrServerAtCapacity) {
+		c.capacityUntil = c.now().Add(time.Duration(DEFAULT_CAPACITY_BACKOFF) * time.Second)
+	}
+}
+
+// stopBackground останавливает фоновое переподключение и ждет его завершения
+func (c *LogClient) stopBackground() {
+	if c.done == nil {
+		return
+	}
+	c.stopOnce.Do(func() { close(c.done) })
+	c.wg.Wait()
+}
+
 // connect устанавливает соединение с сервером логгера
 func (c *LogClient) connect() error {
 	// Проверяем, что конфигурация инициализирована
@@ -66,9 +242,9 @@ func (c *LogClient) connect() error {
 		return fmt.Errorf("не указан путь к сокету")
 	}
 
-	conn, err := netDialTimeout("unix", c.config.SocketPath, time.Duration(DEFAULT_CONNECTION_TIMEOUT)*time.Second)
+	conn, err := c.dial()
 	if err != nil {
-		return fmt.Errorf("ошибка подключения к сокету %s: %w", c.config.SocketPath, err)
+		return err
 	}
 
 	c.conn = conn
@@ -79,7 +255,161 @@ func (c *LogClient) connect() error {
 	return nil
 }
 
-// reconnect переподключается к серверу с экспоненциальным backoff
+// dial открывает новое соединение с сервером и проверяет, что сервер его принял
+func (c *LogClient) dial() (net.Conn, error) {
+	if c.config == nil {
+		return nil, fmt.Errorf("конфигурация не инициализирована")
+	}
+
+	conn, err := netDialTimeout("unix", c.config.SocketPath, time.Duration(DEFAULT_CONNECTION_TIMEOUT)*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к сокету %s: %w: %w", c.config.SocketPath, classifyDialError(err), err)
+	}
+
+	capabilities, err := handshake(conn, c.hello())
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if capabilities != nil {
+		c.serverCaps.Store(capabilities)
+	}
+
+	return conn, nil
+}
+
+// dialStream (пере)подключает отдельное соединение (вызывается под sc.mu или до начала работы)
+func (c *LogClient) dialStream(sc *streamConn) error {
+	_ = sc.close()
+
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+
+	sc.attach(conn)
+	return nil
+}
+
+// sendPooled отправляет сообщение через очередное соединение пула
+// Соединения выбираются по кругу, поэтому запись в сокет из разных горутин
+// идет параллельно. Разорванное соединение переподключается одной попыткой.
+func (c *LogClient) sendPooled(protocolMsg ProtocolMessage) error {
+	sc := c.pool[atomic.AddUint32(&c.poolNext, 1)%uint32(len(c.pool))]
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	if sc.encoder == nil {
+		if err := c.dialStream(sc); err != nil {
+			return err
+		}
+	}
+
+	if err := sc.encoder.Encode(protocolMsg); err != nil {
+		if dialErr := c.dialStream(sc); dialErr != nil {
+			return err
+		}
+		return sc.encoder.Encode(protocolMsg)
+	}
+
+	return nil
+}
+
+// hello формирует приветствие клиента с учетом конфигурации
+func (c *LogClient) hello() Hello {
+	hello := Hello{ProtocolVersion: PROTOCOL_VERSION}
+	if c.config.CompressResponses {
+		hello.Compression = []string{EncodingGzip}
+	}
+	return hello
+}
+
+// handshake обменивается версиями протокола с только что подключенным сервером
+// Клиент отправляет MsgTypeHello, сервер отвечает своей версией и списком
+// поддерживаемых типов сообщений. Перегруженный сервер вместо ответа присылает
+// отказ и закрывает соединение. Старый сервер отвечает ошибкой неизвестного
+// типа или не отвечает вовсе: тогда возвращается nil без ошибки и клиент
+// работает без сведений о сервере. Проверяются только unix соединения,
+// ожидание ответа ограничено DEFAULT_HANDSHAKE_TIMEOUT_MS.
+func handshake(conn net.Conn, hello Hello) (*Capabilities, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, nil
+	}
+
+	deadline := time.Now().Add(time.Duration(DEFAULT_HANDSHAKE_TIMEOUT_MS) * time.Millisecond)
+	_ = unixConn.SetDeadline(deadline)
+	defer func() { _ = unixConn.SetDeadline(time.Time{}) }()
+
+	// Ошибку записи не проверяем: отказ сервера важнее и будет прочитан ниже
+	_ = json.NewEncoder(unixConn).Encode(ProtocolMessage{
+		Type: MsgTypeHello,
+		Data: hello,
+	})
+
+	// Сервер не отправляет данные без запроса, поэтому единственное сообщение
+	// в соединении - ответ на приветствие или отказ в подключении
+	var response ProtocolMessage
+	if err := json.NewDecoder(unixConn).Decode(&response); err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return nil, nil
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("неожиданные данные от сервера при подключении: %w", ErrServerUnavailable)
+		}
+		return nil, fmt.Errorf("сервер закрыл соединение при подключении: %w: %w", ErrServerUnavailable, err)
+	}
+
+	switch {
+	case isCapacityError(&response):
+		return nil, ErrServerAtCapacity
+	case response.Type == MsgTypeError:
+		// Сервер без приветствия; серверы старых версий не передают код ошибки
+		if response.Code == ErrorCodeUnsupported {
+			return nil, nil
+		}
+		if text, ok := response.Data.(string); ok && strings.HasPrefix(text, unknownMessageTypeMessage) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("сервер отклонил подключение: %v: %w", response.Data, ErrServerUnavailable)
+	case response.Type != MsgTypeResponse:
+		return nil, fmt.Errorf("неожиданные данные от сервера при подключении: %w", ErrServerUnavailable)
+	}
+
+	data, err := json.Marshal(response.Data)
+	if err != nil {
+		return nil, fmt.Errorf("неожиданные данные от сервера при подключении: %w", ErrServerUnavailable)
+	}
+	var capabilities Capabilities
+	if err := json.Unmarshal(data, &capabilities); err != nil {
+		return nil, fmt.Errorf("неожиданные данные от сервера при подключении: %w", ErrServerUnavailable)
+	}
+
+	return &capabilities, nil
+}
+
+// isCapacityError проверяет, является ли ответ отказом из-за лимита подключений
+func isCapacityError(response *ProtocolMessage) bool {
+	if response.Type != MsgTypeError {
+		return false
+	}
+	if response.Code == ErrorCodeAtCapacity {
+		return true
+	}
+	text, ok := response.Data.(string)
+	return ok && text == serverAtCapacityMessage
+}
+
+// reconnect переподключается к серверу с экспоненциальным backoff (вызывается под c.mu)
+// На время ожидания между попытками c.mu освобождается, поэтому Close и
+// другие вызовы не блокируются; закрытие клиента прерывает переподключение
+// с ошибкой ErrClosed.
 func (c *LogClient) reconnect() error {
 	// Проверяем, что конфигурация инициализирована
 	if c.config == nil {
@@ -87,33 +417,114 @@ func (c *LogClient) reconnect() error {
 	}
 
 	c.reconnectMu.Lock()
-	defer c.reconnectMu.Unlock()
-
 	if c.conn != nil {
 		_ = c.conn.Close()
 		c.conn = nil
 		c.connected = false
 	}
 
+	// Перегруженный сервер не опрашиваем повторно до истечения паузы
+	atCapacity := c.now().Before(c.capacityUntil)
+	c.reconnectMu.Unlock()
+	if atCapacity {
+		return ErrServerAtCapacity
+	}
+
 	// Экспоненциальный backoff для переподключения
-	backoff := time.Millisecond * 100
-	maxBackoff := time.Second * 10
-	maxAttempts := 5
+	maxAttempts, backoff, maxBackoff := c.reconnectPolicy()
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		if err := c.connect(); err == nil {
+	var lastErr error
+	for attempt := 0; maxAttempts == 0 || attempt < maxAttempts; attempt++ {
+		if c.closed.Load() {
+			return ErrClosed
+		}
+
+		c.reconnectMu.Lock()
+		err := c.connect()
+		c.reconnectMu.Unlock()
+		if err == nil {
 			return nil
 		}
+		lastErr = err
+
+		// При перегрузке сервера частые попытки только усиливают нагрузку,
+		// поэтому откладываем переподключение на более длительный срок
+		if errors.Is(err, ErrServerAtCapacity) {
+			c.capacityUntil = c.now().Add(time.Duration(DEFAULT_CAPACITY_BACKOFF) * time.Second)
+			return err
+		}
+
+		// После последней попытки ждать нечего
+		if attempt == maxAttempts-1 {
+			break
+		}
 
 		// Увеличиваем задержку экспоненциально
-		time.Sleep(backoff)
+		if !c.waitReconnect(jitter(backoff)) {
+			return ErrClosed
+		}
+		// Пока c.mu был свободен, соединение могло восстановить фоновое переподключение
+		if c.connected && c.conn != nil {
+			return nil
+		}
 		backoff *= 2
 		if backoff > maxBackoff {
 			backoff = maxBackoff
 		}
 	}
 
-	return fmt.Errorf("не удалось переподключиться после %d попыток", maxAttempts)
+	// Сохраняем последнюю ошибку, чтобы errors.Is мог определить ее причину
+	return fmt.Errorf("не удалось переподключиться после %d попыток: %w", maxAttempts, lastErr)
+}
+
+// waitReconnect ждет перед следующей попыткой переподключения, освобождая c.mu
+// Возвращает false, если клиент закрыт до или во время ожидания.
+func (c *LogClient) waitReconnect(delay time.Duration) bool {
+	c.mu.Unlock()
+	defer c.mu.Lock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-c.closing:
+	}
+	return !c.closed.Load()
+}
+
+// reconnectPolicy возвращает параметры переподключения из конфигурации
+// Нулевое количество попыток означает переподключение без ограничения
+// (NewConfig задает DEFAULT_RECONNECT_ATTEMPTS), нулевые задержки
+// заменяются значениями по умолчанию.
+func (c *LogClient) reconnectPolicy() (maxAttempts int, backoff, maxBackoff time.Duration) {
+	maxAttempts = max(c.config.ReconnectMaxAttempts, 0)
+
+	backoff = time.Duration(DEFAULT_RECONNECT_BACKOFF_MS) * time.Millisecond
+	if c.config.ReconnectInitialBackoff > 0 {
+		backoff = c.config.ReconnectInitialBackoff
+	}
+
+	maxBackoff = time.Duration(DEFAULT_RECONNECT_MAX_BACKOFF) * time.Second
+	if c.config.ReconnectMaxBackoff > 0 {
+		maxBackoff = c.config.ReconnectMaxBackoff
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	return maxAttempts, backoff, maxBackoff
+}
+
+// jitter возвращает случайную задержку в диапазоне [d/2, d)
+// Разброс не дает множеству клиентов переподключаться одновременно
+// после перезапуска сервера.
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half)
 }
 
 // sendMessage отправляет сообщение логгера на сервер
@@ -126,24 +537,50 @@ func (c *LogClient) sendMessage(service string, level LogLevel, message string,
 	// Проверяем, что конфигурация инициализирована
 	if c.config == nil {
 		// Формируем временную метку для записи в stderr
-		timestamp := time.Now()
+		timestamp := c.now()
 		c.fallbackToStderr(service, level, message, timestamp, nil)
 		return fmt.Errorf("конфигурация не инициализирована")
 	}
 
-	// Проверяем локальный уровень логирования
-	if level < c.level {
+	// Проверяем локальный уровень логирования; при OFF не отправляется ничего
+	if !c.accepts(service, level) {
+		return nil
+	}
+
+	// Обработчики могут изменить или отбросить сообщение
+	msg := c.newMessage(service, level, message, fields)
+	if !c.runHooks(&msg) {
 		return nil
 	}
+	return c.deliver(msg)
+}
 
-	// Создаем сообщение лога
-	msg := LogMessage{
+// accepts сообщает, проходит ли сообщение локальный уровень сервиса (OFF - не проходит ничего)
+func (c *LogClient) accepts(service string, level LogLevel) bool {
+	threshold := c.levelFor(service)
+	return threshold != OFF && level >= threshold && level.IsMessageLevel()
+}
+
+// newMessage создает сообщение лога с текущим временем клиента
+func (c *LogClient) newMessage(service string, level LogLevel, message string, fields map[string]string) LogMessage {
+	return LogMessage{
 		Service:   service,
 		Level:     level,
 		Message:   message,
-		Timestamp: time.Now(),
+		Timestamp: c.now(),
 		Fields:    fields, // Добавляем дополнительные поля
 	}
+}
+
+// deliver отправляет серверу сообщение, уже обработанное обработчиками
+func (c *LogClient) deliver(msg LogMessage) error {
+	// Сервер закрывает соединение при слишком большом кадре - укорачиваем текст заранее
+	c.fitMessage(&msg)
+
+	// При объединении в пакеты сообщение отправляется вместе с соседними
+	if c.batching() {
+		return c.enqueueBatch(msg)
+	}
 
 	// Создаем протокольное сообщение
 	protocolMsg := ProtocolMessage{
@@ -151,14 +588,168 @@ func (c *LogClient) sendMessage(service string, level LogLevel, message string,
 		Data: msg,
 	}
 
+	if err := c.sendFrame(protocolMsg); err != nil {
+		// Fallback в stderr при невозможности отправки
+		c.fallbackToStderr(msg.Service, msg.Level, msg.Message, msg.Timestamp, msg.Fields)
+		return err
+	}
+	return nil
+}
+
+// terminal записывает сообщение FATAL или PANIC перед завершением программы
+// Обработчики применяются до вывода в stderr, поэтому и stderr, и сервер
+// получают одинаковый текст; он же возвращается для panic.
+func (c *LogClient) terminal(service string, level LogLevel, message string, fields map[string]string) string {
+	msg := c.newMessage(service, level, message, fields)
+	if !c.runHooks(&msg) {
+		return msg.Message
+	}
+
+	// Немедленно выводим сообщение в stderr, чтобы тесты могли зафиксировать его в выводе
+	c.fallbackToStderr(msg.Service, msg.Level, msg.Message, msg.Timestamp, msg.Fields)
+	// Пытаемся отправить сообщение серверу (ошибку игнорируем, т.к. процесс завершится)
+	if c.config != nil && c.accepts(service, level) {
+		_ = c.deliver(msg)
+	}
+	return msg.Message
+}
+
+// frameLimit максимальный размер кадра в байтах
+// Сервер закрывает соединение, если кадр больше MaxMessageSize, поэтому клиент
+// не отправляет кадры больше своего MaxMessageSize и лимита, объявленного
+// сервером при приветствии (если он меньше).
+func (c *LogClient) frameLimit() int {
+	limit := DEFAULT_MAX_MESSAGE_SIZE
+	if c.config.MaxMessageSize > 0 {
+		limit = c.config.MaxMessageSize
+	}
+	if capabilities := c.serverCaps.Load(); capabilities != nil && capabilities.MaxMessageSize > 0 {
+		limit = min(limit, capabilities.MaxMessageSize)
+	}
+	return limit
+}
+
+// fitMessage укорачивает текст сообщения и значения полей, если закодированное
+// сообщение не помещается в кадр, и добавляет к ним отметку truncatedMarker
+// Все значения длиннее общего порога укорачиваются до него, поэтому длинное
+// поле не вытесняет текст сообщения и наоборот. Если сообщение не помещается
+// даже после этого (например, из-за длинных ключей), поля отбрасываются,
+// начиная с самого длинного. Укороченные и отброшенные поля учитываются
+// в TruncatedFields.
+func (c *LogClient) fitMessage(msg *LogMessage) {
+	limit := c.frameLimit() - frameOverhead
+
+	// Байт текста кодируется в JSON не более чем шестью байтами (\u00XX),
+	// поэтому заведомо короткие сообщения не кодируются для проверки
+	size := len(msg.Service) + len(msg.Message)
+	for key, value := range msg.Fields {
+		size += len(key) + len(value) + 1
+	}
+	if size*6+messageEnvelopeSize <= limit {
+		return
+	}
+
+	raw, err := json.Marshal(msg)
+	if err != nil || len(raw) <= limit {
+		return
+	}
+
+	// Поля не изменяются на месте: карта может принадлежать вызывающему коду
+	fields := maps.Clone(msg.Fields)
+	msg.Fields = fields
+
+	// Удаление байта текста уменьшает закодированное сообщение минимум на байт
+	keep := fitThreshold(msg, len(raw)-limit)
+	if len(msg.Message) > keep+len(truncatedMarker) {
+		msg.Message = truncateText(msg.Message, keep)
+	}
+	for key, value := range fields {
+		if len(value) > keep+len(truncatedMarker) {
+			fields[key] = truncateText(value, keep)
+			c.truncated.Add(1)
+		}
+	}
+
+	for len(fields) > 0 {
+		if raw, err = json.Marshal(msg); err != nil || len(raw) <= limit {
+			return
+		}
+		delete(fields, longestField(fields))
+		c.truncated.Add(1)
+	}
+}
+
+// fitThreshold подбирает наибольшую длину keep, при которой укорачивание текста
+// и значений полей длиннее keep байт освобождает не менее excess байт
+func fitThreshold(msg *LogMessage, excess int) int {
+	lengths := make([]int, 0, len(msg.Fields)+1)
+	lengths = append(lengths, len(msg.Message))
+	longest := len(msg.Message)
+	for _, value := range msg.Fields {
+		lengths = append(lengths, len(value))
+		longest = max(longest, len(value))
+	}
+
+	// Укорачиваются только значения, которые с отметкой станут короче
+	freed := func(keep int) int {
+		total := 0
+		for _, n := range lengths {
+			if n > keep+len(truncatedMarker) {
+				total += n - keep - len(truncatedMarker)
+			}
+		}
+		return total
+	}
+
+	// freed не возрастает с ростом keep - ищем первое недостаточное значение
+	keep := sort.Search(longest+1, func(keep int) bool { return freed(keep) < excess })
+	return max(keep-1, 0)
+}
+
+// truncateText укорачивает текст до keep байт по границе символа и добавляет truncatedMarker
+func truncateText(text string, keep int) string {
+	for keep > 0 && keep < len(text) && !utf8.RuneStart(text[keep]) {
+		keep--
+	}
+	return text[:min(keep, len(text))] + truncatedMarker
+}
+
+// longestField возвращает ключ поля с наибольшим суммарным размером ключа и значения
+func longestField(fields map[string]string) string {
+	longest, size := "", -1
+	for key, value := range fields {
+		if n := len(key) + len(value); n > size || (n == size && key < longest) {
+			longest, size = key, n
+		}
+	}
+	return longest
+}
+
+// TruncatedFields возвращает количество значений полей, укороченных или
+// отброшенных клиентом, чтобы сообщение поместилось в кадр MaxMessageSize
+func (c *LogClient) TruncatedFields() int64 {
+	return c.truncated.Load()
+}
+
+// sendFrame отправляет кадр с сообщениями лога через пул или основное соединение
+// При разрыве соединения выполняется переподключение и одна повторная попытка.
+// Вывод в stderr при ошибке остается за вызывающим кодом.
+func (c *LogClient) sendFrame(protocolMsg ProtocolMessage) error {
+	if len(c.pool) > 0 {
+		return c.sendPooled(protocolMsg)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// Закрытый клиент не переподключается
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
 	// Проверяем соединение и переподключаемся при необходимости
 	if !c.connected || c.conn == nil || c.encoder == nil {
 		if err := c.reconnect(); err != nil {
-			// Fallback в stderr при невозможности подключения
-			c.fallbackToStderr(service, level, message, msg.Timestamp, fields)
 			return err
 		}
 	}
@@ -172,9 +763,6 @@ func (c *LogClient) sendMessage(service string, level LogLevel, message string,
 				return nil
 			}
 		}
-
-		// Fallback в stderr при ошибке отправки
-		c.fallbackToStderr(service, level, message, msg.Timestamp, fields)
 		return err
 	}
 
@@ -183,6 +771,11 @@ func (c *LogClient) sendMessage(service string, level LogLevel, message string,
 
 // fallbackToStderr записывает сообщение в stderr как резервный вариант
 func (c *LogClient) fallbackToStderr(service string, level LogLevel, message string, timestamp time.Time, fields map[string]string) {
+	writeToStderr(service, level, message, timestamp, fields)
+}
+
+// writeToStderr выводит сообщение в stderr в формате файла лога
+func writeToStderr(service string, level LogLevel, message string, timestamp time.Time, fields map[string]string) {
 	// Форматируем сообщение в том же стиле, что и в файле лога
 	serviceFormatted := fmt.Sprintf("%-5s", service)
 	levelFormatted := fmt.Sprintf("%-5s", level.String())
@@ -209,35 +802,147 @@ func (c *LogClient) fallbackToStderr(service string, level LogLevel, message str
 }
 
 // sendRequest отправляет запрос серверу и ждет ответ
+// Запросы идут через отдельное соединение c.control, а не через соединение
+// сообщений лога: так ответ всегда относится к последнему запросу, а долгий
+// запрос (например, чтение большого лога) не блокирует отправку сообщений.
 func (c *LogClient) sendRequest(msgType string, data interface{}) (*ProtocolMessage, error) {
+	return c.sendRequestContext(context.Background(), msgType, data)
+}
+
+// sendRequestContext отправляет запрос и ждет ответ не дольше, чем позволяет ctx
+// По истечении ctx соединение запросов закрывается, так как опоздавший ответ
+// сервера иначе был бы прочитан как ответ на следующий запрос.
+func (c *LogClient) sendRequestContext(ctx context.Context, msgType string, data interface{}) (*ProtocolMessage, error) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Соединение, открытое до запроса, могло устареть после перезапуска сервера
+	prevConn := c.control.conn
+
+	if err := c.writeRequest(msgType, data); err != nil {
+		return nil, err
+	}
+	// Если соединение открыто этим же запросом, повтор не поможет
+	reused := prevConn != nil && c.control.conn == prevConn
+
+	response, err := c.readResponseContext(ctx)
+	if isContextError(err) {
+		return nil, fmt.Errorf("запрос %s прерван: %w", msgType, err)
+	}
+	if err == nil || !reused || errors.Is(err, ErrServerAtCapacity) {
+		return response, err
+	}
+
+	// Запись в разорванное соединение может пройти без ошибки, и обрыв виден
+	// только при чтении ответа. Запросы управления идемпотентны, поэтому после
+	// переподключения запрос повторяется один раз, как и отправка сообщений.
+	if dialErr := c.dialStream(&c.control); dialErr != nil {
+		return nil, err
+	}
+	if err := c.writeRequest(msgType, data); err != nil {
+		return nil, err
+	}
+	response, err = c.readResponseContext(ctx)
+	if isContextError(err) {
+		return nil, fmt.Errorf("запрос %s прерван: %w", msgType, err)
+	}
+	return response, err
+}
+
+// isContextError проверяет, что запрос прерван завершением контекста
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// readResponseContext читает ответ, прерывая ожидание по завершении ctx (вызывается под c.control.mu)
+// Ошибка чтения после завершения ctx заменяется ошибкой контекста: дедлайн
+// соединения может сработать раньше, чем ctx.Err() отметит истечение срока.
+func (c *LogClient) readResponseContext(ctx context.Context) (*ProtocolMessage, error) {
+	if ctx.Done() == nil || c.control.conn == nil {
+		return c.readResponse()
+	}
+
+	conn := c.control.conn
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetReadDeadline(time.Now())
+	})
+	defer func() {
+		stop()
+		_ = conn.SetReadDeadline(time.Time{})
+	}()
+
+	response, err := c.readResponse()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+			return nil, context.DeadlineExceeded
+		}
+	}
+	return response, err
+}
+
+// writeRequest отправляет запрос через соединение запросов (вызывается под c.control.mu)
+func (c *LogClient) writeRequest(msgType string, data interface{}) error {
 	protocolMsg := ProtocolMessage{
 		Type: msgType,
 		Data: data,
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.closed.Load() {
+		return ErrClosed
+	}
 
-	// Проверяем соединение
-	if !c.connected || c.encoder == nil || c.decoder == nil {
-		if err := c.reconnect(); err != nil {
-			return nil, err
+	// Соединение запросов устанавливается при первом запросе
+	if c.control.encoder == nil {
+		if err := c.dialStream(&c.control); err != nil {
+			return err
 		}
 	}
 
-	// Отправляем запрос
-	if err := c.encoder.Encode(protocolMsg); err != nil {
-		c.connected = false
-		return nil, err
+	// Сервер сообщил при подключении, какие запросы он понимает
+	if capabilities := c.serverCaps.Load(); capabilities != nil && !capabilities.Supports(msgType) {
+		return fmt.Errorf("%w: %q (версия протокола сервера %d, клиента %d)",
+			ErrUnsupportedMessage, msgType, capabilities.ProtocolVersion, PROTOCOL_VERSION)
+	}
+
+	// Отправляем запрос; соединение могло устареть после перезапуска
+	// сервера, поэтому при ошибке записи делаем одну попытку переподключения
+	if err := c.control.encoder.Encode(protocolMsg); err != nil {
+		if dialErr := c.dialStream(&c.control); dialErr != nil {
+			return err
+		}
+		if err := c.control.encoder.Encode(protocolMsg); err != nil {
+			_ = c.control.close()
+			return err
+		}
 	}
 
-	// Читаем ответ
+	return nil
+}
+
+// readResponse читает очередной ответ из соединения запросов (вызывается под c.control.mu)
+func (c *LogClient) readResponse() (*ProtocolMessage, error) {
 	var response ProtocolMessage
-	if err := c.decoder.Decode(&response); err != nil {
-		c.connected = false
+	if err := c.control.decoder.Decode(&response); err != nil {
+		_ = c.control.close()
 		return nil, err
 	}
 
+	// Сервер отклонил подключение уже после его установки
+	if isCapacityError(&response) {
+		_ = c.control.close()
+		return nil, ErrServerAtCapacity
+	}
+
 	return &response, nil
 }
 
@@ -246,8 +951,8 @@ func (c *LogClient) SetLevel(level LogLevel) {
 	c.level = level
 }
 
-// SetServerLevel устанавливает уровень логирования на сервере
-// Использует тип сообщения MsgTypeSetLevel для совместимости с тестами
+// SetServerLevel устанавливает общий уровень логирования на сервере
+// Отправляет MsgTypeSetLevel; устаревший MsgTypeUpdateLevel клиент не использует.
 func (c *LogClient) SetServerLevel(level LogLevel) error {
 	response, err := c.sendRequest(MsgTypeSetLevel, level.String())
 	if err != nil {
@@ -255,12 +960,172 @@ func (c *LogClient) SetServerLevel(level LogLevel) error {
 	}
 
 	if response.Type == MsgTypeError {
-		return fmt.Errorf("ошибка сервера: %v", response.Data)
+		return newServerError(response)
+	}
+
+	return nil
+}
+
+// ServerCapabilities возвращает версию протокола сервера и поддерживаемые типы сообщений
+// Позволяет проверить поддержку запроса до его отправки старому серверу.
+// Используются сведения из приветствия при подключении, а если их нет - выполняется запрос.
+func (c *LogClient) ServerCapabilities() (Capabilities, error) {
+	if cached := c.serverCaps.Load(); cached != nil {
+		return *cached, nil
+	}
+
+	var capabilities Capabilities
+
+	response, err := c.sendRequest(MsgTypeCapabilities, nil)
+	if err != nil {
+		return capabilities, err
+	}
+
+	if response.Type == MsgTypeError {
+		return capabilities, newServerError(response)
+	}
+
+	capabilitiesData, err := json.Marshal(response.Data)
+	if err != nil {
+		return capabilities, err
+	}
+
+	if err := json.Unmarshal(capabilitiesData, &capabilities); err != nil {
+		return capabilities, err
+	}
+
+	return capabilities, nil
+}
+
+// GetServerLevel возвращает общий уровень логирования, действующий на сервере
+func (c *LogClient) GetServerLevel() (LogLevel, error) {
+	level, _, err := c.getServerLevels()
+	return level, err
+}
+
+// GetServiceLevels возвращает уровни сервисов, переопределяющие общий уровень сервера
+func (c *LogClient) GetServiceLevels() (map[string]LogLevel, error) {
+	_, services, err := c.getServerLevels()
+	return services, err
+}
+
+// getServerLevels запрашивает у сервера действующие уровни логирования
+func (c *LogClient) getServerLevels() (LogLevel, map[string]LogLevel, error) {
+	response, err := c.sendRequest(MsgTypeGetLevel, nil)
+	if err != nil {
+		return INFO, nil, err
+	}
+
+	if response.Type == MsgTypeError {
+		return INFO, nil, newServerError(response)
+	}
+
+	infoData, err := json.Marshal(response.Data)
+	if err != nil {
+		return INFO, nil, err
+	}
+
+	var info LevelInfo
+	if err := json.Unmarshal(infoData, &info); err != nil {
+		return INFO, nil, err
+	}
+
+	level, err := ParseLevel(info.Level)
+	if err != nil {
+		return INFO, nil, err
+	}
+
+	services := make(map[string]LogLevel, len(info.Services))
+	for service, name := range info.Services {
+		serviceLevel, err := ParseLevel(name)
+		if err != nil {
+			return INFO, nil, err
+		}
+		services[service] = serviceLevel
+	}
+
+	return level, services, nil
+}
+
+// SetServiceLevel устанавливает уровень логирования для отдельного сервиса
+// Уровень сервиса переопределяет общий уровень и на клиенте, и на сервере:
+// например, DB может писать DEBUG, пока остальные сервисы ограничены INFO.
+func (c *LogClient) SetServiceLevel(service string, level LogLevel) error {
+	if !level.IsValid() {
+		return fmt.Errorf("недопустимый уровень логирования: %d", level)
+	}
+	return c.sendServiceLevel(service, level.String(), &level)
+}
+
+// ResetServiceLevel снимает уровень сервиса, возвращая его к общему уровню
+func (c *LogClient) ResetServiceLevel(service string) error {
+	return c.sendServiceLevel(service, "", nil)
+}
+
+// RegisterService регистрирует сервис на сервере во время работы
+// Сервис добавляется в список разрешенных (RestrictServices) и учитывается в ширине
+// колонки сервиса, как если бы он был передан в New. Имя проверяется по тем же
+// правилам, что и в SetService. Сервер старой версии отвечает ошибкой ErrUnsupportedMessage.
+func (c *LogClient) RegisterService(service string) error {
+	service, err := checkServiceName(service, c.serviceNames)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.sendRequest(MsgTypeRegisterService, service)
+	if err != nil {
+		return err
+	}
+
+	if response.Type == MsgTypeError {
+		return newServerError(response)
 	}
 
 	return nil
 }
 
+// sendServiceLevel отправляет уровень сервиса на сервер и применяет его локально
+// level == nil снимает переопределение уровня.
+func (c *LogClient) sendServiceLevel(service, levelName string, level *LogLevel) error {
+	service = NormalizeServiceName(service)
+
+	response, err := c.sendRequest(MsgTypeSetServiceLevel, ServiceLevelRequest{
+		Service: service,
+		Level:   levelName,
+	})
+	if err != nil {
+		return err
+	}
+
+	if response.Type == MsgTypeError {
+		return newServerError(response)
+	}
+
+	c.servicesMu.Lock()
+	defer c.servicesMu.Unlock()
+	if level == nil {
+		delete(c.serviceLevels, service)
+		return nil
+	}
+	if c.serviceLevels == nil {
+		c.serviceLevels = make(map[string]LogLevel)
+	}
+	c.serviceLevels[service] = *level
+	return nil
+}
+
+// levelFor возвращает локальный уровень для сервиса: его собственный или общий
+func (c *LogClient) levelFor(service string) LogLevel {
+	c.servicesMu.RLock()
+	level, ok := c.serviceLevels[service]
+	c.servicesMu.RUnlock()
+
+	if ok {
+		return level
+	}
+	return c.level
+}
+
 // GetLogFile возвращает путь к файлу лога
 // Отправляет запрос к серверу для получения пути к файлу лога
 func (c *LogClient) GetLogFile() string {
@@ -332,28 +1197,146 @@ func (c *LogClient) UpdateConfig(config *LoggingConfig) error {
 
 // GetLogEntries получает записи из лога с фильтрацией через сервер
 func (c *LogClient) GetLogEntries(filter FilterOptions) ([]LogEntry, error) {
+	return c.GetLogEntriesContext(context.Background(), filter)
+}
+
+// GetLogEntriesContext получает записи из лога, ожидая ответ не дольше, чем позволяет ctx
+// При истечении срока или отмене ctx возвращается ошибка, оборачивающая ctx.Err().
+// Сервер дополнительно ограничивает чтение лога значением QueryTimeout.
+func (c *LogClient) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
 	// Валидируем фильтр на клиенте
 	if err := filter.Validate(); err != nil {
 		return nil, err
 	}
 
-	response, err := c.sendRequest(MsgTypeGetEntries, filter)
+	response, err := c.sendRequestContext(ctx, MsgTypeGetEntries, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	if response.Type == MsgTypeError {
-		return nil, fmt.Errorf("ошибка сервера: %v", response.Data)
+		return nil, newServerError(response)
 	}
 
-	// Преобразуем ответ в []LogEntry
-	entriesData, err := json.Marshal(response.Data)
+	// Преобразуем ответ в []LogEntry (большие ответы могут быть сжаты)
+	var entries []LogEntry
+	if err := decodeResponseData(response, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// StreamLogEntries получает записи лога частями и передает их по одной в fn
+// В отличие от GetLogEntries весь результат не хранится в памяти ни на сервере,
+// ни на клиенте. Возврат false из fn прекращает чтение: соединение запросов
+// при этом закрывается и откроется заново при следующем запросе.
+func (c *LogClient) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	if fn == nil {
+		return fmt.Errorf("не задан обработчик записей")
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if err := c.writeRequest(MsgTypeStreamEntries, filter); err != nil {
+		return err
+	}
+
+	for {
+		response, err := c.readResponse()
+		if err != nil {
+			return err
+		}
+
+		switch response.Type {
+		case MsgTypeEntriesChunk:
+			var entries []LogEntry
+			if err := decodeResponseData(response, &entries); err != nil {
+				_ = c.control.close()
+				return err
+			}
+			for _, entry := range entries {
+				if !fn(entry) {
+					// Остаток ответа не нужен, соединение сбрасываем вместе с ним
+					_ = c.control.close()
+					return nil
+				}
+			}
+
+		case MsgTypeStreamEnd:
+			return nil
+
+		case MsgTypeError:
+			return newServerError(response)
+
+		default:
+			_ = c.control.close()
+			return fmt.Errorf("неожиданный ответ сервера: %s", response.Type)
+		}
+	}
+}
+
+// ExportCSV выгружает подходящие под фильтр записи лога в w в формате CSV
+// Записи читаются потоком через StreamLogEntries (см. LogServer.ExportCSV).
+func (c *LogClient) ExportCSV(w io.Writer, filter FilterOptions) error {
+	return c.ExportCSVWithOptions(w, filter, CSVOptions{})
+}
+
+// ExportCSVWithOptions выгружает записи лога в CSV с выгрузкой дополнительных полей
+func (c *LogClient) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeCSV(w, options, func(fn func(LogEntry) bool) error {
+		return c.StreamLogEntries(filter, fn)
+	})
+}
+
+// ExportNDJSON выгружает подходящие под фильтр записи лога в w по одному JSON объекту на строку
+func (c *LogClient) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeNDJSON(w, func(fn func(LogEntry) bool) error {
+		return c.StreamLogEntries(filter, fn)
+	})
+}
+
+// GetRange получает записи из диапазона строк файла лога
+// start - номер первой строки (нумерация с 1), count - количество строк
+func (c *LogClient) GetRange(start, count int) ([]LogEntry, error) {
+	return c.GetFilteredRange(start, count, FilterOptions{})
+}
+
+// GetFilteredRange получает записи из диапазона строк с дополнительной фильтрацией
+func (c *LogClient) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	req := RangeRequest{
+		Start:  start,
+		Count:  count,
+		Filter: filter,
+	}
+
+	// Валидируем запрос на клиенте
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendRequest(MsgTypeGetRange, req)
 	if err != nil {
 		return nil, err
 	}
 
+	if response.Type == MsgTypeError {
+		return nil, newServerError(response)
+	}
+
+	// Преобразуем ответ в []LogEntry (большие ответы могут быть сжаты)
 	var entries []LogEntry
-	if err := json.Unmarshal(entriesData, &entries); err != nil {
+	if err := decodeResponseData(response, &entries); err != nil {
 		return nil, err
 	}
 
@@ -374,6 +1357,31 @@ func (c *LogClient) Ping() error {
 	return nil
 }
 
+// Health запрашивает у сервера состояние записи лога
+func (c *LogClient) Health() (HealthStatus, error) {
+	var status HealthStatus
+
+	response, err := c.sendRequest(MsgTypeHealth, nil)
+	if err != nil {
+		return status, err
+	}
+
+	if response.Type == MsgTypeError {
+		return status, newServerError(response)
+	}
+
+	statusData, err := json.Marshal(response.Data)
+	if err != nil {
+		return status, err
+	}
+
+	if err := json.Unmarshal(statusData, &status); err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
 // LogPanic обработчик паники с логированием
 func (c *LogClient) LogPanic() {
 	if r := recover(); r != nil {
@@ -398,8 +1406,122 @@ func (c *LogClient) RecoverPanic(serviceName string) {
 	}
 }
 
-// Close закрывает соединение с сервером
+// CloseAndFlush дожидается записи отправленных сообщений на диск и закрывает клиент
+// Запрос сброса идет по тем же соединениям, что и сообщения, поэтому сервер
+// отвечает только после записи и синхронизации всего отправленного ранее.
+// Соединения закрываются даже при ошибке сброса; возвращается первая ошибка.
+func (c *LogClient) CloseAndFlush() error {
+	err := c.Flush()
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Flush дожидается записи на диск всех сообщений, отправленных клиентом
+// Запрос сброса отправляется по каждому соединению, через которое шли сообщения,
+// и не зависит от FlushInterval сервера.
+func (c *LogClient) Flush() error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	// Сначала отправляем накопленный пакет, иначе сервер не узнает о его сообщениях
+	if err := c.flushBatch(); err != nil {
+		return err
+	}
+
+	if len(c.pool) > 0 {
+		var firstErr error
+		for _, sc := range c.pool {
+			sc.mu.Lock()
+			if sc.encoder != nil {
+				if err := flushStream(sc.conn, sc.encoder, sc.decoder); err != nil {
+					_ = sc.close()
+					if firstErr == nil {
+						firstErr = err
+					}
+				}
+			}
+			sc.mu.Unlock()
+		}
+		return firstErr
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Без соединения на сервере не осталось отправленных этим клиентом сообщений
+	if !c.connected || c.conn == nil || c.encoder == nil {
+		return nil
+	}
+
+	if err := flushStream(c.conn, c.encoder, c.decoder); err != nil {
+		c.connected = false
+		return err
+	}
+	return nil
+}
+
+// flushStream отправляет запрос сброса по соединению и ждет подтверждения сервера
+func flushStream(conn net.Conn, encoder *json.Encoder, decoder *json.Decoder) error {
+	if err := encoder.Encode(ProtocolMessage{Type: MsgTypeFlush}); err != nil {
+		return fmt.Errorf("ошибка отправки запроса сброса: %w", err)
+	}
+
+	// Сервер ждет записи не дольше DEFAULT_CONNECTION_TIMEOUT, оставляем запас на ответ
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Duration(DEFAULT_CONNECTION_TIMEOUT) * time.Second))
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+
+	// Ошибки лимита скорости в ответ на отправленные ранее сообщения могут прийти
+	// раньше ответа на сброс; сервер не ограничивает сам сброс, поэтому они пропускаются
+	for {
+		var response ProtocolMessage
+		if err := decoder.Decode(&response); err != nil {
+			return fmt.Errorf("ошибка получения ответа на сброс: %w", err)
+		}
+
+		if response.Type == MsgTypeError && response.Code == ErrorCodeRateLimited {
+			continue
+		}
+		if response.Type == MsgTypeError {
+			return newServerError(&response)
+		}
+		return nil
+	}
+}
+
+// Close закрывает соединения с сервером
+// Повторный вызов безопасен и возвращает nil. После закрытия отправка сообщений
+// и запросов возвращает ErrClosed, сообщения при этом выводятся в stderr.
+// Переподключение, ожидающее следующей попытки, прерывается с ошибкой ErrClosed.
 func (c *LogClient) Close() error {
+	// Накопленный пакет отправляется до закрытия; под batchMu новые сообщения
+	// не попадут в пакет после отправки
+	c.batchMu.Lock()
+	_ = c.sendBatchLocked()
+	alreadyClosed := c.closed.Swap(true)
+	c.batchMu.Unlock()
+	if alreadyClosed {
+		return nil
+	}
+	if c.closing != nil {
+		close(c.closing)
+	}
+
+	// Фоновая горутина берет c.mu, поэтому останавливаем ее до блокировки
+	c.stopBackground()
+
+	for _, sc := range c.pool {
+		sc.mu.Lock()
+		_ = sc.close()
+		sc.mu.Unlock()
+	}
+
+	c.control.mu.Lock()
+	_ = c.control.close()
+	c.control.mu.Unlock()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -417,7 +1539,12 @@ func (c *LogClient) Close() error {
 }
 
 // SetService возвращает логгер для указанного сервиса (с кешированием)
+// Имя приводится к верхнему регистру; недопустимое имя сообщается в stderr,
+// а методы записи полученного логгера возвращают ошибку.
 func (c *LogClient) SetService(service string) *ServiceLogger {
+	// Кешируем логгеры по нормализованному имени, "api" и "API" - один сервис
+	service = NormalizeServiceName(service)
+
 	c.servicesMu.RLock()
 	serviceLogger, exists := c.serviceLoggers[service]
 	c.servicesMu.RUnlock()
@@ -435,12 +1562,25 @@ func (c *LogClient) SetService(service string) *ServiceLogger {
 		return serviceLogger
 	}
 
-	serviceLogger = newServiceLogger(c, service)
+	serviceLogger = newServiceLoggerWithRules(c, service, c.serviceNames)
 	c.serviceLoggers[service] = serviceLogger
 	return serviceLogger
 }
 
 // Основные функции логирования для MAIN сервиса
+// Trace логирует сообщение уровня TRACE (подробнее DEBUG, например дампы протокола)
+// Поддерживает те же форматы вызова, что и Debug
+func (c *LogClient) Trace(args ...interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("отсутствуют аргументы")
+	}
+
+	// Обрабатываем аргументы с помощью общей функции processArgs
+	message, fields := processArgs(args...)
+
+	return c.sendMessage("MAIN", TRACE, message, fields)
+}
+
 // Debug логирует сообщение уровня DEBUG
 // Поддерживает различные форматы вызова:
 // - Debug(message string) - простое сообщение
@@ -522,15 +1662,22 @@ func (c *LogClient) Fatal(args ...interface{}) error {
 
 	// Обрабатываем аргументы с помощью общей функции processArgs
 	message, fields := processArgs(args...)
+	return c.fatal("MAIN", message, fields)
+}
 
-	// Немедленно выводим сообщение в stderr, чтобы тесты могли зафиксировать "fatal" в выводе
-	c.fallbackToStderr("MAIN", FATAL, message, time.Now(), fields)
-	// Пытаемся отправить сообщение серверу (ошибку игнорируем, т.к. процесс завершится)
-	_ = c.sendMessage("MAIN", FATAL, message, fields)
-	os.Exit(1)
+// fatal выводит сообщение FATAL сервиса в stderr, отправляет его серверу и завершает программу
+func (c *LogClient) fatal(service string, message string, fields map[string]string) error {
+	c.terminal(service, FATAL, message, fields)
+	exitFunc(1)
 	return nil
 }
 
+// panic выводит сообщение PANIC сервиса в stderr, отправляет его серверу и вызывает панику
+// с текстом после обработчиков
+func (c *LogClient) panic(service string, message string, fields map[string]string) error {
+	panic(c.terminal(service, PANIC, message, fields))
+}
+
 // Panic логирует сообщение уровня PANIC и вызывает панику
 // Поддерживает различные форматы вызова:
 // - Panic(message string) - простое сообщение
@@ -544,12 +1691,7 @@ func (c *LogClient) Panic(args ...interface{}) error {
 
 	// Обрабатываем аргументы с помощью общей функции processArgs
 	message, fields := processArgs(args...)
-
-	// Немедленно выводим сообщение в stderr
-	c.fallbackToStderr("MAIN", PANIC, message, time.Now(), fields)
-	// Пытаемся отправить сообщение серверу
-	_ = c.sendMessage("MAIN", PANIC, message, fields)
-	panic(message)
+	return c.panic("MAIN", message, fields)
 }
 
 // Устаревшие методы с суффиксом WithFields удалены.
@@ -8,6 +8,8 @@ import (
 	"net"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -33,6 +35,8 @@ func TestClientPing(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод Ping
 	err := client.Ping()
@@ -85,6 +89,8 @@ func TestClientPingError(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод Ping
 	err := client.Ping()
@@ -108,6 +114,8 @@ func TestClientPingConnectionError(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод Ping
 	err := client.Ping()
@@ -117,9 +125,12 @@ func TestClientPingConnectionError(t *testing.T) {
 		t.Fatal("ожидалась ошибка, но получен nil")
 	}
 
-	// Проверяем, что клиент помечен как отключенный
-	if client.connected {
-		t.Error("флаг connected должен быть false после ошибки соединения")
+	// Ошибка закрывает соединение запросов, не затрагивая соединение сообщений
+	if client.control.conn != nil {
+		t.Error("соединение запросов должно быть закрыто после ошибки соединения")
+	}
+	if !client.connected {
+		t.Error("ошибка запроса не должна разрывать соединение сообщений")
 	}
 }
 
@@ -214,13 +225,14 @@ func TestReconnect(t *testing.T) {
 	// Создаем клиент с конфигурацией
 	client := &LogClient{
 		config: &LoggingConfig{
-			SocketPath: "/tmp/logger.sock",
+			SocketPath:           "/tmp/logger.sock",
+			ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS,
 		},
 		connected: false,
 	}
 
 	// Вызываем метод reconnect
-	err := client.reconnect()
+	err := reconnectLocked(client)
 
 	// Проверяем результаты
 	if err != nil {
@@ -247,6 +259,13 @@ func TestReconnect(t *testing.T) {
 	}
 }
 
+// reconnectLocked вызывает reconnect под c.mu, как sendFrame
+func reconnectLocked(client *LogClient) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.reconnect()
+}
+
 // TestReconnectError проверяет обработку ошибки переподключения
 func TestReconnectError(t *testing.T) {
 	// Сохраняем оригинальную функцию net.DialTimeout для восстановления после теста
@@ -262,13 +281,14 @@ func TestReconnectError(t *testing.T) {
 	// Создаем клиент с конфигурацией
 	client := &LogClient{
 		config: &LoggingConfig{
-			SocketPath: "/tmp/logger.sock",
+			SocketPath:           "/tmp/logger.sock",
+			ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS,
 		},
 		connected: false,
 	}
 
 	// Вызываем метод reconnect
-	err := client.reconnect()
+	err := reconnectLocked(client)
 
 	// Проверяем результаты
 	if err == nil {
@@ -286,6 +306,253 @@ func TestReconnectError(t *testing.T) {
 	}
 }
 
+// TestReconnectPolicy проверяет настройку попыток и задержек переподключения
+func TestReconnectPolicy(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+
+	attempts := 0
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		attempts++
+		return nil, errors.New("ошибка подключения")
+	}
+
+	client := &LogClient{
+		config: &LoggingConfig{
+			SocketPath:              "/tmp/logger.sock",
+			ReconnectMaxAttempts:    3,
+			ReconnectInitialBackoff: time.Millisecond,
+			ReconnectMaxBackoff:     2 * time.Millisecond,
+		},
+	}
+
+	if err := reconnectLocked(client); err == nil {
+		t.Fatal("ожидалась ошибка переподключения")
+	}
+	if attempts != 3 {
+		t.Errorf("ожидалось 3 попытки подключения, выполнено %d", attempts)
+	}
+
+	// Нулевые задержки заменяются значениями по умолчанию, ноль попыток - без ограничения
+	client.config = &LoggingConfig{}
+	maxAttempts, backoff, maxBackoff := client.reconnectPolicy()
+	if maxAttempts != 0 {
+		t.Errorf("ожидалось переподключение без ограничения, получено %d попыток", maxAttempts)
+	}
+	if backoff != time.Duration(DEFAULT_RECONNECT_BACKOFF_MS)*time.Millisecond {
+		t.Errorf("неожиданная начальная задержка по умолчанию: %v", backoff)
+	}
+	if maxBackoff != time.Duration(DEFAULT_RECONNECT_MAX_BACKOFF)*time.Second {
+		t.Errorf("неожиданная максимальная задержка по умолчанию: %v", maxBackoff)
+	}
+
+	// Нулевое количество попыток - переподключение до успеха
+	client.config = &LoggingConfig{
+		SocketPath:              "/tmp/logger.sock",
+		ReconnectInitialBackoff: time.Millisecond,
+	}
+	mockConn := newMockConn()
+	attempts = 0
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		attempts++
+		if attempts < 10 {
+			return nil, errors.New("ошибка подключения")
+		}
+		return mockConn, nil
+	}
+	if err := reconnectLocked(client); err != nil {
+		t.Fatalf("ожидалось успешное переподключение: %v", err)
+	}
+	if attempts != 10 {
+		t.Errorf("ожидалось 10 попыток подключения, выполнено %d", attempts)
+	}
+
+	if _, err := NewLogClient(&LoggingConfig{ReconnectMaxBackoff: -time.Second}); err == nil {
+		t.Error("ожидалась ошибка для отрицательной задержки переподключения")
+	}
+	if _, err := NewLogClient(&LoggingConfig{ReconnectMaxAttempts: -1}); err == nil {
+		t.Error("ожидалась ошибка для отрицательного количества попыток переподключения")
+	}
+}
+
+// TestCloseDuringReconnect проверяет, что Close прерывает переподключение без
+// ограничения попыток, а отправка, ожидавшая переподключения, получает ErrClosed
+func TestCloseDuringReconnect(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+
+	var attempts atomic.Int32
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		if attempts.Add(1) == 1 {
+			return newMockConn(), nil
+		}
+		return nil, errors.New("ошибка подключения")
+	}
+
+	client, err := NewLogClient(&LoggingConfig{
+		SocketPath:              "/tmp/logger.sock",
+		ReconnectInitialBackoff: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("не удалось создать клиент: %v", err)
+	}
+	client.mu.Lock()
+	client.connected = false
+	client.mu.Unlock()
+
+	sent := make(chan error, 1)
+	go func() { sent <- client.sendFrame(ProtocolMessage{Type: MsgTypeLog}) }()
+
+	// Ждем первой неудачной попытки: дальше клиент ждет следующую час
+	deadline := time.Now().Add(5 * time.Second)
+	for attempts.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("переподключение не началось")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close не должен ждать окончания переподключения")
+	}
+
+	select {
+	case err := <-sent:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("ожидалась ErrClosed, получено: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("отправка должна прерываться закрытием клиента")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("после закрытия попыток подключения быть не должно, выполнено %d", n-1)
+	}
+}
+
+// TestBackgroundReconnect проверяет фоновое восстановление соединения
+func TestBackgroundReconnect(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+
+	var dials int32
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return newMockConn(), nil
+	}
+
+	client, err := NewLogClient(&LoggingConfig{
+		SocketPath:        "/tmp/logger.sock",
+		Level:             "info",
+		ReconnectInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("не удалось создать клиент: %v", err)
+	}
+
+	// Имитируем потерю соединения
+	client.mu.Lock()
+	client.connected = false
+	client.mu.Unlock()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		client.mu.Lock()
+		connected := client.connected
+		client.mu.Unlock()
+		if connected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("соединение не было восстановлено в фоне")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&dials) != 2 {
+		t.Errorf("ожидалось 2 подключения, выполнено %d", atomic.LoadInt32(&dials))
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("ошибка закрытия клиента: %v", err)
+	}
+
+	// После Close фоновая горутина не должна переподключаться
+	after := atomic.LoadInt32(&dials)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&dials) != after {
+		t.Error("после Close не должно быть фоновых подключений")
+	}
+}
+
+// TestClientCloseConcurrent проверяет повторное закрытие и закрытие во время логирования
+func TestClientCloseConcurrent(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.RateLimit = 100000
+	_, client := startTestServerWithClient(t, config)
+
+	// Сообщения после закрытия уходят в stderr, скрываем их из вывода теста
+	origStderr := os.Stderr
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("не удалось открыть %s: %v", os.DevNull, err)
+	}
+	os.Stderr = devNull
+	defer func() {
+		os.Stderr = origStderr
+		devNull.Close()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := client.Info("сообщение во время закрытия"); err != nil && !errors.Is(err, ErrClosed) {
+					t.Errorf("ожидалась ErrClosed или nil, получено: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Close(); err != nil {
+				t.Errorf("ошибка закрытия клиента: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := client.Close(); err != nil {
+		t.Errorf("повторное закрытие должно быть безопасным: %v", err)
+	}
+	if err := client.Info("после закрытия"); !errors.Is(err, ErrClosed) {
+		t.Errorf("ожидалась ErrClosed после закрытия, получено: %v", err)
+	}
+	if err := client.Ping(); !errors.Is(err, ErrClosed) {
+		t.Errorf("ожидалась ErrClosed для запроса после закрытия, получено: %v", err)
+	}
+}
+
+// TestJitter проверяет диапазон случайной задержки
+func TestJitter(t *testing.T) {
+	base := 100 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		if d := jitter(base); d < base/2 || d >= base {
+			t.Fatalf("задержка %v вне диапазона [%v, %v)", d, base/2, base)
+		}
+	}
+	if d := jitter(time.Nanosecond); d != time.Nanosecond {
+		t.Errorf("слишком малая задержка должна возвращаться без изменений, получено %v", d)
+	}
+}
+
 // TestRecoverPanic проверяет обработку паники
 func TestRecoverPanic(t *testing.T) {
 	// Создаем клиент без конфигурации - это нормально для теста RecoverPanic,
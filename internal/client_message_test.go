@@ -11,6 +11,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // Константы для тестирования
@@ -43,8 +44,8 @@ func TestSendMessage(t *testing.T) {
 		decoder:        json.NewDecoder(mockConn),
 		level:          DEBUG, // Устанавливаем уровень DEBUG, чтобы все сообщения проходили
 		connected:      true,
-		config:         &LoggingConfig{SocketPath: "/tmp/test.sock"}, // Добавляем конфигурацию
-		serviceLoggers: make(map[string]*ServiceLogger),              // Инициализируем карту сервисов
+		config:         &LoggingConfig{SocketPath: "/tmp/test.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}, // Добавляем конфигурацию
+		serviceLoggers: make(map[string]*ServiceLogger),                                                                // Инициализируем карту сервисов
 	}
 
 	// Вызываем метод sendMessage
@@ -107,8 +108,8 @@ func TestSendMessageLevelFiltering(t *testing.T) {
 		decoder:        json.NewDecoder(mockConn),
 		level:          INFO, // Устанавливаем уровень INFO
 		connected:      true,
-		config:         &LoggingConfig{SocketPath: "/tmp/test.sock"}, // Добавляем конфигурацию
-		serviceLoggers: make(map[string]*ServiceLogger),              // Инициализируем карту сервисов
+		config:         &LoggingConfig{SocketPath: "/tmp/test.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}, // Добавляем конфигурацию
+		serviceLoggers: make(map[string]*ServiceLogger),                                                                // Инициализируем карту сервисов
 	}
 
 	// Проверяем фильтрацию DEBUG сообщений
@@ -154,8 +155,8 @@ func TestSendMessageConnectionError(t *testing.T) {
 		decoder:        json.NewDecoder(mockConn),
 		level:          DEBUG,
 		connected:      true,
-		config:         &LoggingConfig{SocketPath: "/tmp/test.sock"}, // Добавляем конфигурацию
-		serviceLoggers: make(map[string]*ServiceLogger),              // Инициализируем карту сервисов
+		config:         &LoggingConfig{SocketPath: "/tmp/test.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}, // Добавляем конфигурацию
+		serviceLoggers: make(map[string]*ServiceLogger),                                                                // Инициализируем карту сервисов
 	}
 
 	// Сохраняем оригинальный stderr и создаем буфер для перехвата
@@ -292,3 +293,192 @@ func TestSendMessageReconnect(t *testing.T) {
 		t.Error("флаг connected должен быть true после успешного переподключения")
 	}
 }
+
+// TestSendMessageLevelOff проверяет, что уровень OFF отключает отправку любых сообщений
+func TestSendMessageLevelOff(t *testing.T) {
+	mockConn := newMockConn()
+	client := &LogClient{
+		conn:           mockConn,
+		encoder:        json.NewEncoder(mockConn),
+		decoder:        json.NewDecoder(mockConn),
+		level:          OFF,
+		connected:      true,
+		config:         &LoggingConfig{SocketPath: "/tmp/test.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS},
+		serviceLoggers: make(map[string]*ServiceLogger),
+	}
+
+	for level := TRACE; level <= PANIC; level++ {
+		if err := client.sendMessage("TEST", level, "не должно отправляться", nil); err != nil {
+			t.Errorf("уровень %v: неожиданная ошибка: %v", level, err)
+		}
+	}
+
+	// Сообщение с уровнем OFF не отправляется даже при низком пороге
+	client.SetLevel(DEBUG)
+	if err := client.sendMessage("TEST", OFF, "уровень OFF", nil); err != nil {
+		t.Errorf("неожиданная ошибка: %v", err)
+	}
+
+	if written := mockConn.GetWrittenData(); len(written) != 0 {
+		t.Errorf("при уровне OFF в соединение ничего не должно записываться, записано: %q", written)
+	}
+
+	if err := ValidateMessage(&LogMessage{Service: "TEST", Level: OFF, Message: "x"}, DefaultSecurityConfig()); err == nil {
+		t.Error("сервер должен отклонять сообщения с уровнем OFF")
+	}
+}
+
+// TestSendMessageTraceFiltered проверяет, что TRACE ниже DEBUG и отсекается порогом DEBUG
+func TestSendMessageTraceFiltered(t *testing.T) {
+	if !(TRACE < DEBUG && DEBUG < INFO) {
+		t.Fatal("порядок уровней должен быть TRACE < DEBUG < INFO")
+	}
+
+	mockConn := newMockConn()
+	client := &LogClient{
+		conn:           mockConn,
+		encoder:        json.NewEncoder(mockConn),
+		decoder:        json.NewDecoder(mockConn),
+		level:          DEBUG,
+		connected:      true,
+		config:         &LoggingConfig{SocketPath: "/tmp/test.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS},
+		serviceLoggers: make(map[string]*ServiceLogger),
+	}
+
+	if err := client.Trace("дамп протокола"); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if written := mockConn.GetWrittenData(); len(written) != 0 {
+		t.Fatalf("TRACE не должен отправляться при пороге DEBUG, записано: %q", written)
+	}
+
+	client.SetLevel(TRACE)
+	if err := client.Trace("дамп протокола"); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if written := mockConn.GetWrittenData(); !strings.Contains(string(written), "дамп протокола") {
+		t.Errorf("TRACE должен отправляться при пороге TRACE, записано: %q", written)
+	}
+}
+
+// TestFitMessage проверяет укорачивание текста, не помещающегося в кадр
+func TestFitMessage(t *testing.T) {
+	client := &LogClient{config: &LoggingConfig{MaxMessageSize: 512}}
+	limit := client.frameLimit() - frameOverhead
+
+	// Короткое сообщение не меняется
+	msg := LogMessage{Service: "API", Level: INFO, Message: "короткое", Timestamp: time.Now()}
+	client.fitMessage(&msg)
+	if msg.Message != "короткое" {
+		t.Errorf("короткое сообщение изменено: %q", msg.Message)
+	}
+
+	// Длинный текст с многобайтовыми символами и экранируемыми байтами
+	text := strings.Repeat("длинный <текст>\n", 200)
+	msg.Message = text
+	client.fitMessage(&msg)
+
+	if !strings.HasSuffix(msg.Message, truncatedMarker) {
+		t.Fatalf("ожидалась отметка %q в конце: %q", truncatedMarker, msg.Message)
+	}
+	if !utf8.ValidString(msg.Message) {
+		t.Error("текст должен обрезаться по границе символа")
+	}
+	if !strings.HasPrefix(text, strings.TrimSuffix(msg.Message, truncatedMarker)) {
+		t.Error("должно сохраняться начало текста")
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("ошибка кодирования: %v", err)
+	}
+	if len(raw) > limit {
+		t.Errorf("закодированное сообщение %d байт больше лимита %d", len(raw), limit)
+	}
+
+	// Лимит, объявленный сервером, учитывается, если он меньше собственного
+	client.serverCaps.Store(&Capabilities{MaxMessageSize: 256})
+	if got := client.frameLimit(); got != 256 {
+		t.Errorf("ожидался лимит сервера 256, получено %d", got)
+	}
+}
+
+// TestSendOversizedMessage проверяет, что длинное сообщение не разрывает соединение
+func TestSendOversizedMessage(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.MaxMessageSize = 1024
+	server, client := startTestServerWithClient(t, config)
+
+	if err := client.Info(strings.Repeat("x", 10*1024)); err != nil {
+		t.Fatalf("ошибка отправки длинного сообщения: %v", err)
+	}
+	if err := client.Info("после длинного"); err != nil {
+		t.Fatalf("ошибка отправки после длинного сообщения: %v", err)
+	}
+
+	waitForLogContent(t, server, "после длинного")
+	waitForLogContent(t, server, "xxx"+truncatedMarker)
+}
+
+// TestFitMessageFields проверяет укорачивание длинных значений полей
+func TestFitMessageFields(t *testing.T) {
+	client := &LogClient{config: &LoggingConfig{MaxMessageSize: 2048}}
+	limit := client.frameLimit() - frameOverhead
+
+	fields := map[string]string{
+		"payload": strings.Repeat("п", 50*1024), // 100KB в UTF-8
+		"user":    "admin",
+	}
+	msg := LogMessage{Service: "API", Level: INFO, Message: "запрос", Timestamp: time.Now(), Fields: fields}
+	client.fitMessage(&msg)
+
+	if msg.Message != "запрос" {
+		t.Errorf("короткий текст не должен меняться: %q", msg.Message)
+	}
+	if msg.Fields["user"] != "admin" {
+		t.Errorf("короткое поле не должно меняться: %q", msg.Fields["user"])
+	}
+	payload := msg.Fields["payload"]
+	if !strings.HasSuffix(payload, truncatedMarker) || !utf8.ValidString(payload) {
+		t.Errorf("длинное поле должно укорачиваться по границе символа с отметкой: %q", payload)
+	}
+	if len(fields["payload"]) != 100*1024 {
+		t.Error("карта полей вызывающего кода не должна изменяться")
+	}
+	if raw, _ := json.Marshal(msg); len(raw) > limit {
+		t.Errorf("закодированное сообщение %d байт больше лимита %d", len(raw), limit)
+	}
+	if got := client.TruncatedFields(); got != 1 {
+		t.Errorf("ожидалось 1 укороченное поле, получено %d", got)
+	}
+
+	// Поле с длинным ключом укоротить нельзя - оно отбрасывается
+	msg = LogMessage{Service: "API", Level: INFO, Message: "запрос", Timestamp: time.Now(),
+		Fields: map[string]string{strings.Repeat("k", 4096): "v", "user": "admin"}}
+	client.fitMessage(&msg)
+	if len(msg.Fields) != 1 || msg.Fields["user"] != "admin" {
+		t.Errorf("ожидалось отбрасывание поля с длинным ключом: %v", msg.Fields)
+	}
+	if got := client.TruncatedFields(); got != 2 {
+		t.Errorf("ожидалось 2 измененных поля, получено %d", got)
+	}
+}
+
+// TestSendOversizedField проверяет, что поле размером 100KB не разрывает соединение
+func TestSendOversizedField(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	err := client.Info("большое поле", map[string]string{"payload": strings.Repeat("x", 100*1024)})
+	if err != nil {
+		t.Fatalf("ошибка отправки сообщения с большим полем: %v", err)
+	}
+	if err := client.Info("после большого поля"); err != nil {
+		t.Fatalf("ошибка отправки после большого поля: %v", err)
+	}
+
+	waitForLogContent(t, server, "после большого поля")
+	waitForLogContent(t, server, "xxx"+truncatedMarker)
+	if got := client.TruncatedFields(); got != 1 {
+		t.Errorf("ожидалось 1 укороченное поле, получено %d", got)
+	}
+}
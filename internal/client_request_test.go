@@ -3,9 +3,13 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"net"
 	"testing"
+	"time"
 )
 
 // Константы теперь определены в message.go
@@ -31,6 +35,8 @@ func TestSendRequest(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод sendRequest
 	msgType := MsgTypePing
@@ -86,6 +92,8 @@ func TestSendRequestWriteError(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод sendRequest
 	response, err := client.sendRequest(MsgTypePing, "PING")
@@ -99,9 +107,12 @@ func TestSendRequestWriteError(t *testing.T) {
 		t.Error("ответ должен быть nil при ошибке")
 	}
 
-	// Проверяем, что клиент помечен как отключенный
-	if client.connected {
-		t.Error("флаг connected должен быть false после ошибки записи")
+	// Ошибка закрывает соединение запросов, не затрагивая соединение сообщений
+	if client.control.conn != nil {
+		t.Error("соединение запросов должно быть закрыто после ошибки записи")
+	}
+	if !client.connected {
+		t.Error("ошибка запроса не должна разрывать соединение сообщений")
 	}
 }
 
@@ -118,6 +129,8 @@ func TestSendRequestReadError(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод sendRequest
 	response, err := client.sendRequest(MsgTypePing, "PING")
@@ -131,9 +144,198 @@ func TestSendRequestReadError(t *testing.T) {
 		t.Error("ответ должен быть nil при ошибке")
 	}
 
-	// Проверяем, что клиент помечен как отключенный
-	if client.connected {
-		t.Error("флаг connected должен быть false после ошибки чтения")
+	// Ошибка закрывает соединение запросов, не затрагивая соединение сообщений
+	if client.control.conn != nil {
+		t.Error("соединение запросов должно быть закрыто после ошибки чтения")
+	}
+	if !client.connected {
+		t.Error("ошибка запроса не должна разрывать соединение сообщений")
+	}
+}
+
+// TestSendRequestRetryAfterReconnect проверяет повтор запроса после переподключения,
+// когда соединение запросов оборвалось после отправки (например, сервер перезапустился)
+func TestSendRequestRetryAfterReconnect(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+
+	tests := []struct {
+		name     string
+		response ProtocolMessage
+		call     func(client *LogClient) error
+		msgType  string
+	}{
+		{
+			name:     "SetServerLevel",
+			response: ProtocolMessage{Type: MsgTypeResponse, Data: "OK"},
+			call:     func(client *LogClient) error { return client.SetServerLevel(DEBUG) },
+			msgType:  MsgTypeSetLevel,
+		},
+		{
+			name:     "GetLogEntries",
+			response: ProtocolMessage{Type: MsgTypeResponse, Data: []LogEntry{}},
+			call: func(client *LogClient) error {
+				_, err := client.GetLogEntries(FilterOptions{})
+				return err
+			},
+			msgType: MsgTypeGetEntries,
+		},
+		{
+			name:     "Ping",
+			response: ProtocolMessage{Type: MsgTypePong, Data: "PONG"},
+			call:     func(client *LogClient) error { return client.Ping() },
+			msgType:  MsgTypePing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Запись в старое соединение проходит, но ответа уже нет
+			staleConn := newMockConn()
+
+			freshConn := newMockConn()
+			responseData, _ := json.Marshal(tt.response)
+			freshConn.SetReadData(append(responseData, '\n'))
+
+			dials := 0
+			netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+				dials++
+				return freshConn, nil
+			}
+
+			client := &LogClient{config: &LoggingConfig{SocketPath: "/tmp/logger.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}}
+			client.control.attach(staleConn)
+
+			if err := tt.call(client); err != nil {
+				t.Fatalf("ожидался успешный повтор запроса, получена ошибка: %v", err)
+			}
+			if dials != 1 {
+				t.Errorf("ожидалось одно переподключение, получено %d", dials)
+			}
+
+			var sent ProtocolMessage
+			if err := json.Unmarshal(freshConn.GetWrittenData(), &sent); err != nil || sent.Type != tt.msgType {
+				t.Errorf("запрос %s должен быть повторен в новом соединении, отправлено %+v (%v)", tt.msgType, sent, err)
+			}
+		})
+	}
+}
+
+// TestSendRequestRetryOnce проверяет, что запрос повторяется не больше одного раза
+func TestSendRequestRetryOnce(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+
+	dials := 0
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dials++
+		return newMockConn(), nil // Новое соединение тоже не отвечает
+	}
+
+	client := &LogClient{config: &LoggingConfig{SocketPath: "/tmp/logger.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}}
+	client.control.attach(newMockConn())
+
+	if err := client.Ping(); err == nil {
+		t.Fatal("ожидалась ошибка, когда и повторный запрос остался без ответа")
+	}
+	if dials != 1 {
+		t.Errorf("ожидалось одно переподключение, получено %d", dials)
+	}
+
+	// Соединение, открытое самим запросом, не переподключается повторно
+	dials = 0
+	if err := client.Ping(); err == nil {
+		t.Fatal("ожидалась ошибка без ответа сервера")
+	}
+	if dials != 1 {
+		t.Errorf("запрос по новому соединению не должен повторяться, переподключений: %d", dials)
+	}
+}
+
+// TestGetLogEntriesContextTimeout проверяет, что срок контекста ограничивает ожидание ответа
+func TestGetLogEntriesContextTimeout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+
+	// Сервер читает запрос, но не отвечает
+	go func() { _, _ = io.Copy(io.Discard, serverConn) }()
+
+	client := &LogClient{config: &LoggingConfig{SocketPath: "/tmp/logger.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}}
+	client.control.attach(clientConn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetLogEntriesContext(ctx, FilterOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ожидалась ошибка истечения срока, получена: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("запрос должен прерываться по сроку контекста, прошло %v", elapsed)
+	}
+
+	// Опоздавший ответ не должен попасть в следующий запрос
+	if client.control.conn != nil {
+		t.Error("соединение запросов должно закрываться после истечения срока")
+	}
+}
+
+// lateContext контекст, срок которого прошел, но Err еще не отметил истечение:
+// так выглядит промежуток, в котором дедлайн соединения срабатывает раньше ctx
+type lateContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c lateContext) Deadline() (time.Time, bool) { return c.deadline, true }
+
+// TestSendRequestContextDeadlineNoRetry проверяет, что ошибка чтения после срока
+// контекста возвращается как context.DeadlineExceeded и запрос не повторяется
+func TestSendRequestContextDeadlineNoRetry(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+
+	dials := 0
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dials++
+		return newMockConn(), nil
+	}
+
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	go func() { _, _ = io.Copy(io.Discard, serverConn) }()
+
+	client := &LogClient{config: &LoggingConfig{SocketPath: "/tmp/logger.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}}
+	client.control.attach(clientConn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	late := lateContext{Context: ctx, deadline: time.Now().Add(-time.Millisecond)}
+
+	_, err := client.sendRequestContext(late, MsgTypePing, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ожидалась ошибка истечения срока, получена: %v", err)
+	}
+	if dials != 0 {
+		t.Errorf("запрос с истекшим сроком не должен повторяться, переподключений: %d", dials)
+	}
+}
+
+// TestGetLogEntriesContextCanceled проверяет, что отмененный контекст не отправляет запрос
+func TestGetLogEntriesContextCanceled(t *testing.T) {
+	mockConn := newMockConn()
+	client := &LogClient{}
+	client.control.attach(mockConn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GetLogEntriesContext(ctx, FilterOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ожидалась ошибка отмены, получена: %v", err)
+	}
+	if len(mockConn.GetWrittenData()) != 0 {
+		t.Error("запрос с отмененным контекстом не должен отправляться")
 	}
 }
 
@@ -158,6 +360,8 @@ func TestSetServerLevel(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод SetServerLevel
 	err := client.SetServerLevel(WARN)
@@ -211,6 +415,8 @@ func TestSetServerLevelError(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод SetServerLevel
 	err := client.SetServerLevel(WARN)
@@ -245,6 +451,8 @@ func TestClientGetLogFile(t *testing.T) {
 		config:         &LoggingConfig{},                // Добавляем пустую конфигурацию
 		serviceLoggers: make(map[string]*ServiceLogger), // Инициализируем карту логгеров
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод GetLogFile
 	path := client.GetLogFile()
@@ -280,6 +488,8 @@ func TestGetLogFileError(t *testing.T) {
 		decoder:   json.NewDecoder(mockConn),
 		connected: true,
 	}
+	// Запросы идут через отдельное соединение
+	client.control.attach(mockConn)
 
 	// Вызываем метод GetLogFile
 	path := client.GetLogFile()
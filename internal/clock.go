@@ -0,0 +1,152 @@
+// clock.go - Источник времени для сервера, клиента, кеша и ограничителя скорости
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock источник текущего времени и таймеров
+// По умолчанию используется SystemClock. В тестах FakeClock позволяет
+// управлять временем (TTL кеша, окна ограничителя скорости, таймеры сброса)
+// без ожидания через time.Sleep. Сетевые таймауты всегда отсчитываются по
+// системным часам, так как их проверяет операционная система.
+type Clock interface {
+	Now() time.Time                   // Текущее время
+	NewTicker(d time.Duration) Ticker // Таймер, срабатывающий каждые d
+}
+
+// Ticker периодический таймер, аналог time.Ticker
+type Ticker interface {
+	C() <-chan time.Time   // Канал срабатываний
+	Stop()                 // Остановка таймера
+	Reset(d time.Duration) // Смена периода
+}
+
+// SystemClock часы на основе пакета time
+var SystemClock Clock = systemClock{}
+
+// systemClock реализация Clock через time.Now и time.NewTicker
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker обертка над time.Ticker
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time   { return t.ticker.C }
+func (t systemTicker) Stop()                 { t.ticker.Stop() }
+func (t systemTicker) Reset(d time.Duration) { t.ticker.Reset(d) }
+
+// clockOrSystem возвращает clock или SystemClock, если часы не заданы
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// FakeClock управляемые часы для тестов
+// Время меняется только вызовом Advance. Таймеры срабатывают при переходе
+// через очередной период; как и у time.Ticker, в канале хранится не больше
+// одного срабатывания, лишние пропускаются.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFakeClock создает управляемые часы, показывающие время now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now возвращает текущее время часов
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker создает таймер, срабатывающий при Advance через каждые d
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("FakeClock.NewTicker: период должен быть положительным")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ticker := &fakeTicker{
+		clock:  c,
+		ch:     make(chan time.Time, 1),
+		period: d,
+		next:   c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance переводит часы вперед на d и запускает наступившие срабатывания таймеров
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, ticker := range c.tickers {
+		for !ticker.next.After(c.now) {
+			select {
+			case ticker.ch <- ticker.next:
+			default:
+				// Получатель не успел прочитать предыдущее срабатывание
+			}
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+}
+
+// fakeTicker таймер FakeClock (поля защищены мьютексом часов)
+// Остановленный таймер удаляется из списка часов и возвращается в него при Reset.
+type fakeTicker struct {
+	clock   *FakeClock
+	ch      chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, ticker := range t.clock.tickers {
+		if ticker == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			break
+		}
+	}
+	t.stopped = true
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("FakeClock: период таймера должен быть положительным")
+	}
+
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.period = d
+	t.next = t.clock.now.Add(d)
+	if t.stopped {
+		t.clock.tickers = append(t.clock.tickers, t)
+		t.stopped = false
+	}
+}
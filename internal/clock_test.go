@@ -0,0 +1,94 @@
+// clock_test.go - Тесты для управляемых часов
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFakeClockTicker проверяет срабатывания, пропуск лишних тиков, Reset и Stop
+func TestFakeClockTicker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	select {
+	case <-ticker.C():
+		t.Fatal("таймер не должен срабатывать без Advance")
+	default:
+	}
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-ticker.C():
+		t.Fatal("таймер не должен срабатывать до истечения периода")
+	default:
+	}
+
+	// За три периода в канале остается одно срабатывание, как у time.Ticker
+	clock.Advance(2*time.Second + time.Millisecond)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Second)) {
+		t.Errorf("неверное время срабатывания: %v", tick)
+	}
+	select {
+	case <-ticker.C():
+		t.Error("лишние срабатывания должны пропускаться")
+	default:
+	}
+	if now := clock.Now(); !now.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("неверное время часов: %v", now)
+	}
+
+	ticker.Reset(time.Minute)
+	clock.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("после Reset таймер должен срабатывать с новым периодом")
+	default:
+	}
+	clock.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+	default:
+		t.Error("таймер должен сработать через новый период")
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	select {
+	case <-ticker.C():
+		t.Error("остановленный таймер не должен срабатывать")
+	default:
+	}
+}
+
+// TestRateLimiterFakeClock проверяет окно и бан ограничителя скорости без ожидания
+func TestRateLimiterFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 2
+	config.Clock = clock
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	for i := 0; i < 2; i++ {
+		if !limiter.IsAllowed("client") {
+			t.Fatalf("сообщение %d должно быть разрешено", i+1)
+		}
+	}
+	if limiter.IsAllowed("client") {
+		t.Fatal("третье сообщение в секунду должно быть отклонено")
+	}
+
+	// Клиент забанен до истечения BanDuration, новая секунда не помогает
+	clock.Advance(time.Second)
+	if limiter.IsAllowed("client") {
+		t.Error("клиент должен оставаться забаненным")
+	}
+
+	clock.Advance(config.BanDuration)
+	if !limiter.IsAllowed("client") {
+		t.Error("после истечения бана сообщения должны разрешаться")
+	}
+}
@@ -0,0 +1,98 @@
+// compress.go - Сжатие больших ответов сервера в протоколе
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// EncodingGzip сжатие данных ответа gzip
+// Сжатые данные передаются в ProtocolMessage.Data строкой base64.
+const EncodingGzip = "gzip"
+
+// acceptsGzip проверяет, предложил ли клиент в приветствии сжатие gzip
+func acceptsGzip(hello Hello) bool {
+	for _, encoding := range hello.Compression {
+		if encoding == EncodingGzip {
+			return true
+		}
+	}
+	return false
+}
+
+// newDataResponse формирует ответ с данными, сжимая его при согласованном gzip
+// Ответы меньше DEFAULT_COMPRESS_THRESHOLD байт отправляются без сжатия:
+// для них затраты на сжатие больше выигрыша.
+func newDataResponse(data interface{}, compress bool) ProtocolMessage {
+	response := ProtocolMessage{
+		Type: MsgTypeResponse,
+		Data: data,
+	}
+	if !compress {
+		return response
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return response
+	}
+	// Уже закодированные данные не кодируем повторно
+	response.Data = json.RawMessage(payload)
+	if len(payload) < DEFAULT_COMPRESS_THRESHOLD {
+		return response
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return response
+	}
+	if err := writer.Close(); err != nil {
+		return response
+	}
+
+	response.Encoding = EncodingGzip
+	response.Data = buf.Bytes() // []byte кодируется в JSON как base64
+	return response
+}
+
+// decodeResponseData разбирает данные ответа сервера в v, распаковывая сжатые ответы
+func decodeResponseData(response *ProtocolMessage, v interface{}) error {
+	var payload []byte
+
+	switch response.Encoding {
+	case "":
+		data, err := json.Marshal(response.Data)
+		if err != nil {
+			return err
+		}
+		payload = data
+
+	case EncodingGzip:
+		encoded, ok := response.Data.(string)
+		if !ok {
+			return fmt.Errorf("неверный формат сжатого ответа")
+		}
+		compressed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("ошибка декодирования сжатого ответа: %w", err)
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return fmt.Errorf("ошибка распаковки ответа: %w", err)
+		}
+		defer reader.Close()
+		if payload, err = io.ReadAll(reader); err != nil {
+			return fmt.Errorf("ошибка распаковки ответа: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("неизвестное сжатие ответа: %s", response.Encoding)
+	}
+
+	return json.Unmarshal(payload, v)
+}
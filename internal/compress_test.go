@@ -0,0 +1,121 @@
+// compress_test.go - Тесты для сжатия больших ответов сервера
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDataResponseCompression проверяет порог сжатия и распаковку ответа
+func TestDataResponseCompression(t *testing.T) {
+	small := []string{"короткий ответ"}
+	large := []string{strings.Repeat("повторяющаяся строка ", DEFAULT_COMPRESS_THRESHOLD/10)}
+
+	tests := []struct {
+		name     string
+		data     []string
+		compress bool
+		encoding string
+	}{
+		{"без согласования", large, false, ""},
+		{"маленький ответ", small, true, ""},
+		{"большой ответ", large, true, EncodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := newDataResponse(tt.data, tt.compress)
+			if response.Encoding != tt.encoding {
+				t.Errorf("ожидалось сжатие %q, получено %q", tt.encoding, response.Encoding)
+			}
+
+			// Ответ проходит через JSON так же, как в сокете
+			encoded, err := json.Marshal(response)
+			if err != nil {
+				t.Fatalf("ошибка кодирования ответа: %v", err)
+			}
+			var received ProtocolMessage
+			if err := json.Unmarshal(encoded, &received); err != nil {
+				t.Fatalf("ошибка разбора ответа: %v", err)
+			}
+
+			var decoded []string
+			if err := decodeResponseData(&received, &decoded); err != nil {
+				t.Fatalf("ошибка разбора ответа: %v", err)
+			}
+			if len(decoded) != 1 || decoded[0] != tt.data[0] {
+				t.Error("данные ответа изменились после передачи")
+			}
+		})
+	}
+
+	if err := decodeResponseData(&ProtocolMessage{Encoding: "zstd"}, new([]string)); err == nil {
+		t.Error("ожидалась ошибка для неизвестного сжатия")
+	}
+}
+
+// TestClientCompressedResponses проверяет согласование сжатия и чтение большого лога
+func TestClientCompressedResponses(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			config := createTestServerConfig(t)
+			config.CompressResponses = compress
+			server, client := startTestServerWithClient(t, config)
+			writeTestEntries(server, 500)
+
+			entries, err := client.GetLogEntries(FilterOptions{Service: "BENCH", Limit: DEFAULT_MAX_QUERY_LIMIT})
+			if err != nil {
+				t.Fatalf("ошибка получения записей: %v", err)
+			}
+			if len(entries) != 500 {
+				t.Errorf("ожидалось 500 записей, получено %d", len(entries))
+			}
+
+			// Соединение запросов открывается при первом запросе со своим приветствием
+			expected := ""
+			if compress {
+				expected = EncodingGzip
+			}
+			if caps := client.serverCaps.Load(); caps == nil || caps.Compression != expected {
+				t.Errorf("ожидалось согласованное сжатие %q, получено %+v", expected, caps)
+			}
+		})
+	}
+}
+
+// BenchmarkGetLogEntries10k сравнивает чтение 10 000 записей со сжатием и без
+func BenchmarkGetLogEntries10k(b *testing.B) {
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compress=%v", compress), func(b *testing.B) {
+			config := createTestServerConfig(b)
+			config.CompressResponses = compress
+			server, client := startTestServerWithClient(b, config)
+			writeTestEntries(server, DEFAULT_MAX_QUERY_LIMIT)
+
+			filter := FilterOptions{Service: "BENCH", Limit: DEFAULT_MAX_QUERY_LIMIT}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				entries, err := client.GetLogEntries(filter)
+				if err != nil || len(entries) != DEFAULT_MAX_QUERY_LIMIT {
+					b.Fatalf("получено %d записей: %v", len(entries), err)
+				}
+			}
+		})
+	}
+}
+
+// writeTestEntries записывает в лог сервера count сообщений сервиса BENCH
+func writeTestEntries(server *LogServer, count int) {
+	now := time.Now()
+	for i := 0; i < count; i++ {
+		server.writeMessage(LogMessage{
+			Service:   "BENCH",
+			Level:     INFO,
+			Message:   fmt.Sprintf("запись номер %d для проверки чтения большого лога", i),
+			Timestamp: now,
+		})
+	}
+}
@@ -1,6 +1,46 @@
 package logger
 
-import "time"
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// SyncPolicy определяет, когда сервер вызывает fsync файла лога
+type SyncPolicy string
+
+const (
+	SyncOnError  SyncPolicy = "on_error" // После сообщений ERROR и выше и по таймеру FlushInterval (по умолчанию)
+	SyncInterval SyncPolicy = "interval" // Только по таймеру FlushInterval
+	SyncAlways   SyncPolicy = "always"   // После каждой записи в файл
+	SyncNever    SyncPolicy = "never"    // Только при явном Flush и остановке сервера
+)
+
+// IsValid проверяет, что политика синхронизации известна (пустое значение - политика по умолчанию)
+func (p SyncPolicy) IsValid() bool {
+	switch p {
+	case "", SyncOnError, SyncInterval, SyncAlways, SyncNever:
+		return true
+	}
+	return false
+}
+
+// Mode определяет, как New записывает сообщения: через сервер и сокет или напрямую в файл
+type Mode string
+
+const (
+	ModeServer Mode = "server" // Сервер с сокетом в том же процессе, клиент подключается к нему
+	ModeLocal  Mode = "local"  // Запись в файл лога без сокета (пустой Mode и SocketPath - тоже локальный режим)
+)
+
+// IsValid проверяет, что режим известен (пустое значение - выбор по SocketPath)
+func (m Mode) IsValid() bool {
+	switch m {
+	case "", ModeServer, ModeLocal:
+		return true
+	}
+	return false
+}
 
 // LoggingConfig определяет параметры системы логирования
 // Оптимизирован для минимального потребления ресурсов
@@ -11,6 +51,8 @@ type LoggingConfig struct {
 	SocketPath       string        `yaml:"socket_path"`       // Путь к Unix сокету для логов
 	MaxFileSize      float64       `yaml:"max_file_size"`     // Максимальный размер лог-файла в MB
 	MaxFiles         int           `yaml:"max_files"`         // Количество резервных копий лог-файлов
+	MaxFileAge       time.Duration `yaml:"max_file_age"`      // Максимальный возраст резервных копий по времени изменения (0 - без ограничения)
+	MaxTotalSize     int64         `yaml:"max_total_size"`    // Суммарный размер файла лога и резервных копий в байтах (0 - без ограничения)
 	MaxSize          int           `yaml:"max_size"`          // Старый формат: максимальный размер лог-файла в MB
 	MaxBackups       int           `yaml:"max_backups"`       // Старый формат: количество резервных копий
 	MaxAge           int           `yaml:"max_age"`           // Старый формат: максимальный возраст файлов в днях
@@ -18,6 +60,102 @@ type LoggingConfig struct {
 	Console          bool          `yaml:"console"`           // Старый формат: выводить в консоль
 	BufferSize       int           `yaml:"buffer_size"`       // Размер буфера сообщений в памяти в строках
 	FlushInterval    time.Duration `yaml:"flush_interval"`    // Интервал принудительного сброса буфера на диск
+	SyncPolicy       SyncPolicy    `yaml:"sync_policy"`       // Когда вызывать fsync файла лога (пусто - SyncOnError)
 	Services         []string      `yaml:"services"`          // Список разрешенных сервисов для логирования
 	RestrictServices bool          `yaml:"restrict_services"` // Ограничить логирование только указанными сервисами
+	HTTPAddr         string        `yaml:"http_addr"`         // Адрес HTTP API для чтения логов (пусто - отключен)
+	RateLimit        int           `yaml:"rate_limit"`        // Лимит сообщений в секунду на клиента (0 - по умолчанию)
+	RateLimitExempt  []string      `yaml:"rate_limit_exempt"` // Сервисы, на которые не действует ограничение скорости
+	CacheSize        int           `yaml:"cache_size"`        // Количество записей в кеше сервера (0 - кеш отключен)
+	CacheTTL         time.Duration `yaml:"cache_ttl"`         // Время жизни записей в кеше (0 - без ограничения)
+	MaxConnections   int           `yaml:"max_connections"`   // Максимум одновременных подключений (0 - по умолчанию)
+	MaxMessageSize   int           `yaml:"max_message_size"`  // Максимальный размер входящих данных в байтах (0 - по умолчанию)
+	FileMode         os.FileMode   `yaml:"file_mode"`         // Права доступа к файлу лога (0 - DEFAULT_FILE_PERMISSIONS)
+	SocketMode       os.FileMode   `yaml:"socket_mode"`       // Права доступа к сокету (0 - DEFAULT_SOCKET_PERMISSIONS)
+	SocketGroup      string        `yaml:"socket_group"`      // Группа сокета: имя или gid (пусто - не менять)
+	DirMode          os.FileMode   `yaml:"dir_mode"`          // Права создаваемых директорий лога и сокета (0 - DEFAULT_DIR_PERMISSIONS)
+	Locale           string        `yaml:"locale"`            // Язык служебных сообщений и ошибок сервера: ru (по умолчанию) или en
+	DisableSelfLog   bool          `yaml:"disable_self_log"`  // Не писать служебные записи сервера (SLOG) в файл лога
+
+	// Режим New: ModeLocal пишет в файл без сокета (пусто - ModeLocal без SocketPath, иначе ModeServer)
+	Mode Mode `yaml:"mode"` // Режим логгера, создаваемого New; Connect и NewLogServer его не учитывают
+
+	// Сокетный файл (true - сокетом управляет внешний процесс, например systemd)
+	KeepSocketFile bool `yaml:"keep_socket_file"` // Не удалять прежний сокетный файл при запуске и сокет при остановке
+
+	// Формат файла лога
+	ServiceNameWidth int `yaml:"service_name_width"` // Ширина колонки сервиса (0 - по самому длинному имени, растет с новыми сервисами)
+
+	// Допустимые имена сервисов на клиенте и сервере
+	AllowedServiceChars string `yaml:"allowed_service_chars"` // Регулярное выражение для имени сервиса в верхнем регистре (пусто - DEFAULT_SERVICE_NAME_PATTERN)
+
+	// Порядок записи пакета (сортировка увеличивает затраты на запись)
+	OrderByTimestamp bool `yaml:"order_by_timestamp"` // Упорядочивать сообщения пакета по времени перед записью
+
+	// Ограничение времени чтения лога по запросу записей (GetLogEntries и HTTP API)
+	QueryTimeout time.Duration `yaml:"query_timeout"` // Максимальное время чтения (0 - DEFAULT_QUERY_TIMEOUT, отрицательное - без ограничения)
+
+	// Квота на объем записи сервиса: при превышении сообщения сервиса отбрасываются до конца окна
+	ServiceByteQuota   int64         `yaml:"service_byte_quota"`   // Байт на сервис за окно ServiceQuotaWindow (0 - без ограничения)
+	ServiceQuotaWindow time.Duration `yaml:"service_quota_window"` // Длительность окна квоты (0 - DEFAULT_SERVICE_QUOTA_WINDOW)
+
+	// Мониторинг ресурсов сервера
+	MonitorInterval      time.Duration `yaml:"monitor_interval"`        // Интервал проверки потребления памяти (0 - DEFAULT_MONITOR_INTERVAL)
+	MaxMemory            int64         `yaml:"max_memory"`              // Порог потребления памяти в байтах (0 - DEFAULT_MAX_MEMORY)
+	StatsInterval        time.Duration `yaml:"stats_interval"`          // Интервал записи статистики в лог (0 - DEFAULT_STATS_INTERVAL, < 0 - не записывать)
+	DisableForcedGC      bool          `yaml:"disable_forced_gc"`       // Не вызывать runtime.GC() при превышении MaxMemory (дорого на слабых CPU)
+	ClearCacheOnPressure bool          `yaml:"clear_cache_on_pressure"` // Очищать кеш записей при превышении MaxMemory
+
+	// Переподключение клиента
+	ReconnectMaxAttempts    int           `yaml:"reconnect_max_attempts"`    // Попыток переподключения клиента (0 - без ограничения)
+	ReconnectInitialBackoff time.Duration `yaml:"reconnect_initial_backoff"` // Начальная задержка между попытками (0 - по умолчанию)
+	ReconnectMaxBackoff     time.Duration `yaml:"reconnect_max_backoff"`     // Максимальная задержка между попытками (0 - по умолчанию)
+	ReconnectInterval       time.Duration `yaml:"reconnect_interval"`        // Интервал фонового переподключения (0 - только при отправке)
+	ClientPoolSize          int           `yaml:"client_pool_size"`          // Количество соединений для отправки сообщений (0 или 1 - одно общее соединение)
+	CompressResponses       bool          `yaml:"compress_responses"`        // Запрашивать gzip сжатие больших ответов сервера (полезно при чтении большого лога)
+	BatchSize               int           `yaml:"batch_size"`                // Сообщений в одном кадре протокола (0 или 1 - каждое сообщение отдельно)
+	BatchInterval           time.Duration `yaml:"batch_interval"`            // Максимальная задержка отправки неполного пакета (0 - DEFAULT_BATCH_INTERVAL_MS)
+
+	// Ограничения безопасности: длины, имена сервисов, лимит скорости и баны
+	// (nil - DefaultSecurityConfig; нулевые поля получают значения по умолчанию,
+	// RateLimit, RateLimitExempt и AllowedServiceChars имеют приоритет)
+	Security *SecurityConfig `yaml:"-"`
+
+	// Источник времени сервера и клиента (nil - SystemClock, в тестах - FakeClock)
+	Clock Clock `yaml:"-"`
+}
+
+// applyBufferDefaults проверяет параметры буфера и ротации
+// Нулевые значения заменяются значениями по умолчанию, отрицательные считаются
+// ошибкой. Вызывается в NewLogServer и Reload, поэтому остальной код сервера
+// полагается на положительные значения без повторных проверок.
+func applyBufferDefaults(config *LoggingConfig) error {
+	if config.BufferSize < 0 {
+		return fmt.Errorf("размер буфера не может быть отрицательным: %d", config.BufferSize)
+	}
+	if config.FlushInterval < 0 {
+		return fmt.Errorf("интервал сброса буфера не может быть отрицательным: %v", config.FlushInterval)
+	}
+	if config.MaxFileSize < 0 {
+		return fmt.Errorf("размер файла лога не может быть отрицательным: %g", config.MaxFileSize)
+	}
+	if config.MaxFiles < 0 {
+		return fmt.Errorf("количество файлов лога не может быть отрицательным: %d", config.MaxFiles)
+	}
+
+	// Нулевой буфер сделал бы канал небуферизованным и изменил бы поведение при переполнении
+	if config.BufferSize == 0 {
+		config.BufferSize = DEFAULT_BUFFER_SIZE
+	}
+	if config.FlushInterval == 0 {
+		config.FlushInterval = time.Duration(DEFAULT_FLUSH_INTERVAL_MS) * time.Millisecond
+	}
+	// Нулевой размер вызывал бы ротацию после каждой записи
+	if config.MaxFileSize == 0 {
+		config.MaxFileSize = DEFAULT_MAX_FILE_SIZE
+	}
+	if config.MaxFiles == 0 {
+		config.MaxFiles = DEFAULT_MAX_FILES
+	}
+	return nil
 }
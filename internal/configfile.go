@@ -0,0 +1,381 @@
+// configfile.go - Загрузка конфигурации из файла и переменных окружения
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CONFIG_ENV_PREFIX префикс переменных окружения, переопределяющих параметры конфигурации
+// Имя переменной - префикс и yaml имя параметра в верхнем регистре, например ZLOGGER_LEVEL.
+const CONFIG_ENV_PREFIX = "ZLOGGER_"
+
+// NewConfig создает конфигурацию с настройками по умолчанию
+func NewConfig(logFile, socketPath string) *LoggingConfig {
+	return &LoggingConfig{
+		Level:            "info",      // Уровень логирования
+		LogFile:          logFile,     // Путь к файлу лога
+		SocketPath:       socketPath,  // Путь к Unix сокету
+		MaxFileSize:      1,           // 1 MB
+		BufferSize:       1000,        // 1000 сообщений
+		FlushInterval:    time.Second, // 1 секунда
+		Services:         []string{},  // Пустой список сервисов
+		RestrictServices: false,       // Не ограничивать сервисы
+		Dir:              "/tmp",      // Путь к директории логов
+		MaxFiles:         3,           // Максимальное количество файлов логов
+		MaxSize:          1,           // Максимальный размер файла логов в MB
+		MaxAge:           7,           // Максимальный возраст файла логов
+		Compress:         true,        // Сжатие файлов логов
+		Console:          true,        // Вывод логов в консоль
+		MaxBackups:       3,           // Максимальное количество резервных копий
+
+		// Кеш записей сервера (CacheSize = 0 отключает кеш)
+		CacheSize: DEFAULT_CACHE_SIZE,
+		CacheTTL:  time.Duration(DEFAULT_CACHE_TTL) * time.Second,
+
+		// Лимиты подключений и размера сообщений
+		MaxConnections: DEFAULT_MAX_CONNECTIONS,
+		MaxMessageSize: DEFAULT_MAX_MESSAGE_SIZE,
+
+		// Переподключение клиента к серверу
+		ReconnectMaxAttempts:    DEFAULT_RECONNECT_ATTEMPTS,
+		ReconnectInitialBackoff: time.Duration(DEFAULT_RECONNECT_BACKOFF_MS) * time.Millisecond,
+		ReconnectMaxBackoff:     time.Duration(DEFAULT_RECONNECT_MAX_BACKOFF) * time.Second,
+	}
+}
+
+// LoadConfig загружает конфигурацию из файла YAML или JSON
+//
+// Параметры, отсутствующие в файле, получают значения NewConfig. Затем
+// применяются переменные окружения с префиксом CONFIG_ENV_PREFIX, и результат
+// проверяется ValidateConfig. Ключи файла - yaml имена полей LoggingConfig
+// (log_file, flush_interval и т.д.), неизвестные ключи считаются ошибкой.
+// При пустом path конфигурация строится только из значений по умолчанию
+// и переменных окружения.
+func LoadConfig(path string) (*LoggingConfig, error) {
+	config := NewConfig("", "")
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
+		}
+
+		values, err := parseConfigData(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора файла конфигурации %s: %w", path, err)
+		}
+
+		for key, value := range values {
+			if err := setConfigValue(config, key, value); err != nil {
+				return nil, fmt.Errorf("ошибка в файле конфигурации %s: %w", path, err)
+			}
+		}
+	}
+
+	if err := applyConfigEnv(config); err != nil {
+		return nil, err
+	}
+
+	if _, err := ParseLevel(config.Level); err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация: %w", err)
+	}
+	if _, err := catalogFor(config.Locale); err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация: %w", err)
+	}
+	if err := ValidateConfig(config); err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация: %w", err)
+	}
+
+	return config, nil
+}
+
+// parseConfigData разбирает содержимое файла конфигурации в значения параметров
+// Формат определяется по расширению (.json, .yaml, .yml), без расширения -
+// по первому символу. Значения - строки или списки строк ([]string).
+func parseConfigData(path string, data []byte) (map[string]interface{}, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parseJSONConfig(data)
+	case ".yaml", ".yml":
+		return parseYAMLConfig(data)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return parseJSONConfig(data)
+	}
+	return parseYAMLConfig(data)
+}
+
+// parseJSONConfig разбирает JSON объект с параметрами конфигурации
+func parseJSONConfig(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			values[key] = v
+		case json.Number, bool:
+			values[key] = fmt.Sprint(v)
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				text, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("параметр %s: элементы списка должны быть строками", key)
+				}
+				items = append(items, text)
+			}
+			values[key] = items
+		default:
+			return nil, fmt.Errorf("параметр %s: вложенные объекты не поддерживаются", key)
+		}
+	}
+	return values, nil
+}
+
+// parseYAMLConfig разбирает плоский YAML с параметрами конфигурации
+// Поддерживается подмножество YAML, достаточное для LoggingConfig: пары
+// "ключ: значение" верхнего уровня, строки в кавычках, комментарии и списки
+// в виде [a, b] или строк "- элемент" под ключом. Внешние библиотеки не
+// используются, чтобы модуль оставался без зависимостей.
+func parseYAMLConfig(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	listKey := "" // Ключ, элементы списка которого идут следующими строками
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNum := i + 1
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		if strings.TrimSpace(line) == "" || line == "---" {
+			continue
+		}
+
+		// Элемент блочного списка
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != line || strings.HasPrefix(line, "- ") {
+			if listKey == "" || !strings.HasPrefix(trimmed, "-") {
+				return nil, fmt.Errorf("строка %d: вложенные параметры не поддерживаются", lineNum)
+			}
+			item, err := parseYAMLScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: %w", lineNum, err)
+			}
+			values[listKey] = append(values[listKey].([]string), item)
+			continue
+		}
+
+		key, rawValue, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("строка %d: ожидается \"ключ: значение\"", lineNum)
+		}
+		key = strings.TrimSpace(key)
+		rawValue = strings.TrimSpace(rawValue)
+		if _, exists := values[key]; exists {
+			return nil, fmt.Errorf("строка %d: параметр %s указан повторно", lineNum, key)
+		}
+
+		listKey = ""
+		switch {
+		case rawValue == "":
+			// Значение - блочный список на следующих строках
+			listKey = key
+			values[key] = []string{}
+		case strings.HasPrefix(rawValue, "["):
+			items, err := parseYAMLFlowList(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: %w", lineNum, err)
+			}
+			values[key] = items
+		default:
+			value, err := parseYAMLScalar(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: %w", lineNum, err)
+			}
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+// stripYAMLComment удаляет комментарий, начинающийся с # вне кавычек
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseYAMLScalar возвращает значение скаляра YAML, снимая кавычки
+func parseYAMLScalar(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("неверная строка в кавычках: %s", value)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("неверная строка в кавычках: %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+// parseYAMLFlowList разбирает список вида [a, "b", c]
+func parseYAMLFlowList(value string) ([]string, error) {
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("список не закрыт: %s", value)
+	}
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	items := []string{}
+	if inner == "" {
+		return items, nil
+	}
+	for _, part := range strings.Split(inner, ",") {
+		item, err := parseYAMLScalar(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// applyConfigEnv переопределяет параметры конфигурации переменными окружения
+// Пустые переменные игнорируются, списки задаются через запятую.
+func applyConfigEnv(config *LoggingConfig) error {
+	configType := reflect.TypeOf(*config)
+	for i := 0; i < configType.NumField(); i++ {
+		key := configType.Field(i).Tag.Get("yaml")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		name := CONFIG_ENV_PREFIX + strings.ToUpper(key)
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		if err := setConfigValue(config, key, value); err != nil {
+			return fmt.Errorf("ошибка в переменной окружения %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setConfigValue устанавливает параметр конфигурации по его yaml имени
+// value - строка или список строк. Длительности задаются в формате
+// time.ParseDuration ("500ms", "1m"), права доступа - в восьмеричной записи ("0640").
+func setConfigValue(config *LoggingConfig, key string, value interface{}) error {
+	field, ok := configFieldByTag(config, key)
+	if !ok {
+		return fmt.Errorf("неизвестный параметр конфигурации: %s", key)
+	}
+
+	if field.Kind() == reflect.Slice {
+		switch v := value.(type) {
+		case []string:
+			field.Set(reflect.ValueOf(append([]string(nil), v...)))
+		case string:
+			var items []string
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		}
+		return nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("параметр %s: ожидается одно значение, а не список", key)
+	}
+
+	switch field.Type() {
+	case reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("параметр %s: неверная длительность %q (пример: 500ms, 1m)", key, text)
+		}
+		field.SetInt(int64(d))
+		return nil
+	case reflect.TypeOf(os.FileMode(0)):
+		mode, err := strconv.ParseUint(text, 8, 32)
+		if err != nil {
+			return fmt.Errorf("параметр %s: неверные права доступа %q (ожидается восьмеричная запись, например 0640)", key, text)
+		}
+		field.SetUint(mode)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("параметр %s: ожидается true или false, получено %q", key, text)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("параметр %s: ожидается целое число, получено %q", key, text)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("параметр %s: ожидается число, получено %q", key, text)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("параметр %s: неподдерживаемый тип %s", key, field.Type())
+	}
+	return nil
+}
+
+// configFieldByTag возвращает поле конфигурации по его yaml имени
+func configFieldByTag(config *LoggingConfig, key string) (reflect.Value, bool) {
+	if key == "-" {
+		return reflect.Value{}, false
+	}
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("yaml") == key {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,153 @@
+// configfile_test.go - Тесты для загрузки конфигурации из файла
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile записывает файл конфигурации во временную директорию
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("не удалось записать файл конфигурации: %v", err)
+	}
+	return path
+}
+
+// TestLoadConfigYAML проверяет разбор YAML и значения по умолчанию
+func TestLoadConfigYAML(t *testing.T) {
+	path := writeConfigFile(t, "zlogger.yaml", `
+# Конфигурация сервера
+level: debug
+log_file: "/var/log/app.log"   # файл лога
+socket_path: /run/zlogger.sock
+max_file_size: 2.5
+flush_interval: 500ms
+file_mode: "0640"
+restrict_services: true
+services: [API, "DB"]
+rate_limit_exempt:
+  - HEALTH
+  - 'METRICS'
+`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("ошибка загрузки конфигурации: %v", err)
+	}
+
+	if config.Level != "debug" || config.LogFile != "/var/log/app.log" || config.SocketPath != "/run/zlogger.sock" {
+		t.Errorf("неверные строковые параметры: %+v", config)
+	}
+	if config.MaxFileSize != 2.5 || config.FlushInterval != 500*time.Millisecond || config.FileMode != 0640 {
+		t.Errorf("неверные числовые параметры: size=%v flush=%v mode=%o", config.MaxFileSize, config.FlushInterval, config.FileMode)
+	}
+	if !config.RestrictServices {
+		t.Error("restrict_services должен быть true")
+	}
+	if !reflect.DeepEqual(config.Services, []string{"API", "DB"}) {
+		t.Errorf("неверный список сервисов: %v", config.Services)
+	}
+	if !reflect.DeepEqual(config.RateLimitExempt, []string{"HEALTH", "METRICS"}) {
+		t.Errorf("неверный список исключений: %v", config.RateLimitExempt)
+	}
+
+	// Параметры, отсутствующие в файле, получают значения по умолчанию
+	if config.BufferSize != 1000 || config.CacheSize != DEFAULT_CACHE_SIZE {
+		t.Errorf("ожидались значения по умолчанию: buffer=%d cache=%d", config.BufferSize, config.CacheSize)
+	}
+}
+
+// TestLoadConfigJSON проверяет разбор JSON с теми же ключами
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfigFile(t, "zlogger.json", `{
+		"level": "warn",
+		"log_file": "/var/log/app.log",
+		"socket_path": "/run/zlogger.sock",
+		"buffer_size": 500,
+		"compress_responses": true,
+		"services": ["API"]
+	}`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("ошибка загрузки конфигурации: %v", err)
+	}
+	if config.Level != "warn" || config.BufferSize != 500 || !config.CompressResponses {
+		t.Errorf("неверные параметры: %+v", config)
+	}
+	if !reflect.DeepEqual(config.Services, []string{"API"}) {
+		t.Errorf("неверный список сервисов: %v", config.Services)
+	}
+}
+
+// TestLoadConfigEnv проверяет переопределение параметров переменными окружения
+func TestLoadConfigEnv(t *testing.T) {
+	path := writeConfigFile(t, "zlogger.yml", "level: info\nlog_file: /var/log/app.log\nsocket_path: /run/zlogger.sock\n")
+
+	t.Setenv("ZLOGGER_LEVEL", "error")
+	t.Setenv("ZLOGGER_SERVICES", "API, DB")
+	t.Setenv("ZLOGGER_CACHE_TTL", "2m")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("ошибка загрузки конфигурации: %v", err)
+	}
+	if config.Level != "error" {
+		t.Errorf("переменная окружения должна переопределять файл, уровень: %s", config.Level)
+	}
+	if !reflect.DeepEqual(config.Services, []string{"API", "DB"}) || config.CacheTTL != 2*time.Minute {
+		t.Errorf("неверные параметры из окружения: services=%v ttl=%v", config.Services, config.CacheTTL)
+	}
+
+	// Без файла конфигурация строится из значений по умолчанию и окружения
+	t.Setenv("ZLOGGER_LOG_FILE", "/var/log/env.log")
+	t.Setenv("ZLOGGER_SOCKET_PATH", "/run/env.sock")
+	config, err = LoadConfig("")
+	if err != nil {
+		t.Fatalf("ошибка загрузки конфигурации из окружения: %v", err)
+	}
+	if config.LogFile != "/var/log/env.log" || config.SocketPath != "/run/env.sock" {
+		t.Errorf("неверные пути из окружения: %s %s", config.LogFile, config.SocketPath)
+	}
+}
+
+// TestLoadConfigErrors проверяет сообщения об ошибках в файле конфигурации
+func TestLoadConfigErrors(t *testing.T) {
+	const paths = "log_file: /var/log/app.log\nsocket_path: /run/zlogger.sock\n"
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"неизвестный параметр", "c.yaml", paths + "unknown: 1\n", "неизвестный параметр конфигурации: unknown"},
+		{"неверная длительность", "c.yaml", paths + "flush_interval: быстро\n", "flush_interval"},
+		{"неверное число", "c.yaml", paths + "buffer_size: много\n", "buffer_size"},
+		{"вложенный параметр", "c.yaml", paths + "limits:\n  max: 1\n", "строка 4"},
+		{"неверный уровень", "c.yaml", paths + "level: verbose\n", "некорректная конфигурация"},
+		{"относительный путь", "c.yaml", "log_file: app.log\nsocket_path: /run/zlogger.sock\n", "абсолютным"},
+		{"вложенный объект JSON", "c.json", `{"log_file": {"path": "/var/log/app.log"}}`, "вложенные объекты"},
+		{"неверный JSON", "c.json", `{"level": `, "ошибка разбора"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfigFile(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ожидалась ошибка с %q, получено: %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего файла")
+	}
+}
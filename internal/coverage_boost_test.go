@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -89,7 +90,7 @@ func TestLogServerHelperMethodsBoost(t *testing.T) {
 
 	// Создаем минимальную структуру сервера для тестирования методов
 	server := &LogServer{
-		file:        file,
+		logFile:     FileSink{file: file},
 		currentSize: 0,
 		stats:       ServerStats{StartTime: time.Now()},
 		config: &LoggingConfig{
@@ -158,7 +159,7 @@ func TestLogServerStatisticsBoost(t *testing.T) {
 
 	// Создаем сервер с базовой статистикой
 	server := &LogServer{
-		file:        file,
+		logFile:     FileSink{file: file},
 		currentSize: 0,
 		stats: ServerStats{
 			StartTime:     time.Now(),
@@ -230,7 +231,7 @@ func TestLogServerMessageProcessingBoost(t *testing.T) {
 	defer file.Close()
 
 	server := &LogServer{
-		file:        file,
+		logFile:     FileSink{file: file},
 		currentSize: 0,
 		stats:       ServerStats{StartTime: time.Now()},
 		config: &LoggingConfig{
@@ -298,7 +299,7 @@ func TestLogServerBufferHandling(t *testing.T) {
 
 	// Создаем сервер с базовой конфигурацией
 	server := &LogServer{
-		file:        file,
+		logFile:     FileSink{file: file},
 		currentSize: 0,
 		stats:       ServerStats{StartTime: time.Now()},
 		config: &LoggingConfig{
@@ -372,10 +373,11 @@ func TestLogServerEdgeCases(t *testing.T) {
 	defer file.Close()
 
 	server := &LogServer{
-		file:        file,
+		logFile:     FileSink{file: file},
 		currentSize: 0,
 		stats:       ServerStats{StartTime: time.Now()},
 		config: &LoggingConfig{
+			LogFile:     logFile,
 			MaxFileSize: 1024 * 1024, // 1MB
 			MaxFiles:    3,
 		},
@@ -415,8 +417,38 @@ func TestLogServerEdgeCases(t *testing.T) {
 
 	server.writeMessage(specialMsg)
 
-	if server.stats.TotalMessages != 3 {
-		t.Errorf("ожидалось 3 сообщения, получено %d", server.stats.TotalMessages)
+	// Тестируем многострочные значения полей (например, стек вызовов)
+	fieldsMsg := LogMessage{
+		Level:     ERROR,
+		Message:   "паника в обработчике",
+		Service:   "EDGE_TEST",
+		Timestamp: time.Now(),
+		ClientID:  "edge-client",
+		Fields:    map[string]string{"stack": "main.go:10\nhandler.go:20", "code": "500"},
+	}
+
+	server.writeMessage(fieldsMsg)
+
+	if server.stats.TotalMessages != 4 {
+		t.Errorf("ожидалось 4 сообщения, получено %d", server.stats.TotalMessages)
+	}
+
+	// Переводы строк не должны разрывать записи: все сообщения читаются обратно без искажений
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{Service: "EDGE_TEST"})
+	if err != nil {
+		t.Fatalf("ошибка чтения записей: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("ожидалось 4 записи, получено %d", len(entries))
+	}
+	if entries[0].Message != "" || entries[1].Message != longMessage {
+		t.Error("пустое или длинное сообщение искажено при чтении")
+	}
+	if entries[2].Message != specialMsg.Message {
+		t.Errorf("сообщение со спецсимволами искажено: %q", entries[2].Message)
+	}
+	if entries[3].Fields["stack"] != fieldsMsg.Fields["stack"] || entries[3].Fields["code"] != "500" {
+		t.Errorf("многострочное поле искажено: %v", entries[3].Fields)
 	}
 
 	// Тестируем parseLogEntry с невалидными данными
@@ -25,6 +25,9 @@ func createTestConfigForCoverage(t *testing.T, socketPath string) (*LoggingConfi
 		LogFile:    tempFile.Name(),
 		SocketPath: socketPath,
 		Services:   []string{"MAIN", "API", "TEST"},
+
+		// Ноль означает переподключение без ограничения, тестам нужно ограничение
+		ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS,
 	}
 
 	cleanup := func() {
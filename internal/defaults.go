@@ -6,10 +6,35 @@ const (
 	DEFAULT_TIME_FORMAT = "02-01-2006 15:04:05" // Фиксированный формат времени
 
 	// Производительность
-	DEFAULT_WRITE_BATCH_SIZE   = 50   // Оптимальный размер пакета для flash
-	DEFAULT_MAX_CONNECTIONS    = 10   // Ограничение для embedded CPU (уменьшено с 20)
-	DEFAULT_MAX_MESSAGE_SIZE   = 2048 // 2KB максимум на сообщение (уменьшено с 4KB)
-	DEFAULT_CONNECTION_TIMEOUT = 30   // 30 секунд таймаут
+	DEFAULT_WRITE_BATCH_SIZE   = 50    // Оптимальный размер пакета для flash
+	DEFAULT_LINE_LENGTH        = 100   // Начальная оценка длины строки файла лога в байтах
+	DEFAULT_MAX_CONNECTIONS    = 20    // Ограничение для embedded CPU: 10 клиентов, каждому нужно соединение запросов
+	DEFAULT_MAX_MESSAGE_SIZE   = 2048  // 2KB максимум на сообщение (уменьшено с 4KB)
+	DEFAULT_CONNECTION_TIMEOUT = 30    // 30 секунд таймаут
+	DEFAULT_MAX_QUERY_LIMIT    = 10000 // Максимальное количество записей в одном запросе
+	DEFAULT_QUERY_TIMEOUT      = 30    // Максимальное время чтения лога по запросу записей в секундах
+	DEFAULT_QUERY_CHECK_LINES  = 256   // Через сколько прочитанных записей проверяется время запроса
+	DEFAULT_STREAM_CHUNK_SIZE  = 100   // Количество записей в одной части потокового ответа
+	DEFAULT_CAPACITY_BACKOFF   = 5     // Пауза перед новым подключением к перегруженному серверу в секундах
+
+	// Буфер и ротация (нулевые значения в конфигурации заменяются этими)
+	DEFAULT_BUFFER_SIZE       = 1000  // Емкость буфера сообщений
+	DEFAULT_FLUSH_INTERVAL_MS = 1000  // Интервал сброса буфера на диск в миллисекундах
+	DEFAULT_MAX_FILE_SIZE     = 1     // Размер файла лога для ротации в MB
+	DEFAULT_MAX_FILES         = 3     // Количество файлов лога вместе с текущим
+	DEFAULT_SEQ_RECOVERY_TAIL = 65536 // Байт с конца файла лога, в которых ищется последний порядковый номер
+	DEFAULT_SINK_QUEUE_SIZE   = 1000  // Емкость очереди записей каждого дополнительного приемника
+
+	// Протокол
+	PROTOCOL_VERSION             = 1    // Версия протокола, увеличивается при несовместимых изменениях
+	DEFAULT_HANDSHAKE_TIMEOUT_MS = 200  // Ожидание ответа на приветствие при подключении в миллисекундах
+	DEFAULT_COMPRESS_THRESHOLD   = 8192 // Минимальный размер ответа для сжатия в байтах
+
+	// Переподключение клиента
+	DEFAULT_RECONNECT_ATTEMPTS    = 5   // Количество попыток переподключения
+	DEFAULT_RECONNECT_BACKOFF_MS  = 100 // Начальная задержка между попытками в миллисекундах
+	DEFAULT_RECONNECT_MAX_BACKOFF = 10  // Максимальная задержка между попытками в секундах
+	DEFAULT_BATCH_INTERVAL_MS     = 5   // Максимальная задержка отправки неполного пакета сообщений в миллисекундах
 
 	// Кеширование
 	DEFAULT_CACHE_SIZE = 100    // 100 записей в кеше (уменьшено с 500)
@@ -18,8 +43,23 @@ const (
 	// Безопасность
 	DEFAULT_FILE_PERMISSIONS   = 0644 // Стандартные права для файлов
 	DEFAULT_SOCKET_PERMISSIONS = 0666 // Стандартные права для сокетов
+	DEFAULT_DIR_PERMISSIONS    = 0755 // Стандартные права для создаваемых директорий
 	DEFAULT_RATE_LIMIT         = 50   // 50 сообщений в секунду (уменьшено со 100)
+	DEFAULT_TOP_OFFENDERS      = 5    // Количество нарушителей лимита в статистике
+	DEFAULT_REJECTED_WARNING   = 60   // Интервал предупреждений о неизвестных сервисах в секундах
+
+	// Ответы на запросы записей
+	DEFAULT_MAX_RESPONSE_SIZE = 4 * 1024 * 1024 // Максимальный объем записей в ответе GetLogEntries в байтах
+	DEFAULT_ENTRY_OVERHEAD    = 64              // Оценка памяти на запись сверх ее строк в байтах
+
+	// Квота на объем записи сервиса
+	DEFAULT_SERVICE_QUOTA_WINDOW = 3600 // Окно квоты в секундах (ServiceQuotaWindow = 0)
+
+	// Имена сервисов (LoggingConfig.AllowedServiceChars)
+	DEFAULT_SERVICE_NAME_PATTERN = `^[A-Z0-9_-]+$` // Заглавные латинские буквы, цифры, _ и -
 
 	// Ресурсы
-	DEFAULT_MAX_MEMORY = 50 * 1024 * 1024 // 50MB лимит памяти
+	DEFAULT_MAX_MEMORY       = 50 * 1024 * 1024 // 50MB лимит памяти
+	DEFAULT_MONITOR_INTERVAL = 60               // Интервал проверки потребления памяти в секундах
+	DEFAULT_STATS_INTERVAL   = 600              // Интервал записи статистики сервера в лог в секундах
 )
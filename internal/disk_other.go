@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd)
+
+// disk_other.go - Заглушка определения свободного места для остальных систем
+package logger
+
+// freeDiskSpace на этих системах не поддерживается
+func freeDiskSpace(path string) (int64, bool) {
+	return 0, false
+}
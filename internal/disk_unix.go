@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+// disk_unix.go - Определение свободного места на диске для unix систем
+package logger
+
+import "syscall"
+
+// freeDiskSpace возвращает количество байт, доступных для записи в файловой системе пути
+func freeDiskSpace(path string) (int64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, false
+	}
+	return int64(uint64(stat.Bavail) * uint64(stat.Bsize)), true
+}
@@ -99,7 +99,7 @@ func TestEmbeddedResourceLimits(t *testing.T) {
 		value int
 		max   int
 	}{
-		{"MAX_CONNECTIONS", DEFAULT_MAX_CONNECTIONS, 10},
+		{"MAX_CONNECTIONS", DEFAULT_MAX_CONNECTIONS, 20},
 		{"MAX_MESSAGE_SIZE", DEFAULT_MAX_MESSAGE_SIZE, 2048}, // 2KB для embedded
 		{"CACHE_SIZE", DEFAULT_CACHE_SIZE, 100},
 		{"RATE_LIMIT", DEFAULT_RATE_LIMIT, 50}, // 50 msg/sec для embedded
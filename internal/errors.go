@@ -0,0 +1,116 @@
+// errors.go - Ошибки клиента, которые можно проверить через errors.Is
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// Ошибки подключения к серверу логгера
+// Возвращаются обернутыми через %w, что позволяет приложению решить,
+// повторить попытку, подождать или перейти в деградированный режим.
+var (
+	ErrServerUnavailable = errors.New("сервер логгера недоступен")         // Сокет отсутствует или сервер не отвечает
+	ErrServerAtCapacity  = errors.New(serverAtCapacityMessage)             // Сервер отклонил подключение из-за лимита
+	ErrSocketPermission  = errors.New("нет прав доступа к сокету логгера") // Недостаточно прав для подключения к сокету
+)
+
+// ErrUnsupportedMessage возвращается, если сервер по итогам приветствия
+// не поддерживает тип запроса (например, сервер старее клиента)
+var ErrUnsupportedMessage = errors.New("запрос не поддерживается сервером")
+
+// ErrClosed возвращается при отправке сообщений и запросов через закрытый клиент
+var ErrClosed = errors.New("клиент логгера закрыт")
+
+// classifyDialError сопоставляет ошибку подключения с ошибкой клиента
+func classifyDialError(err error) error {
+	if errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM) {
+		return ErrSocketPermission
+	}
+	return ErrServerUnavailable
+}
+
+// ErrorCode код ошибки в ответе сервера (ProtocolMessage.Code)
+// Позволяет отличать причины ошибок без разбора текста, который может меняться.
+type ErrorCode string
+
+// Коды ошибок сервера
+const (
+	ErrorCodeInvalidRequest ErrorCode = "invalid_request"   // Неверный формат или параметры запроса
+	ErrorCodeNotFound       ErrorCode = "not_found"         // Файл лога не найден
+	ErrorCodePermission     ErrorCode = "permission_denied" // Нет прав доступа к файлу лога
+	ErrorCodeRateLimited    ErrorCode = "rate_limited"      // Превышен лимит скорости сообщений
+	ErrorCodeUnsupported    ErrorCode = "unsupported"       // Тип сообщения не поддерживается сервером
+	ErrorCodeAtCapacity     ErrorCode = "at_capacity"       // Достигнут лимит подключений
+	ErrorCodeTimeout        ErrorCode = "timeout"           // Истекло время ожидания на сервере
+	ErrorCodeInternal       ErrorCode = "internal"          // Прочие ошибки сервера
+)
+
+// Ошибки запросов, соответствующие кодам ErrorCode
+// Возвращаются обернутыми в ServerError и проверяются через errors.Is.
+var (
+	ErrInvalidRequest = errors.New("неверный запрос к серверу логгера") // ErrorCodeInvalidRequest
+	ErrLogNotFound    = errors.New("файл лога не найден")               // ErrorCodeNotFound
+	ErrLogPermission  = errors.New("нет прав доступа к файлу лога")     // ErrorCodePermission
+	ErrRateLimited    = errors.New("превышен лимит скорости сообщений") // ErrorCodeRateLimited
+	ErrServerTimeout  = errors.New("истекло время ожидания на сервере") // ErrorCodeTimeout
+	ErrServerInternal = errors.New("внутренняя ошибка сервера логгера") // ErrorCodeInternal
+)
+
+// errResponseTooLarge результат запроса записей не помещается в один ответ
+var errResponseTooLarge = errors.New("слишком большой ответ")
+
+// errorCodeErrors сопоставляет коды ошибок сервера с ошибками клиента
+var errorCodeErrors = map[ErrorCode]error{
+	ErrorCodeInvalidRequest: ErrInvalidRequest,
+	ErrorCodeNotFound:       ErrLogNotFound,
+	ErrorCodePermission:     ErrLogPermission,
+	ErrorCodeRateLimited:    ErrRateLimited,
+	ErrorCodeUnsupported:    ErrUnsupportedMessage,
+	ErrorCodeAtCapacity:     ErrServerAtCapacity,
+	ErrorCodeTimeout:        ErrServerTimeout,
+	ErrorCodeInternal:       ErrServerInternal,
+}
+
+// ServerError ошибка, которую сервер вернул в ответ на запрос
+// errors.Is сопоставляет ее с ошибкой, соответствующей коду (например,
+// ErrLogNotFound). Серверы старых версий не передают код, и тогда Code пуст.
+type ServerError struct {
+	Code    ErrorCode // Код ошибки (пусто у серверов без кодов ошибок)
+	Message string    // Текст ошибки от сервера
+}
+
+// Error возвращает текст ошибки сервера
+func (e *ServerError) Error() string {
+	return "ошибка сервера: " + e.Message
+}
+
+// Unwrap возвращает ошибку клиента, соответствующую коду (nil для неизвестного кода)
+func (e *ServerError) Unwrap() error {
+	return errorCodeErrors[e.Code]
+}
+
+// newServerError создает ошибку из ответа сервера MsgTypeError
+func newServerError(response *ProtocolMessage) error {
+	return &ServerError{Code: response.Code, Message: fmt.Sprint(response.Data)}
+}
+
+// errorCodeFor подбирает код ошибки сервера по ошибке чтения лога
+func errorCodeFor(err error) ErrorCode {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrorCodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrorCodePermission
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeTimeout
+	case errors.Is(err, errResponseTooLarge):
+		return ErrorCodeInvalidRequest
+	default:
+		return ErrorCodeInternal
+	}
+}
@@ -0,0 +1,111 @@
+// errors_test.go - Тесты для ошибок подключения клиента и ошибок сервера
+package logger
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestConnectSentinelErrors проверяет, что ошибки подключения различимы через errors.Is
+func TestConnectSentinelErrors(t *testing.T) {
+	// Отсутствующий сокет
+	client := &LogClient{config: &LoggingConfig{
+		SocketPath: filepath.Join(t.TempDir(), "missing.sock"),
+	}}
+	err := client.connect()
+	if !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("ожидалась ErrServerUnavailable, получено: %v", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("исходная ошибка подключения должна сохраняться в цепочке")
+	}
+
+	// Нет прав доступа к сокету
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: os.NewSyscallError("connect", syscall.EACCES)}
+	}
+
+	client = &LogClient{config: &LoggingConfig{SocketPath: "/tmp/logger.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}}
+	err = client.connect()
+	if !errors.Is(err, ErrSocketPermission) {
+		t.Errorf("ожидалась ErrSocketPermission, получено: %v", err)
+	}
+	if errors.Is(err, ErrServerUnavailable) {
+		t.Error("ошибка прав доступа не должна считаться недоступностью сервера")
+	}
+}
+
+// TestReconnectKeepsCause проверяет, что reconnect сохраняет причину последней ошибки
+func TestReconnectKeepsCause(t *testing.T) {
+	origDialTimeout := netDialTimeout
+	defer func() { netDialTimeout = origDialTimeout }()
+	netDialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+
+	client := &LogClient{config: &LoggingConfig{SocketPath: "/tmp/logger.sock", ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS}}
+	if err := reconnectLocked(client); !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("ожидалась ErrServerUnavailable после неудачных попыток, получено: %v", err)
+	}
+}
+
+// TestServerErrorCodes проверяет, что ошибки сервера различимы через errors.Is
+func TestServerErrorCodes(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.CacheSize = 0
+	_, client := startTestServerWithClient(t, config)
+
+	// Неверный формат фильтра
+	response, err := client.sendRequest(MsgTypeGetEntries, "не фильтр")
+	if err != nil {
+		t.Fatalf("ошибка запроса: %v", err)
+	}
+	if response.Type != MsgTypeError {
+		t.Fatalf("ожидалась ошибка, получено %s", response.Type)
+	}
+	err = newServerError(response)
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("ожидалась ErrInvalidRequest, получено: %v", err)
+	}
+
+	// Файл лога удален
+	if err := os.Remove(config.LogFile); err != nil {
+		t.Fatalf("не удалось удалить файл лога: %v", err)
+	}
+	_, err = client.GetLogEntries(FilterOptions{})
+	if !errors.Is(err, ErrLogNotFound) {
+		t.Errorf("ожидалась ErrLogNotFound, получено: %v", err)
+	}
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Code != ErrorCodeNotFound {
+		t.Errorf("ожидалась ServerError с кодом %s, получено: %#v", ErrorCodeNotFound, err)
+	}
+	if errors.Is(err, ErrLogPermission) {
+		t.Error("отсутствие файла не должно считаться ошибкой прав доступа")
+	}
+}
+
+// TestServerErrorWithoutCode проверяет ошибки серверов, не передающих код
+func TestServerErrorWithoutCode(t *testing.T) {
+	err := newServerError(&ProtocolMessage{Type: MsgTypeError, Data: "старая ошибка"})
+	if err.Error() != "ошибка сервера: старая ошибка" {
+		t.Errorf("неожиданный текст ошибки: %q", err.Error())
+	}
+	if errors.Unwrap(err) != nil {
+		t.Error("ошибка без кода не должна сопоставляться с ошибками клиента")
+	}
+
+	if got := errorCodeFor(os.ErrPermission); got != ErrorCodePermission {
+		t.Errorf("ожидался код %s, получено %s", ErrorCodePermission, got)
+	}
+	if got := errorCodeFor(errors.New("другая ошибка")); got != ErrorCodeInternal {
+		t.Errorf("ожидался код %s, получено %s", ErrorCodeInternal, got)
+	}
+}
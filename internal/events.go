@@ -0,0 +1,73 @@
+// events.go - Служебные события сервера в файле лога
+package logger
+
+import (
+	"maps"
+	"strconv"
+)
+
+// EVENT_FIELD имя поля служебной записи сервера (сервис SERVER_LOGGER_NAME) с типом события
+const EVENT_FIELD = "event"
+
+// Типы служебных событий сервера (значения поля EVENT_FIELD)
+const (
+	EventServerStart      = "server_start"      // Сервер запущен
+	EventServerStop       = "server_stop"       // Сервер останавливается
+	EventLevelChange      = "level_change"      // Изменен общий уровень или уровень сервиса
+	EventConfigReload     = "config_reload"     // Конфигурация перезагружена
+	EventRotation         = "rotation"          // Файл лога ротирован
+	EventStats            = "stats"             // Периодическая статистика сервера
+	EventRejectedServices = "rejected_services" // Отброшены сообщения сервисов вне списка разрешенных
+	EventClientBanned     = "client_banned"     // Клиент заблокирован за превышение лимита скорости
+	EventClientUnbanned   = "client_unbanned"   // Бан клиента истек
+	EventServiceAdded     = "service_added"     // Сервис зарегистрирован во время работы
+)
+
+// newServerEvent создает служебную запись сервера с типом события в поле EVENT_FIELD
+// Текст сообщения остается человекочитаемым, а данные события передаются полями,
+// поэтому события можно отбирать по FieldMatch, не разбирая текст.
+func (s *LogServer) newServerEvent(event string, level LogLevel, message string, fields map[string]string) LogMessage {
+	eventFields := make(map[string]string, len(fields)+1)
+	maps.Copy(eventFields, fields)
+	eventFields[EVENT_FIELD] = event
+
+	return LogMessage{
+		Service:   SERVER_LOGGER_NAME,
+		Level:     level,
+		Message:   message,
+		Timestamp: s.now(),
+		ClientID:  "server",
+		Fields:    eventFields,
+	}
+}
+
+// logServerEvent ставит служебную запись в буфер, а если он полон - записывает напрямую
+// При LoggingConfig.DisableSelfLog запись не создается.
+// Нельзя вызывать под s.mu: прямая запись захватывает его.
+func (s *LogServer) logServerEvent(event string, level LogLevel, message string, fields map[string]string) {
+	if !s.selfLog.Load() {
+		return
+	}
+	msg := s.newServerEvent(event, level, message, fields)
+	select {
+	case s.buffer <- &msg:
+	default:
+		s.writeMessage(msg)
+	}
+}
+
+// logBanEvent записывает бан клиента или окончание бана
+// Одна запись на каждое изменение вместо ошибки на каждое отклоненное сообщение
+// позволяет видеть повторяющихся нарушителей по полям client и ban_level.
+func (s *LogServer) logBanEvent(event BanEvent) {
+	fields := map[string]string{
+		"client":    event.ClientID,
+		"ban_level": strconv.Itoa(event.BanLevel),
+	}
+	if !event.Banned {
+		s.logServerEvent(EventClientUnbanned, INFO, s.text(msgClientUnbanned, event.ClientID), fields)
+		return
+	}
+	fields["duration"] = event.Duration.String()
+	s.logServerEvent(EventClientBanned, WARN, s.text(msgClientBanned, event.ClientID, event.Duration, event.BanLevel), fields)
+}
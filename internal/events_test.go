@@ -0,0 +1,173 @@
+// events_test.go - Тесты служебных событий сервера
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+// TestServerEvents проверяет, что служебные записи сервера содержат тип события
+// и отбираются по полю EVENT_FIELD
+func TestServerEvents(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.CacheSize = 0
+	server, client := startTestServerWithClient(t, config)
+
+	waitForLogContent(t, server, EVENT_FIELD+": "+EventServerStart)
+
+	if err := client.SetServerLevel(WARN); err != nil {
+		t.Fatalf("ошибка изменения уровня: %v", err)
+	}
+	waitForLogContent(t, server, EVENT_FIELD+": "+EventLevelChange)
+
+	server.writeMessage(LogMessage{Service: "API", Level: ERROR, Message: "ошибка приложения"})
+
+	entries, err := client.GetLogEntries(FilterOptions{
+		Service:    SERVER_LOGGER_NAME,
+		FieldMatch: map[string]string{EVENT_FIELD: EventLevelChange},
+	})
+	if err != nil {
+		t.Fatalf("ошибка получения записей: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ожидалось одно событие изменения уровня, получено %d", len(entries))
+	}
+	if entries[0].Fields["level"] != "WARN" {
+		t.Errorf("ожидалось поле level=WARN, получено %v", entries[0].Fields)
+	}
+
+	// Исключение сервиса SLOG оставляет только записи приложения
+	entries, err = client.GetLogEntries(FilterOptions{ExcludeServices: []string{SERVER_LOGGER_NAME}})
+	if err != nil {
+		t.Fatalf("ошибка получения записей: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Service != "API" {
+		t.Errorf("ожидалась только запись сервиса API, получено %+v", entries)
+	}
+}
+
+// TestRotationEvent проверяет событие ротации файла лога
+func TestRotationEvent(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.MaxFiles = 1
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	if err := server.rotateIfNeeded(); err != nil {
+		t.Fatalf("ошибка при ротации: %v", err)
+	}
+
+	select {
+	case msg := <-server.buffer:
+		if msg.Service != SERVER_LOGGER_NAME || msg.Fields[EVENT_FIELD] != EventRotation {
+			t.Errorf("ожидалось событие ротации, получено %+v", msg)
+		}
+		if msg.Fields["rotations"] != "1" {
+			t.Errorf("ожидалось поле rotations=1, получено %v", msg.Fields)
+		}
+	default:
+		t.Fatal("событие ротации не поставлено в буфер")
+	}
+}
+
+// TestStatsEvent проверяет тип события у записи статистики
+func TestStatsEvent(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+
+	server.logStatsAsJSON()
+
+	select {
+	case msg := <-server.buffer:
+		if msg.Fields[EVENT_FIELD] != EventStats {
+			t.Errorf("ожидалось событие статистики, получено %+v", msg)
+		}
+	default:
+		t.Fatal("запись статистики не поставлена в буфер")
+	}
+}
+
+// TestSelfLogDisabled проверяет, что с DisableSelfLog сервер не пишет служебные записи
+func TestSelfLogDisabled(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.DisableSelfLog = true
+	config.MaxFiles = 1
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	server.logServerEvent(EventServerStart, INFO, server.text(msgServerStarted), nil)
+	server.logStatsAsJSON()
+	if err := server.rotateIfNeeded(); err != nil {
+		t.Fatalf("ошибка при ротации: %v", err)
+	}
+
+	if len(server.buffer) != 0 {
+		t.Errorf("служебные записи не должны попадать в буфер, в буфере %d", len(server.buffer))
+	}
+	if rotations := server.Stats().FileRotations; rotations != 1 {
+		t.Errorf("статистика должна учитываться без служебных записей, ротаций %d", rotations)
+	}
+
+	// Перезагрузка конфигурации включает служебные записи
+	reloaded := *config
+	reloaded.DisableSelfLog = false
+	if err := server.Reload(&reloaded); err != nil {
+		t.Fatalf("ошибка перезагрузки конфигурации: %v", err)
+	}
+	if len(server.buffer) != 1 {
+		t.Errorf("ожидалась запись о перезагрузке конфигурации, в буфере %d", len(server.buffer))
+	}
+}
+
+// TestBanEvents проверяет служебные записи о бане клиента и его окончании
+func TestBanEvents(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.RateLimit = 1
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+
+	clock := NewFakeClock(time.Now())
+	server.rateLimiter.clock = clock
+
+	server.rateLimiter.IsAllowed("client_1")
+	for i := 0; i < 3; i++ {
+		server.rateLimiter.IsAllowed("client_1")
+	}
+
+	if len(server.buffer) != 1 {
+		t.Fatalf("ожидалась одна запись о бане, в буфере %d", len(server.buffer))
+	}
+	msg := <-server.buffer
+	if msg.Fields[EVENT_FIELD] != EventClientBanned || msg.Level != WARN {
+		t.Errorf("ожидалось событие бана уровня WARN, получено %+v", msg)
+	}
+	if msg.Fields["client"] != "client_1" || msg.Fields["ban_level"] != "1" || msg.Fields["duration"] != "5m0s" {
+		t.Errorf("неожиданные поля события бана: %v", msg.Fields)
+	}
+
+	clock.Advance(DefaultSecurityConfig().BanDuration)
+	server.rateLimiter.IsAllowed("client_1")
+	msg = <-server.buffer
+	if msg.Fields[EVENT_FIELD] != EventClientUnbanned || msg.Fields["client"] != "client_1" {
+		t.Errorf("ожидалось событие окончания бана, получено %+v", msg)
+	}
+}
@@ -0,0 +1,164 @@
+// export.go - Выгрузка записей лога в CSV и NDJSON
+package logger
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// CSVFieldsMode способ выгрузки дополнительных полей записей в CSV
+type CSVFieldsMode int
+
+const (
+	CSVFieldsNone    CSVFieldsMode = iota // Дополнительные поля не выгружаются
+	CSVFieldsJSON                         // Все поля записи одной колонкой fields в виде JSON объекта
+	CSVFieldsColumns                      // Каждое поле отдельной колонкой field.<имя>
+)
+
+// CSVOptions параметры выгрузки в CSV
+type CSVOptions struct {
+	Fields CSVFieldsMode // Способ выгрузки дополнительных полей (по умолчанию не выгружаются)
+}
+
+// csvHeader обязательные колонки CSV выгрузки
+var csvHeader = []string{"timestamp", "service", "level", "message"}
+
+// entryIterator обходит записи лога, передавая их в fn, пока fn возвращает true
+type entryIterator func(fn func(LogEntry) bool) error
+
+// ExportCSV записывает в w подходящие под фильтр записи лога в формате CSV
+// Первая строка - заголовок timestamp,service,level,message. Экранирование
+// запятых, кавычек и переводов строк выполняет encoding/csv.
+func (s *LogServer) ExportCSV(w io.Writer, filter FilterOptions) error {
+	return s.ExportCSVWithOptions(w, filter, CSVOptions{})
+}
+
+// ExportCSVWithOptions записывает записи лога в CSV с выгрузкой дополнительных полей
+func (s *LogServer) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeCSV(w, options, func(fn func(LogEntry) bool) error {
+		return s.eachLogEntry(context.Background(), filter, fn)
+	})
+}
+
+// writeCSV записывает записи, полученные через each, в формате CSV
+// Для выгрузки полей колонками записи обходятся дважды: сначала собираются
+// имена полей для заголовка, затем записываются строки. Так весь результат
+// не хранится в памяти.
+func writeCSV(w io.Writer, options CSVOptions, each entryIterator) error {
+	header := append([]string(nil), csvHeader...)
+
+	var fieldNames []string
+	switch options.Fields {
+	case CSVFieldsNone:
+	case CSVFieldsJSON:
+		header = append(header, "fields")
+	case CSVFieldsColumns:
+		names := make(map[string]struct{})
+		err := each(func(entry LogEntry) bool {
+			for name := range entry.Fields {
+				names[name] = struct{}{}
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		for name := range names {
+			fieldNames = append(fieldNames, name)
+		}
+		sort.Strings(fieldNames)
+		for _, name := range fieldNames {
+			header = append(header, "field."+name)
+		}
+	default:
+		return fmt.Errorf("неизвестный способ выгрузки полей: %d", options.Fields)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+
+	var writeErr error
+	record := make([]string, len(header))
+	err := each(func(entry LogEntry) bool {
+		record = record[:len(csvHeader)]
+		record[0] = entry.Timestamp.Format(time.RFC3339)
+		record[1] = entry.Service
+		record[2] = entry.Level.String()
+		record[3] = entry.Message
+
+		switch options.Fields {
+		case CSVFieldsJSON:
+			fields := ""
+			if len(entry.Fields) > 0 {
+				data, err := json.Marshal(entry.Fields)
+				if err != nil {
+					writeErr = err
+					return false
+				}
+				fields = string(data)
+			}
+			record = append(record, fields)
+		case CSVFieldsColumns:
+			for _, name := range fieldNames {
+				record = append(record, entry.Fields[name])
+			}
+		}
+
+		if writeErr = writer.Write(record); writeErr != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return fmt.Errorf("ошибка записи CSV: %w", writeErr)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+	return nil
+}
+
+// ExportNDJSON записывает в w подходящие под фильтр записи лога в формате NDJSON
+// Каждая запись - отдельный JSON объект LogEntry на своей строке, что удобно
+// для jq и загрузки в Loki. Записи кодируются по одной, без сборки массива.
+func (s *LogServer) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeNDJSON(w, func(fn func(LogEntry) bool) error {
+		return s.eachLogEntry(context.Background(), filter, fn)
+	})
+}
+
+// writeNDJSON записывает записи, полученные через each, по одному JSON объекту на строку
+func writeNDJSON(w io.Writer, each entryIterator) error {
+	encoder := json.NewEncoder(w)
+
+	var writeErr error
+	err := each(func(entry LogEntry) bool {
+		writeErr = encoder.Encode(entry)
+		return writeErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return fmt.Errorf("ошибка записи NDJSON: %w", writeErr)
+	}
+	return nil
+}
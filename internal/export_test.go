@@ -0,0 +1,160 @@
+// export_test.go - Тесты для выгрузки записей лога в CSV и NDJSON
+package logger
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newExportTestServer создает сервер с записями, требующими экранирования в CSV
+func newExportTestServer(t *testing.T) *LogServer {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Stop() })
+
+	now := time.Now()
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: `запрос "GET /", код 200`, Timestamp: now,
+		Fields: map[string]string{"user_id": "42"}})
+	server.writeMessage(LogMessage{Service: "DB", Level: ERROR, Message: "таймаут, повтор", Timestamp: now,
+		Fields: map[string]string{"query": "select", "user_id": "7"}})
+	server.writeMessage(LogMessage{Service: "API", Level: WARN, Message: "без полей", Timestamp: now})
+
+	return server
+}
+
+// readCSV разбирает выгрузку обратно в строки
+func readCSV(t *testing.T, data []byte) [][]string {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("выгрузка не является корректным CSV: %v\n%s", err, data)
+	}
+	return records
+}
+
+// TestServerExportCSV проверяет заголовок, экранирование и способы выгрузки полей
+func TestServerExportCSV(t *testing.T) {
+	server := newExportTestServer(t)
+
+	tests := []struct {
+		name    string
+		options CSVOptions
+		header  []string
+		extra   [][]string // Дополнительные колонки каждой строки
+	}{
+		{"без полей", CSVOptions{}, nil, [][]string{nil, nil, nil}},
+		{"поля в JSON", CSVOptions{Fields: CSVFieldsJSON}, []string{"fields"},
+			[][]string{{`{"user_id":"42"}`}, {`{"query":"select","user_id":"7"}`}, {""}}},
+		{"поля колонками", CSVOptions{Fields: CSVFieldsColumns}, []string{"field.query", "field.user_id"},
+			[][]string{{"", "42"}, {"select", "7"}, {"", ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := server.ExportCSVWithOptions(&buf, FilterOptions{}, tt.options); err != nil {
+				t.Fatalf("ошибка выгрузки: %v", err)
+			}
+
+			records := readCSV(t, buf.Bytes())
+			if len(records) != 4 {
+				t.Fatalf("ожидалось 4 строки (заголовок и 3 записи), получено %d", len(records))
+			}
+			if header := append([]string{"timestamp", "service", "level", "message"}, tt.header...); !reflect.DeepEqual(records[0], header) {
+				t.Errorf("неверный заголовок: %v", records[0])
+			}
+			if records[1][1] != "API" || records[1][2] != "INFO" || records[1][3] != `запрос "GET /", код 200` {
+				t.Errorf("неверная строка записи: %v", records[1])
+			}
+			if _, err := time.Parse(time.RFC3339, records[1][0]); err != nil {
+				t.Errorf("время должно быть в формате RFC3339: %q", records[1][0])
+			}
+			for i, extra := range tt.extra {
+				if got := records[i+1][4:]; len(got) != len(extra) || (len(extra) > 0 && !reflect.DeepEqual(got, extra)) {
+					t.Errorf("строка %d: ожидались поля %v, получено %v", i+1, extra, got)
+				}
+			}
+		})
+	}
+
+	// Фильтр применяется так же, как в GetLogEntries
+	var buf bytes.Buffer
+	if err := server.ExportCSV(&buf, FilterOptions{Service: "DB"}); err != nil {
+		t.Fatalf("ошибка выгрузки: %v", err)
+	}
+	if records := readCSV(t, buf.Bytes()); len(records) != 2 || records[1][1] != "DB" {
+		t.Errorf("ожидалась одна запись DB, получено %v", records)
+	}
+
+	if err := server.ExportCSVWithOptions(&buf, FilterOptions{}, CSVOptions{Fields: 42}); err == nil {
+		t.Error("ожидалась ошибка для неизвестного способа выгрузки полей")
+	}
+}
+
+// TestClientExportCSV проверяет выгрузку в CSV через клиента
+func TestClientExportCSV(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+	writeTestEntries(server, 150)
+
+	var buf bytes.Buffer
+	if err := client.ExportCSV(&buf, FilterOptions{Service: "BENCH"}); err != nil {
+		t.Fatalf("ошибка выгрузки: %v", err)
+	}
+
+	records := readCSV(t, buf.Bytes())
+	if len(records) != 151 {
+		t.Fatalf("ожидалась 151 строка, получено %d", len(records))
+	}
+	if records[150][3] != "запись номер 149 для проверки чтения большого лога" {
+		t.Errorf("неверная последняя запись: %v", records[150])
+	}
+}
+
+// TestExportNDJSON проверяет выгрузку по одному JSON объекту на строку
+func TestExportNDJSON(t *testing.T) {
+	server := newExportTestServer(t)
+
+	var buf bytes.Buffer
+	if err := server.ExportNDJSON(&buf, FilterOptions{Service: "API"}); err != nil {
+		t.Fatalf("ошибка выгрузки: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ожидалось 2 строки, получено %d: %q", len(lines), buf.String())
+	}
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("строка не является JSON объектом: %v", err)
+	}
+	if entry.Service != "API" || entry.Fields["user_id"] != "42" {
+		t.Errorf("неверная запись: %+v", entry)
+	}
+
+	if err := server.ExportNDJSON(&buf, FilterOptions{Limit: -1}); err == nil {
+		t.Error("ожидалась ошибка валидации фильтра")
+	}
+}
+
+// TestClientExportNDJSON проверяет выгрузку в NDJSON через клиента
+func TestClientExportNDJSON(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+	writeTestEntries(server, 150)
+
+	var buf bytes.Buffer
+	if err := client.ExportNDJSON(&buf, FilterOptions{Service: "BENCH", Limit: 120}); err != nil {
+		t.Fatalf("ошибка выгрузки: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 120 {
+		t.Errorf("ожидалось 120 строк, получено %d", lines)
+	}
+}
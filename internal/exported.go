@@ -1,12 +1,47 @@
 // Package logger - экспортируемые функции для упрощенного интерфейса логирования
 package logger
 
-import "fmt"
+import (
+	"fmt"
+	"sync/atomic"
+)
 
 // Глобальные функции для простого доступа к логированию без создания экземпляра
+// Если задан логгер по умолчанию (SetDefault), сообщения отправляются через него,
+// иначе выводятся в stdout.
+
+// defaultLogger логгер по умолчанию для глобальных функций
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault задает логгер, через который работают глобальные функции
+// Передача nil возвращает вывод в stdout. Безопасна для вызова из разных горутин.
+// Fatal и Panic при заданном логгере ведут себя как методы Logger: завершают
+// программу и вызывают панику после отправки сообщения.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default возвращает логгер по умолчанию или nil, если он не задан
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// Service возвращает логгер сервиса из логгера по умолчанию
+// Если логгер по умолчанию не задан, сообщения сервиса выводятся в stderr.
+func Service(name string) *ServiceLogger {
+	if l := defaultLogger.Load(); l != nil {
+		return l.SetService(name)
+	}
+	return newServiceLogger(stderrSender{}, name)
+}
 
 // Debug логирует сообщение с уровнем DEBUG с поддержкой различных типов аргументов
 func Debug(args ...interface{}) {
+	if l := defaultLogger.Load(); l != nil {
+		_ = l.Debug(args...)
+		return
+	}
+
 	// Обрабатываем аргументы
 	if len(args) == 0 {
 		fmt.Println("[DEBUG] ")
@@ -25,6 +60,11 @@ func Debug(args ...interface{}) {
 
 // Info логирует сообщение с уровнем INFO с поддержкой различных типов аргументов
 func Info(args ...interface{}) {
+	if l := defaultLogger.Load(); l != nil {
+		_ = l.Info(args...)
+		return
+	}
+
 	// Обрабатываем аргументы
 	if len(args) == 0 {
 		fmt.Println("[INFO] ")
@@ -43,6 +83,11 @@ func Info(args ...interface{}) {
 
 // Warn логирует сообщение с уровнем WARN с поддержкой различных типов аргументов
 func Warn(args ...interface{}) {
+	if l := defaultLogger.Load(); l != nil {
+		_ = l.Warn(args...)
+		return
+	}
+
 	// Обрабатываем аргументы
 	if len(args) == 0 {
 		fmt.Println("[WARN] ")
@@ -61,6 +106,11 @@ func Warn(args ...interface{}) {
 
 // Error логирует сообщение с уровнем ERROR с поддержкой различных типов аргументов
 func Error(args ...interface{}) {
+	if l := defaultLogger.Load(); l != nil {
+		_ = l.Error(args...)
+		return
+	}
+
 	// Обрабатываем аргументы
 	if len(args) == 0 {
 		fmt.Println("[ERROR] ")
@@ -79,6 +129,11 @@ func Error(args ...interface{}) {
 
 // Fatal логирует сообщение с уровнем FATAL с поддержкой различных типов аргументов
 func Fatal(args ...interface{}) {
+	if l := defaultLogger.Load(); l != nil {
+		_ = l.Fatal(args...)
+		return
+	}
+
 	// Обрабатываем аргументы
 	if len(args) == 0 {
 		fmt.Println("[FATAL] ")
@@ -97,6 +152,11 @@ func Fatal(args ...interface{}) {
 
 // Panic логирует сообщение с уровнем PANIC с поддержкой различных типов аргументов
 func Panic(args ...interface{}) {
+	if l := defaultLogger.Load(); l != nil {
+		_ = l.Panic(args...)
+		return
+	}
+
 	// Обрабатываем аргументы
 	if len(args) == 0 {
 		fmt.Println("[PANIC] ")
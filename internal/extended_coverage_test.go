@@ -39,8 +39,8 @@ func TestServerHelperFunctions(t *testing.T) {
 	}
 
 	defer func() {
-		if server.file != nil {
-			_ = server.file.Close()
+		if server.logFile.file != nil {
+			_ = server.logFile.file.Close()
 		}
 	}()
 
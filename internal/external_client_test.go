@@ -0,0 +1,77 @@
+// external_client_test.go - Собственная реализация ClientInterface вне пакета
+package logger_test
+
+import (
+	"testing"
+
+	logger "github.com/qzeleza/zlogger/internal"
+)
+
+// transportClient транспорт вне пакета: получает сообщения сервисов через MessageSender
+type transportClient struct {
+	logger.ClientInterface
+	sent []string
+}
+
+func (c *transportClient) SendMessage(service string, level logger.LogLevel, message string, fields map[string]string) error {
+	c.sent = append(c.sent, service+" "+level.String()+" "+message+" "+fields["path"])
+	return nil
+}
+
+func (c *transportClient) SetService(service string) *logger.ServiceLogger {
+	return logger.NewServiceLogger(c, service)
+}
+
+// zeroServiceClient возвращает логгер сервиса без клиента
+type zeroServiceClient struct {
+	logger.ClientInterface
+}
+
+func (zeroServiceClient) SetService(service string) *logger.ServiceLogger {
+	return &logger.ServiceLogger{}
+}
+
+// recursiveClient возвращает логгер сервиса через NewWithClient от самого себя
+type recursiveClient struct {
+	logger.ClientInterface
+}
+
+func (c *recursiveClient) SetService(service string) *logger.ServiceLogger {
+	return logger.NewWithClient(c).SetService(service)
+}
+
+// TestNewWithClientExternal проверяет логгер поверх реализации ClientInterface из другого пакета
+func TestNewWithClientExternal(t *testing.T) {
+	transport := &transportClient{ClientInterface: logger.NewNop()}
+	log := logger.NewWithClient(transport, "db")
+
+	if err := log.SetService("api").Info("запрос", "path", "/users"); err != nil {
+		t.Fatalf("ошибка записи сервиса: %v", err)
+	}
+	if err := transport.SetService("db").Warn("медленно"); err != nil {
+		t.Fatalf("ошибка записи через NewServiceLogger: %v", err)
+	}
+	if len(transport.sent) != 2 || transport.sent[0] != "API INFO запрос /users" || transport.sent[1] != "DB WARN медленно " {
+		t.Errorf("неожиданные сообщения транспорта: %q", transport.sent)
+	}
+
+	// Нулевой логгер сервиса не вызывает панику
+	zero := logger.NewWithClient(zeroServiceClient{ClientInterface: logger.NewNop()})
+	if err := zero.SetService("api").Info("без транспорта"); err == nil {
+		t.Error("ожидалась ошибка для клиента, возвращающего логгер сервиса без клиента")
+	}
+	var empty logger.ServiceLogger
+	if err := empty.Info("в stderr"); err != nil {
+		t.Errorf("нулевой ServiceLogger должен писать в stderr: %v", err)
+	}
+
+	// SetService через NewWithClient от самого клиента не зацикливается
+	recursive := logger.NewWithClient(&recursiveClient{ClientInterface: logger.NewNop()})
+	if err := recursive.SetService("api").Info("по кругу"); err == nil {
+		t.Error("ожидалась ошибка вместо бесконечной рекурсии")
+	}
+
+	if err := logger.NewServiceLogger(transport, "bad name!").Info("x"); err == nil {
+		t.Error("ожидалась ошибка для недопустимого имени сервиса")
+	}
+}
@@ -0,0 +1,281 @@
+// fake.go - Подставной клиент для модульных тестов приложений
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FakeClient клиент для модульных тестов кода, принимающего ClientInterface
+// Записи сохраняются в MemorySink, а уровни, обработчики и запросы записей
+// работают так же, как у NewMemory. Дополнительно FakeClient считает вызовы
+// методов (Calls) и возвращает ошибки, заданные FailOn, что позволяет проверить
+// обработку сбоев логгера. Fatal записывает сообщение, но не завершает программу.
+// Методы безопасны для вызова из нескольких горутин.
+type FakeClient struct {
+	memory *memoryClient
+
+	mu    sync.Mutex
+	calls map[string]int   // Количество вызовов по имени метода
+	fails map[string]error // Ошибки, которые возвращают методы (см. FailOn)
+}
+
+var _ LogClientInterface = (*FakeClient)(nil)
+
+// NewFakeClient создает подставной клиент и приемник с его записями
+// Общий уровень логирования - INFO, как у NewMemory.
+func NewFakeClient() (*FakeClient, *MemorySink) {
+	log, sink := NewMemory()
+	fake := &FakeClient{
+		memory: log.client.(*memoryClient),
+		calls:  make(map[string]int),
+		fails:  make(map[string]error),
+	}
+	return fake, sink
+}
+
+// FailOn задает ошибку, которую возвращает метод с именем method (например, "Info" или "Ping")
+// Метод с ошибкой ничего не записывает. err == nil снимает ошибку. Запись через
+// логгер сервиса возвращает ошибку метода своего уровня: ServiceLogger.Info - ошибку "Info".
+func (f *FakeClient) FailOn(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.fails, method)
+		return
+	}
+	f.fails[method] = err
+}
+
+// Calls возвращает количество вызовов метода с именем method
+// Записи через логгеры сервисов учитываются в методе своего уровня.
+func (f *FakeClient) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// call учитывает вызов метода и возвращает заданную для него ошибку
+func (f *FakeClient) call(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[method]++
+	return f.fails[method]
+}
+
+// levelMethod возвращает имя метода записи уровня level (INFO - "Info")
+func levelMethod(level LogLevel) string {
+	name := level.String()
+	return name[:1] + strings.ToLower(name[1:])
+}
+
+// SetService возвращает логгер сервиса, записывающий сообщения через FakeClient
+func (f *FakeClient) SetService(service string) *ServiceLogger {
+	_ = f.call("SetService")
+	return newServiceLogger(f, service)
+}
+
+func (f *FakeClient) SetLevel(level LogLevel) {
+	_ = f.call("SetLevel")
+	f.memory.SetLevel(level)
+}
+
+func (f *FakeClient) SetServerLevel(level LogLevel) error {
+	if err := f.call("SetServerLevel"); err != nil {
+		return err
+	}
+	return f.memory.SetServerLevel(level)
+}
+
+func (f *FakeClient) GetServerLevel() (LogLevel, error) {
+	if err := f.call("GetServerLevel"); err != nil {
+		return INFO, err
+	}
+	return f.memory.GetServerLevel()
+}
+
+func (f *FakeClient) ServerCapabilities() (Capabilities, error) {
+	if err := f.call("ServerCapabilities"); err != nil {
+		return Capabilities{}, err
+	}
+	return f.memory.ServerCapabilities()
+}
+
+func (f *FakeClient) GetServiceLevels() (map[string]LogLevel, error) {
+	if err := f.call("GetServiceLevels"); err != nil {
+		return nil, err
+	}
+	return f.memory.GetServiceLevels()
+}
+
+func (f *FakeClient) SetServiceLevel(service string, level LogLevel) error {
+	if err := f.call("SetServiceLevel"); err != nil {
+		return err
+	}
+	return f.memory.SetServiceLevel(service, level)
+}
+
+func (f *FakeClient) ResetServiceLevel(service string) error {
+	if err := f.call("ResetServiceLevel"); err != nil {
+		return err
+	}
+	return f.memory.ResetServiceLevel(service)
+}
+
+func (f *FakeClient) RegisterService(service string) error {
+	if err := f.call("RegisterService"); err != nil {
+		return err
+	}
+	return f.memory.RegisterService(service)
+}
+
+func (f *FakeClient) GetLogFile() string {
+	_ = f.call("GetLogFile")
+	return f.memory.GetLogFile()
+}
+
+func (f *FakeClient) UpdateConfig(config *LoggingConfig) error {
+	if err := f.call("UpdateConfig"); err != nil {
+		return err
+	}
+	return f.memory.UpdateConfig(config)
+}
+
+// LogPanic записывает перехваченную панику и перебрасывает ее
+func (f *FakeClient) LogPanic() {
+	if r := recover(); r != nil {
+		_ = f.call("LogPanic")
+		_ = f.memory.sendMessage("MAIN", PANIC, fmt.Sprintf("Восстановлено после паники: %v", r), nil)
+		panic(r)
+	}
+}
+
+func (f *FakeClient) GetLogEntries(filter FilterOptions) ([]LogEntry, error) {
+	if err := f.call("GetLogEntries"); err != nil {
+		return nil, err
+	}
+	return f.memory.GetLogEntries(filter)
+}
+
+func (f *FakeClient) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
+	if err := f.call("GetLogEntriesContext"); err != nil {
+		return nil, err
+	}
+	return f.memory.GetLogEntriesContext(ctx, filter)
+}
+
+func (f *FakeClient) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	if err := f.call("StreamLogEntries"); err != nil {
+		return err
+	}
+	return f.memory.StreamLogEntries(filter, fn)
+}
+
+func (f *FakeClient) ExportCSV(w io.Writer, filter FilterOptions) error {
+	if err := f.call("ExportCSV"); err != nil {
+		return err
+	}
+	return f.memory.ExportCSV(w, filter)
+}
+
+func (f *FakeClient) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	if err := f.call("ExportCSVWithOptions"); err != nil {
+		return err
+	}
+	return f.memory.ExportCSVWithOptions(w, filter, options)
+}
+
+func (f *FakeClient) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	if err := f.call("ExportNDJSON"); err != nil {
+		return err
+	}
+	return f.memory.ExportNDJSON(w, filter)
+}
+
+func (f *FakeClient) GetRange(start, count int) ([]LogEntry, error) {
+	if err := f.call("GetRange"); err != nil {
+		return nil, err
+	}
+	return f.memory.GetRange(start, count)
+}
+
+func (f *FakeClient) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	if err := f.call("GetFilteredRange"); err != nil {
+		return nil, err
+	}
+	return f.memory.GetFilteredRange(start, count, filter)
+}
+
+func (f *FakeClient) Ping() error { return f.call("Ping") }
+
+func (f *FakeClient) Health() (HealthStatus, error) {
+	if err := f.call("Health"); err != nil {
+		return HealthStatus{Error: err.Error(), FreeDiskBytes: -1}, err
+	}
+	return f.memory.Health()
+}
+
+func (f *FakeClient) Flush() error         { return f.call("Flush") }
+func (f *FakeClient) Close() error         { return f.call("Close") }
+func (f *FakeClient) CloseAndFlush() error { return f.call("CloseAndFlush") }
+
+func (f *FakeClient) AddHook(hook MessageHook) {
+	_ = f.call("AddHook")
+	f.memory.AddHook(hook)
+}
+
+func (f *FakeClient) TruncatedFields() int64 {
+	_ = f.call("TruncatedFields")
+	return 0
+}
+
+func (f *FakeClient) Trace(args ...interface{}) error { return f.log(TRACE, args) }
+func (f *FakeClient) Debug(args ...interface{}) error { return f.log(DEBUG, args) }
+func (f *FakeClient) Info(args ...interface{}) error  { return f.log(INFO, args) }
+func (f *FakeClient) Warn(args ...interface{}) error  { return f.log(WARN, args) }
+func (f *FakeClient) Error(args ...interface{}) error { return f.log(ERROR, args) }
+
+// Fatal записывает сообщение, но не завершает программу: тест может проверить запись
+func (f *FakeClient) Fatal(args ...interface{}) error { return f.log(FATAL, args) }
+
+// fatal записывает сообщение FATAL сервиса, не завершая программу
+func (f *FakeClient) fatal(service string, message string, fields map[string]string) error {
+	return f.sendMessage(service, FATAL, message, fields)
+}
+
+// Panic записывает сообщение и вызывает панику
+func (f *FakeClient) Panic(args ...interface{}) error {
+	message, fields := processArgs(args...)
+	return f.panic("MAIN", message, fields)
+}
+
+// panic записывает сообщение PANIC сервиса и вызывает панику с текстом после обработчиков
+func (f *FakeClient) panic(service string, message string, fields map[string]string) error {
+	if err := f.call(levelMethod(PANIC)); err != nil {
+		panic(message)
+	}
+	text, _ := f.memory.record(service, PANIC, message, fields)
+	panic(text)
+}
+
+// log записывает сообщение сервиса MAIN
+func (f *FakeClient) log(level LogLevel, args []interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("отсутствуют аргументы")
+	}
+	message, fields := processArgs(args...)
+	return f.sendMessage("MAIN", level, message, fields)
+}
+
+// sendMessage учитывает вызов метода уровня и сохраняет запись, если для него не задана ошибка
+func (f *FakeClient) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	if err := f.call(levelMethod(level)); err != nil {
+		return err
+	}
+	return f.memory.sendMessage(service, level, message, fields)
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+)
+
+/**
+ * TestFakeClient проверяет запись сообщений и подсчет вызовов подставного клиента
+ * @param t *testing.T - тестовый контекст
+ */
+func TestFakeClient(t *testing.T) {
+	fake, sink := NewFakeClient()
+
+	// Код приложения получает FakeClient как ClientInterface
+	var client ClientInterface = fake
+	_ = client.Info("запуск")
+	_ = client.Debug("отладка ниже уровня")
+	_ = client.SetService("api").Info("запрос", "path", "/users")
+
+	if fake.Calls("Info") != 2 || fake.Calls("Debug") != 1 || fake.Calls("SetService") != 1 {
+		t.Errorf("неожиданное количество вызовов: Info=%d, Debug=%d, SetService=%d",
+			fake.Calls("Info"), fake.Calls("Debug"), fake.Calls("SetService"))
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 2 || entries[1].Service != "API" || entries[1].Fields["path"] != "/users" {
+		t.Errorf("неожиданные записи: %+v", entries)
+	}
+
+	found, err := client.GetLogEntries(FilterOptions{Service: "API"})
+	if err != nil || len(found) != 1 {
+		t.Errorf("ожидалась одна запись API, получили %+v, %v", found, err)
+	}
+}
+
+/**
+ * TestFakeClientFailOn проверяет ошибки, заданные FailOn, и их снятие
+ * @param t *testing.T - тестовый контекст
+ */
+func TestFakeClientFailOn(t *testing.T) {
+	fake, sink := NewFakeClient()
+	errDown := errors.New("логгер недоступен")
+
+	fake.FailOn("Error", errDown)
+	fake.FailOn("Ping", ErrServerUnavailable)
+
+	if err := fake.Error("сбой"); !errors.Is(err, errDown) {
+		t.Errorf("ожидалась заданная ошибка Error, получили %v", err)
+	}
+	if err := fake.SetService("db").Error("сбой базы"); !errors.Is(err, errDown) {
+		t.Errorf("логгер сервиса должен возвращать ошибку своего уровня, получили %v", err)
+	}
+	if err := fake.Ping(); !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("ожидалась ошибка Ping, получили %v", err)
+	}
+	if len(sink.Entries()) != 0 {
+		t.Errorf("метод с ошибкой не должен ничего записывать: %+v", sink.Entries())
+	}
+
+	fake.FailOn("Error", nil)
+	if err := fake.Error("после снятия"); err != nil || !sink.Contains(ERROR, "после снятия") {
+		t.Errorf("после снятия ошибки сообщение должно записываться: %v", err)
+	}
+	if fake.Calls("Error") != 3 {
+		t.Errorf("ожидалось 3 вызова Error, получили %d", fake.Calls("Error"))
+	}
+}
+
+/**
+ * TestFakeClientFatal проверяет, что Fatal записывает сообщение и не завершает программу
+ * @param t *testing.T - тестовый контекст
+ */
+func TestFakeClientFatal(t *testing.T) {
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(code int) {
+		t.Errorf("FakeClient не должен завершать программу, код %d", code)
+	}
+
+	fake, sink := NewFakeClient()
+	_ = fake.Fatal("критично")
+	_ = fake.SetService("api").Fatal("критично в сервисе")
+
+	if fake.Calls("Fatal") != 2 || !sink.Contains(FATAL, "критично в сервисе") {
+		t.Errorf("сообщения FATAL должны быть записаны: %+v", sink.Entries())
+	}
+
+	defer func() {
+		if r := recover(); r != "паника" || !sink.Contains(PANIC, "паника") {
+			t.Errorf("ожидалась записанная паника, получили %v", r)
+		}
+	}()
+	_ = fake.Panic("паника")
+}
@@ -125,6 +125,31 @@ func TestFatalFunctions(t *testing.T) {
 	}
 }
 
+/**
+ * TestClientFatalExitFunc проверяет Fatal в текущем процессе через подмену exitFunc
+ * @param t *testing.T - тестовый контекст
+ */
+func TestClientFatalExitFunc(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	exitCodes := make([]int, 0, 1)
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(code int) { exitCodes = append(exitCodes, code) }
+
+	if err := client.Fatal("test fatal message: %s", "formatted"); err != nil {
+		t.Errorf("неожиданная ошибка Fatal: %v", err)
+	}
+
+	if len(exitCodes) != 1 || exitCodes[0] != 1 {
+		t.Fatalf("ожидался один вызов exitFunc с кодом 1, получили %v", exitCodes)
+	}
+
+	// Сообщение отправлено серверу до вызова exitFunc
+	waitForLogContent(t, server, "test fatal message: formatted")
+}
+
 /**
  * TestServerFunctionsWithoutSocket тестирует функции сервера без создания сокета
  * @param t *testing.T - тестовый контекст
@@ -193,8 +218,8 @@ func TestServerFunctionsWithoutSocket(t *testing.T) {
 
 	// Если сервер создался успешно, тестируем его методы
 	defer func() {
-		if testServer.file != nil {
-			_ = testServer.file.Close()
+		if testServer.logFile.file != nil {
+			_ = testServer.logFile.file.Close()
 		}
 	}()
 
@@ -215,7 +240,7 @@ func TestServerFunctionsWithoutSocket(t *testing.T) {
 	}
 
 	// Тестируем sendError (не требует сокета)
-	testServer.sendError(nil, "test error")
+	testServer.sendError(nil, ErrorCodeInternal, "test error")
 	// Функция не должна паниковать при nil соединении
 
 	// Тестируем handlePing (не требует сокета)
@@ -53,16 +53,23 @@ func TestFilterOptions(t *testing.T) {
 		t.Errorf("ожидалось 2 записи для SERVICE1, получено %d", len(filtered))
 	}
 
-	// Тестируем фильтрацию по уровню
+	// Тестируем фильтрацию по минимальному уровню
 	errorLevel := ERROR
-	filter = FilterOptions{Level: &errorLevel}
+	filter = FilterOptions{MinLevel: &errorLevel}
 	filtered = filterEntries(entries, filter)
 	if len(filtered) != 2 { // ERROR и PANIC
 		t.Errorf("ожидалось 2 записи для уровня ERROR и выше, получено %d", len(filtered))
 	}
 
+	// Точное совпадение уровня
+	filter = FilterOptions{Level: &errorLevel}
+	filtered = filterEntries(entries, filter)
+	if len(filtered) != 1 {
+		t.Errorf("ожидалась 1 запись с уровнем ERROR, получено %d", len(filtered))
+	}
+
 	// Тестируем комбинированную фильтрацию
-	filter = FilterOptions{Service: "SERVICE2", Level: &errorLevel}
+	filter = FilterOptions{Service: "SERVICE2", MinLevel: &errorLevel}
 	filtered = filterEntries(entries, filter)
 	if len(filtered) != 1 { // Только ERROR от SERVICE2
 		t.Errorf("ожидалась 1 запись для SERVICE2 с уровнем ERROR и выше, получено %d", len(filtered))
@@ -70,7 +77,7 @@ func TestFilterOptions(t *testing.T) {
 }
 
 /**
- * filterEntries вспомогательная функция для фильтрации записей (имитирует логику сервера)
+ * filterEntries вспомогательная функция для фильтрации записей (использует логику сервера)
  * @param entries []LogEntry - записи для фильтрации
  * @param filter FilterOptions - параметры фильтрации
  * @return []LogEntry - отфильтрованные записи
@@ -78,13 +85,9 @@ func TestFilterOptions(t *testing.T) {
 func filterEntries(entries []LogEntry, filter FilterOptions) []LogEntry {
 	var result []LogEntry
 	for _, entry := range entries {
-		if filter.Service != "" && entry.Service != filter.Service {
-			continue
-		}
-		if filter.Level != nil && entry.Level < *filter.Level {
-			continue
+		if filter.matches(entry) {
+			result = append(result, entry)
 		}
-		result = append(result, entry)
 	}
 	return result
 }
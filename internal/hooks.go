@@ -0,0 +1,153 @@
+// hooks.go - Обработчики сообщений клиента перед отправкой серверу
+package logger
+
+import "regexp"
+
+// Шаблоны DefaultRedactors компилируются один раз при загрузке пакета
+var (
+	// Номера банковских карт: 13-19 цифр, допускаются пробелы и дефисы
+	redactCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	// Адреса электронной почты, в том числе с кириллическими доменами
+	redactEmailPattern = regexp.MustCompile(`[\p{L}\p{N}._%+-]+@[\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)*\.\p{L}{2,}`)
+	// Токены в заголовке Authorization: Bearer
+	redactBearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// redactChecks дополнительные проверки совпадений встроенных шаблонов
+// Номер карты маскируется, только если проходит проверку Луна: иначе маскировались
+// бы любые длинные числа, например идентификаторы заказов и метки времени.
+var redactChecks = map[*regexp.Regexp]func(string) bool{
+	redactCardPattern: luhnValid,
+}
+
+// luhnValid проверяет контрольную цифру номера по алгоритму Луна (пробелы и дефисы пропускаются)
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// MessageHook обработчик сообщения перед отправкой серверу
+// Может изменять Message и Fields (например, маскировать секреты) или
+// отбросить сообщение, вернув false. Fields сообщения - копия полей вызова,
+// поэтому изменение карты не затрагивает данные вызывающего кода.
+type MessageHook func(msg *LogMessage) bool
+
+// AddHook добавляет обработчик сообщений
+// Обработчики вызываются в порядке добавления в горутине, вызвавшей метод
+// логирования, до проверки соединения, поэтому медленный обработчик замедляет
+// логирование. После первого обработчика, вернувшего false, остальные не
+// вызываются и сообщение не отправляется (и не выводится в stderr).
+func (c *LogClient) AddHook(hook MessageHook) {
+	if hook == nil {
+		return
+	}
+
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+
+	// Новый срез вместо append на месте: sendMessage читает прежний без блокировки
+	hooks := make([]MessageHook, len(c.hooks), len(c.hooks)+1)
+	copy(hooks, c.hooks)
+	c.hooks = append(hooks, hook)
+}
+
+// runHooks применяет обработчики к сообщению
+// Возвращает false, если сообщение нужно отбросить.
+func (c *LogClient) runHooks(msg *LogMessage) bool {
+	c.hooksMu.RLock()
+	hooks := c.hooks
+	c.hooksMu.RUnlock()
+
+	return applyHooks(hooks, msg)
+}
+
+// applyHooks применяет обработчики к сообщению по порядку
+// Перед вызовом обработчиков поля сообщения копируются.
+func applyHooks(hooks []MessageHook, msg *LogMessage) bool {
+	if len(hooks) == 0 {
+		return true
+	}
+
+	if msg.Fields != nil {
+		fields := make(map[string]string, len(msg.Fields))
+		for key, value := range msg.Fields {
+			fields[key] = value
+		}
+		msg.Fields = fields
+	}
+
+	for _, hook := range hooks {
+		if !hook(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultRedactors возвращает готовые шаблоны для RedactHook
+// Маскируются номера банковских карт (только проходящие проверку Луна),
+// адреса электронной почты и токены Bearer.
+// Возвращается новый срез, его можно дополнять своими шаблонами.
+func DefaultRedactors() []*regexp.Regexp {
+	return []*regexp.Regexp{redactCardPattern, redactEmailPattern, redactBearerPattern}
+}
+
+// RedactHook создает обработчик, заменяющий совпадения шаблонов на replacement
+// Замена выполняется в тексте сообщения и значениях полей; имена полей не
+// меняются. Шаблоны должны быть скомпилированы заранее (regexp.MustCompile),
+// обработчик только применяет их. Регулярные выражения работают с UTF-8,
+// поэтому сообщения на любом языке обрабатываются корректно. Обработчик
+// никогда не отбрасывает сообщение.
+func RedactHook(patterns []*regexp.Regexp, replacement string) MessageHook {
+	// Копия защищает от изменения среза вызывающим кодом после создания обработчика
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern != nil {
+			compiled = append(compiled, pattern)
+		}
+	}
+
+	redact := func(text string) string {
+		for _, pattern := range compiled {
+			check, ok := redactChecks[pattern]
+			if !ok {
+				text = pattern.ReplaceAllLiteralString(text, replacement)
+				continue
+			}
+			text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+				if check(match) {
+					return replacement
+				}
+				return match
+			})
+		}
+		return text
+	}
+
+	return func(msg *LogMessage) bool {
+		msg.Message = redact(msg.Message)
+		for key, value := range msg.Fields {
+			msg.Fields[key] = redact(value)
+		}
+		return true
+	}
+}
@@ -0,0 +1,181 @@
+// hooks_test.go - Тесты обработчиков сообщений клиента
+package logger
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestClientHooks проверяет порядок обработчиков, изменение и отбрасывание сообщений
+func TestClientHooks(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	var order []string
+	client.AddHook(func(msg *LogMessage) bool {
+		order = append(order, "first")
+		if msg.Fields["password"] != "" {
+			msg.Fields["password"] = "***"
+		}
+		return !strings.Contains(msg.Message, "drop")
+	})
+	client.AddHook(func(msg *LogMessage) bool {
+		order = append(order, "second")
+		msg.Message = strings.ReplaceAll(msg.Message, "secret", "[скрыто]")
+		return true
+	})
+	client.AddHook(nil)
+
+	fields := map[string]string{"user": "admin", "password": "qwerty"}
+	if err := client.Info("вход с secret токеном", fields); err != nil {
+		t.Fatalf("ошибка отправки: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "first,second" {
+		t.Errorf("неверный порядок обработчиков: %s", got)
+	}
+	if fields["password"] != "qwerty" {
+		t.Error("обработчик не должен изменять поля вызывающего кода")
+	}
+
+	order = nil
+	if err := client.Info("drop this message"); err != nil {
+		t.Fatalf("отброшенное сообщение не должно приводить к ошибке: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "first" {
+		t.Errorf("после отбрасывания остальные обработчики не вызываются: %s", got)
+	}
+	if err := client.Info("маркер"); err != nil {
+		t.Fatalf("ошибка отправки: %v", err)
+	}
+
+	waitForLogContent(t, server, "маркер")
+	content, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать лог: %v", err)
+	}
+	text := string(content)
+	for _, unexpected := range []string{"qwerty", "secret", "drop this message"} {
+		if strings.Contains(text, unexpected) {
+			t.Errorf("лог не должен содержать %q:\n%s", unexpected, text)
+		}
+	}
+	for _, expected := range []string{"вход с [скрыто] токеном", "***"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("лог должен содержать %q:\n%s", expected, text)
+		}
+	}
+}
+
+// TestRedactHook проверяет маскирование встроенными шаблонами, в том числе в кириллице
+func TestRedactHook(t *testing.T) {
+	hook := RedactHook(append(DefaultRedactors(), regexp.MustCompile(`пароль=\S+`)), "***")
+
+	msg := &LogMessage{
+		Message: "Оплата картой 4111 1111 1111 1111 от иван.петров@пример.рф, пароль=секрет",
+		Fields: map[string]string{
+			"auth":  "Bearer eyJhbGciOi.J9-x_y==",
+			"email": "user@example.com",
+			"order": "12345",
+			"trace": "1234567890123456",
+			"card":  "5500-0055-5555-5559",
+		},
+	}
+	if !hook(msg) {
+		t.Fatal("RedactHook не должен отбрасывать сообщения")
+	}
+
+	if want := "Оплата картой *** от ***, ***"; msg.Message != want {
+		t.Errorf("ожидалось %q, получено %q", want, msg.Message)
+	}
+	if msg.Fields["auth"] != "***" || msg.Fields["email"] != "***" {
+		t.Errorf("значения полей должны быть замаскированы: %v", msg.Fields)
+	}
+	if msg.Fields["order"] != "12345" {
+		t.Errorf("короткие числа не должны маскироваться: %q", msg.Fields["order"])
+	}
+	if msg.Fields["trace"] != "1234567890123456" {
+		t.Errorf("числа, не проходящие проверку Луна, не должны маскироваться: %q", msg.Fields["trace"])
+	}
+	if msg.Fields["card"] != "***" {
+		t.Errorf("номер карты с дефисами должен маскироваться: %q", msg.Fields["card"])
+	}
+
+	// Пустой список шаблонов оставляет сообщение без изменений
+	plain := &LogMessage{Message: "без изменений"}
+	if !RedactHook(nil, "***")(plain) || plain.Message != "без изменений" {
+		t.Errorf("сообщение не должно меняться: %q", plain.Message)
+	}
+}
+
+// TestTerminalMessagesHooked проверяет, что FATAL и PANIC выводятся в stderr,
+// записываются и передаются в panic уже после обработчиков
+func TestTerminalMessagesHooked(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	hook := func(msg *LogMessage) bool {
+		msg.Message = strings.ReplaceAll(msg.Message, "secret", "[скрыто]")
+		return true
+	}
+	client.AddHook(hook)
+
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(int) {}
+
+	origStderr := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("не удалось создать pipe: %v", err)
+	}
+	os.Stderr = w
+
+	_ = client.Fatal("fatal secret")
+	recovered := func() (value interface{}) {
+		defer func() { value = recover() }()
+		_ = client.SetService("api").Panic("panic secret")
+		return nil
+	}()
+
+	_ = w.Close()
+	os.Stderr = origStderr
+	var stderr strings.Builder
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		stderr.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+
+	if recovered != "panic [скрыто]" {
+		t.Errorf("паника должна получить текст после обработчиков, получено %v", recovered)
+	}
+	if strings.Contains(stderr.String(), "secret") || !strings.Contains(stderr.String(), "fatal [скрыто]") {
+		t.Errorf("stderr должен содержать только текст после обработчиков:\n%s", stderr.String())
+	}
+
+	waitForLogContent(t, server, "panic [скрыто]")
+	content, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать лог: %v", err)
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Errorf("лог не должен содержать исходный текст:\n%s", content)
+	}
+
+	// Логгер в памяти передает в panic тот же текст
+	memory, sink := NewMemory()
+	memory.AddHook(hook)
+	recovered = func() (value interface{}) {
+		defer func() { value = recover() }()
+		_ = memory.SetService("db").Panic("panic secret")
+		return nil
+	}()
+	if recovered != "panic [скрыто]" || !sink.Contains(PANIC, "panic [скрыто]") {
+		t.Errorf("логгер в памяти должен паниковать и писать текст после обработчиков: %v", recovered)
+	}
+}
@@ -0,0 +1,186 @@
+// http.go - HTTP API для чтения записей лога
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryHandler возвращает HTTP обработчик для чтения записей лога
+//
+// Принимает только GET запросы со следующими параметрами:
+//   - service: фильтр по сервису (можно указать несколько раз)
+//   - level: фильтр по точному уровню (trace, debug, info, warn, error, fatal, panic)
+//   - min_level: фильтр по уровню и более серьезным (игнорируется при заданном level)
+//   - limit: лимит количества записей (ограничивается DEFAULT_MAX_QUERY_LIMIT)
+//   - offset: количество пропускаемых записей
+//   - since, until: границы временного интервала в формате RFC3339
+//   - field.<имя>: точное совпадение значения дополнительного поля (например, field.user_id=12345)
+//   - contains: подстрока, которую должен содержать текст сообщения
+//
+// Ответ - JSON массив записей LogEntry.
+func (s *LogServer) QueryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, s.text(msgMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		filter, err := parseQueryFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := filter.Validate(); err != nil {
+			http.Error(w, s.text(msgFilterValidation, err), http.StatusBadRequest)
+			return
+		}
+
+		// Чтение прерывается по QueryTimeout и при отключении клиента HTTP
+		ctx, cancel := s.queryContext(r.Context())
+		defer cancel()
+
+		entries, err := s.getLogEntries(ctx, filter)
+		if err != nil {
+			http.Error(w, s.text(msgGetEntriesFailed, err), http.StatusInternalServerError)
+			return
+		}
+
+		// Пустой результат кодируем как [], а не null
+		if entries == nil {
+			entries = []LogEntry{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
+
+// parseQueryFilter преобразует параметры HTTP запроса в FilterOptions
+func parseQueryFilter(query url.Values) (FilterOptions, error) {
+	var filter FilterOptions
+
+	// Повторяющийся параметр service задает список сервисов
+	if services := query["service"]; len(services) > 1 {
+		filter.Services = services
+	} else {
+		filter.Service = query.Get("service")
+	}
+	filter.ExcludeServices = query["exclude_service"]
+
+	filter.Contains = query.Get("contains")
+
+	if v := query.Get("include_raw"); v != "" {
+		includeRaw, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("неверный параметр include_raw: %s", v)
+		}
+		filter.IncludeRaw = includeRaw
+	}
+
+	if v := query.Get("search_rotated"); v != "" {
+		searchRotated, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("неверный параметр search_rotated: %s", v)
+		}
+		filter.SearchRotated = searchRotated
+	}
+
+	if v := query.Get("level"); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Level = &level
+	}
+
+	if v := query.Get("min_level"); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.MinLevel = &level
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("неверный параметр limit: %s", v)
+		}
+		// Ограничиваем лимит тем же максимумом, что и FilterOptions.Validate
+		if limit > DEFAULT_MAX_QUERY_LIMIT {
+			limit = DEFAULT_MAX_QUERY_LIMIT
+		}
+		filter.Limit = limit
+	}
+
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("неверный параметр offset: %s", v)
+		}
+		filter.Offset = offset
+	}
+
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, "field.")
+		if !ok {
+			continue
+		}
+		if filter.FieldMatch == nil {
+			filter.FieldMatch = make(map[string]string)
+		}
+		filter.FieldMatch[name] = values[0]
+	}
+
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("неверный параметр since: %s", v)
+		}
+		filter.StartTime = &since
+	}
+
+	if v := query.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("неверный параметр until: %s", v)
+		}
+		filter.EndTime = &until
+	}
+
+	return filter, nil
+}
+
+// startHTTP запускает HTTP API на адресе из конфигурации
+func (s *LogServer) startHTTP() error {
+	listener, err := net.Listen("tcp", s.config.HTTPAddr)
+	if err != nil {
+		return fmt.Errorf("ошибка запуска HTTP API на %s: %w", s.config.HTTPAddr, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.QueryHandler(),
+		ReadHeaderTimeout: time.Duration(DEFAULT_CONNECTION_TIMEOUT) * time.Second,
+	}
+
+	s.mu.Lock()
+	s.httpServer = httpServer
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		_ = httpServer.Serve(listener)
+	}()
+
+	return nil
+}
@@ -0,0 +1,175 @@
+// http_test.go - Тесты для HTTP API чтения логов
+package logger
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newQueryTestServer создает сервер с несколькими записанными сообщениями
+func newQueryTestServer(t *testing.T) *LogServer {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Stop() })
+
+	now := time.Now()
+	messages := []LogMessage{
+		{Service: "API", Level: INFO, Message: "первое", Timestamp: now},
+		{Service: "DB", Level: ERROR, Message: "второе", Timestamp: now},
+		{Service: "API", Level: WARN, Message: "третье", Timestamp: now},
+		{Service: "API", Level: INFO, Message: "четвертое", Timestamp: now, Fields: map[string]string{"user_id": "42"}},
+	}
+	for _, msg := range messages {
+		server.writeMessage(msg)
+	}
+
+	return server
+}
+
+// TestQueryHandler проверяет фильтрацию записей через HTTP API
+func TestQueryHandler(t *testing.T) {
+	server := newQueryTestServer(t)
+	handler := server.QueryHandler()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"все записи", "", []string{"первое", "второе", "третье", "четвертое"}},
+		{"по сервису", "?service=API", []string{"первое", "третье", "четвертое"}},
+		{"по минимальному уровню", "?min_level=warn", []string{"второе", "третье"}},
+		{"по нескольким сервисам", "?service=API&service=DB&level=info", []string{"первое", "четвертое"}},
+		{"по уровню", "?level=error", []string{"второе"}},
+		{"лимит и смещение", "?service=API&offset=1&limit=1", []string{"третье"}},
+		{"лимит сверх максимума", "?limit=50000", []string{"первое", "второе", "третье", "четвертое"}},
+		{"по полю", "?field.user_id=42", []string{"четвертое"}},
+		{"по тексту", "?contains=рет", []string{"третье"}},
+		{"нет совпадений", "?service=NONE", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("ожидался код 200, получен %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var entries []LogEntry
+			if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+				t.Fatalf("не удалось разобрать ответ: %v", err)
+			}
+			if entries == nil {
+				t.Fatal("ответ должен быть JSON массивом, а не null")
+			}
+
+			if len(entries) != len(tt.expected) {
+				t.Fatalf("ожидалось %d записей, получено %d", len(tt.expected), len(entries))
+			}
+			for i, msg := range tt.expected {
+				if entries[i].Message != msg {
+					t.Errorf("запись %d: ожидалось %q, получено %q", i, msg, entries[i].Message)
+				}
+			}
+		})
+	}
+}
+
+// TestQueryHandlerErrors проверяет обработку некорректных запросов
+func TestQueryHandlerErrors(t *testing.T) {
+	server := newQueryTestServer(t)
+	handler := server.QueryHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		code   int
+	}{
+		{"метод POST", http.MethodPost, "", http.StatusMethodNotAllowed},
+		{"неверный уровень", http.MethodGet, "?level=verbose", http.StatusBadRequest},
+		{"неверный лимит", http.MethodGet, "?limit=abc", http.StatusBadRequest},
+		{"отрицательное смещение", http.MethodGet, "?offset=-1", http.StatusBadRequest},
+		{"неверное время", http.MethodGet, "?since=вчера", http.StatusBadRequest},
+		{"начало позже конца", http.MethodGet, "?since=2024-01-02T00:00:00Z&until=2024-01-01T00:00:00Z", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("ожидался код %d, получен %d", tt.code, rec.Code)
+			}
+		})
+	}
+}
+
+// TestQueryHTTPListener проверяет запуск HTTP API по адресу из конфигурации
+func TestQueryHTTPListener(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.HTTPAddr = "127.0.0.1:0"
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+
+	server.mu.RLock()
+	httpServer := server.httpServer
+	server.mu.RUnlock()
+	if httpServer == nil {
+		t.Fatal("HTTP API должен быть запущен при заданном HTTPAddr")
+	}
+
+	if err := server.Stop(); err != nil {
+		t.Fatalf("ошибка остановки сервера: %v", err)
+	}
+	if server.httpServer != nil {
+		t.Error("HTTP API должен быть остановлен")
+	}
+}
+
+// TestQueryHTTPListenerFailure проверяет, что при занятом адресе HTTP API сервер не остается запущенным
+func TestQueryHTTPListenerFailure(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("не удалось занять адрес: %v", err)
+	}
+	defer busy.Close()
+
+	config := createTestServerConfig(t)
+	config.HTTPAddr = busy.Addr().String()
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err == nil {
+		t.Fatal("ожидалась ошибка запуска HTTP API на занятом адресе")
+	}
+
+	if _, err := os.Stat(config.SocketPath); !os.IsNotExist(err) {
+		t.Errorf("сокет должен быть удален после неудачного запуска: %v", err)
+	}
+	if conn, err := net.Dial("unix", config.SocketPath); err == nil {
+		_ = conn.Close()
+		t.Error("сервер не должен принимать подключения после неудачного запуска")
+	}
+}
@@ -45,6 +45,9 @@ func createTestConfig(t *testing.T, socketPath string) (*LoggingConfig, func())
 		MaxFiles:      3,
 		BufferSize:    100,
 		FlushInterval: 5 * time.Second,
+
+		// Ноль означает переподключение без ограничения, тестам нужно ограничение
+		ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS,
 	}
 
 	return config, cleanup
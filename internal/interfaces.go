@@ -1,17 +1,42 @@
 // interfaces.go - Интерфейсы для тестирования
 package logger
 
-// LogClientInterface интерфейс для клиента логгера
-type LogClientInterface interface {
+import (
+	"context"
+	"io"
+)
+
+// ClientInterface методы логгера, доступные приложениям
+// Реализуется Logger и FakeClient: код, принимающий ClientInterface вместо
+// *Logger, в тестах получает FakeClient и не зависит от сервера и сокета.
+type ClientInterface interface {
 	SetService(service string) *ServiceLogger
 	SetLevel(level LogLevel)
 	SetServerLevel(level LogLevel) error
+	GetServerLevel() (LogLevel, error)
+	ServerCapabilities() (Capabilities, error)
+	GetServiceLevels() (map[string]LogLevel, error)
+	SetServiceLevel(service string, level LogLevel) error
+	ResetServiceLevel(service string) error
+	RegisterService(service string) error
 	GetLogFile() string
 	UpdateConfig(config *LoggingConfig) error
 	LogPanic()
 	GetLogEntries(filter FilterOptions) ([]LogEntry, error)
+	GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error)
+	StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error
+	ExportCSV(w io.Writer, filter FilterOptions) error
+	ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error
+	ExportNDJSON(w io.Writer, filter FilterOptions) error
+	GetRange(start, count int) ([]LogEntry, error)
+	GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error)
 	Ping() error
+	Health() (HealthStatus, error)
+	Flush() error
 	Close() error
+	CloseAndFlush() error
+	AddHook(hook MessageHook)
+	TruncatedFields() int64
 
 	// Методы логирования для MAIN сервиса
 	// Поддерживают различные форматы вызова:
@@ -19,13 +44,22 @@ type LogClientInterface interface {
 	// - Debug(message string, fields map[string]string) - сообщение с полями в виде карты
 	// - Debug(format string, args ...interface{}) - форматированное сообщение
 	// - Debug(message string, keyValues ...string) - сообщение с полями в виде пар ключ-значение
+	Trace(args ...interface{}) error
 	Debug(args ...interface{}) error
 	Info(args ...interface{}) error
 	Warn(args ...interface{}) error
 	Error(args ...interface{}) error
 	Fatal(args ...interface{}) error
 	Panic(args ...interface{}) error
+}
+
+// LogClientInterface интерфейс клиента, которому Logger передает вызовы
+type LogClientInterface interface {
+	ClientInterface
 
-	// Внутренний метод для отправки сообщений
+	// Внутренние методы для отправки сообщений, завершения программы после FATAL
+	// и паники после PANIC
 	sendMessage(service string, level LogLevel, message string, fields map[string]string) error
+	fatal(service string, message string, fields map[string]string) error
+	panic(service string, message string, fields map[string]string) error
 }
@@ -3,58 +3,132 @@ package logger
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 // LogLevel уровни логирования с числовыми значениями для быстрого сравнения
+// TRACE имеет значение -1, чтобы числовые значения остальных уровней
+// (они передаются по протоколу) не изменились.
 type LogLevel int
 
 const (
-	DEBUG LogLevel = iota // 0 - Отладочная информация
-	INFO                  // 1 - Информационные сообщения
-	WARN                  // 2 - Предупреждения
-	ERROR                 // 3 - Ошибки
-	FATAL                 // 4 - Критические ошибки
-	PANIC                 // 5 - Паника приложения
+	TRACE LogLevel = iota - 1 // -1 - Трассировка (дампы протокола и т.п.)
+	DEBUG                     // 0 - Отладочная информация
+	INFO                      // 1 - Информационные сообщения
+	WARN                      // 2 - Предупреждения
+	ERROR                     // 3 - Ошибки
+	FATAL                     // 4 - Критические ошибки
+	PANIC                     // 5 - Паника приложения
+	OFF                       // 6 - Логирование отключено (только порог, сообщений этого уровня нет)
 )
 
-// Кешированные строковые представления для производительности
+// Кешированные строковые представления для производительности (индекс - уровень минус TRACE)
 var levelNames = [...]string{
-	DEBUG: "DEBUG",
-	INFO:  "INFO",
-	WARN:  "WARN",
-	ERROR: "ERROR",
-	FATAL: "FATAL",
-	PANIC: "PANIC",
+	TRACE - TRACE: "TRACE",
+	DEBUG - TRACE: "DEBUG",
+	INFO - TRACE:  "INFO",
+	WARN - TRACE:  "WARN",
+	ERROR - TRACE: "ERROR",
+	FATAL - TRACE: "FATAL",
+	PANIC - TRACE: "PANIC",
+	OFF - TRACE:   "OFF",
 }
 
-// Мапа для быстрого поиска уровня по строке
+// levelPadWidth наибольшая ширина, для которой PaddedString не выделяет память
+const levelPadWidth = 16
+
+// Имена уровней, дополненные пробелами до levelPadWidth; PaddedString возвращает их префиксы
+var levelPaddedNames = func() (padded [len(levelNames)]string) {
+	for i, name := range levelNames {
+		padded[i] = name + strings.Repeat(" ", levelPadWidth-len(name))
+	}
+	return padded
+}()
+
+// Мапа для быстрого поиска уровня по строке (канонические имена и синонимы)
 var levelValues = map[string]LogLevel{
+	"TRACE": TRACE,
 	"DEBUG": DEBUG,
 	"INFO":  INFO,
 	"WARN":  WARN,
 	"ERROR": ERROR,
 	"FATAL": FATAL,
 	"PANIC": PANIC,
+	"OFF":   OFF,
+
+	// Синонимы из конфигураций других систем (в том числе syslog)
+	"DBG":           DEBUG,
+	"INFORMATION":   INFO,
+	"INFORMATIONAL": INFO,
+	"NOTICE":        INFO,
+	"WARNING":       WARN,
+	"ERR":           ERROR,
+	"CRIT":          FATAL,
+	"CRITICAL":      FATAL,
+	"ALERT":         PANIC,
+	"EMERG":         PANIC,
+	"EMERGENCY":     PANIC,
+	"NONE":          OFF,
 }
 
 // String возвращает строковое представление уровня (оптимизировано)
 func (l LogLevel) String() string {
-	if l >= 0 && int(l) < len(levelNames) {
-		return levelNames[l]
+	if i := int(l - TRACE); i >= 0 && i < len(levelNames) {
+		return levelNames[i]
 	}
 	return "UNKNOWN"
 }
 
+// PaddedString возвращает имя уровня, дополненное пробелами справа до width символов
+// Результат совпадает с fmt.Sprintf("%-*s", width, l.String()), но для ширины
+// до 16 символов берется из заранее подготовленной таблицы без выделения памяти.
+func (l LogLevel) PaddedString(width int) string {
+	i := int(l - TRACE)
+	if i < 0 || i >= len(levelNames) {
+		return padRight(l.String(), width)
+	}
+	name := levelNames[i]
+	if width <= len(name) {
+		return name
+	}
+	if width <= levelPadWidth {
+		return levelPaddedNames[i][:width]
+	}
+	return padRight(name, width)
+}
+
+// padRight дополняет строку пробелами справа до width байт
+func padRight(s string, width int) string {
+	if width <= len(s) {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
 // IsValid проверяет валидность уровня логирования
+// OFF допустим как порог уровня, но не как уровень сообщения (см. IsMessageLevel).
 func (l LogLevel) IsValid() bool {
-	return l >= DEBUG && l <= PANIC
+	return l >= TRACE && l <= OFF
+}
+
+// IsMessageLevel проверяет, что с этим уровнем можно записать сообщение
+func (l LogLevel) IsMessageLevel() bool {
+	return l >= TRACE && l <= PANIC
 }
 
 // ParseLevel парсит строковый уровень с улучшенной обработкой ошибок
+// Принимает без учета регистра канонические имена (trace, debug, info, warn,
+// error, fatal, panic, off), их синонимы (warning, err, informational,
+// critical, none и др.) и числовые значения LogLevel ("-1" - TRACE ... "6" - OFF).
+// При ошибке возвращает INFO.
 func ParseLevel(level string) (LogLevel, error) {
-	if l, ok := levelValues[strings.ToUpper(strings.TrimSpace(level))]; ok {
+	normalized := strings.ToUpper(strings.TrimSpace(level))
+	if l, ok := levelValues[normalized]; ok {
 		return l, nil
 	}
-	return INFO, fmt.Errorf("неизвестный уровень логирования: %s", level)
+	if n, err := strconv.Atoi(normalized); err == nil && LogLevel(n).IsValid() {
+		return LogLevel(n), nil
+	}
+	return INFO, fmt.Errorf("неизвестный уровень логирования: %s (допустимые значения: trace, debug, info, warn, error, fatal, panic, off, их синонимы warning, err, informational, critical, none и др. или число от %d до %d)", level, TRACE, OFF)
 }
@@ -2,7 +2,10 @@
 package logger
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestLogLevelString проверяет строковое представление уровней
@@ -11,6 +14,7 @@ func TestLogLevelString(t *testing.T) {
 		level    LogLevel
 		expected string
 	}{
+		{TRACE, "TRACE"},
 		{DEBUG, "DEBUG"},
 		{INFO, "INFO"},
 		{WARN, "WARN"},
@@ -18,7 +22,7 @@ func TestLogLevelString(t *testing.T) {
 		{FATAL, "FATAL"},
 		{PANIC, "PANIC"},
 		{LogLevel(999), "UNKNOWN"}, // Неизвестный уровень
-		{LogLevel(-1), "UNKNOWN"},  // Отрицательный уровень
+		{LogLevel(-2), "UNKNOWN"},  // Уровень ниже TRACE
 	}
 
 	for _, tt := range tests {
@@ -31,12 +35,33 @@ func TestLogLevelString(t *testing.T) {
 	}
 }
 
+// TestLogLevelPaddedString проверяет выравнивание имени уровня
+func TestLogLevelPaddedString(t *testing.T) {
+	levels := []LogLevel{TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC, OFF, LogLevel(99)}
+	for _, level := range levels {
+		for _, width := range []int{0, 3, 5, 8, levelPadWidth, levelPadWidth + 4} {
+			want := fmt.Sprintf("%-*s", width, level.String())
+			if got := level.PaddedString(width); got != want {
+				t.Errorf("%v.PaddedString(%d) = %q, ожидалось %q", level, width, got, want)
+			}
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = WARN.PaddedString(5)
+	})
+	if allocs != 0 {
+		t.Errorf("PaddedString выделяет память: %v", allocs)
+	}
+}
+
 // TestLogLevelIsValid проверяет валидацию уровней
 func TestLogLevelIsValid(t *testing.T) {
 	tests := []struct {
 		level LogLevel
 		valid bool
 	}{
+		{TRACE, true},
 		{DEBUG, true},
 		{INFO, true},
 		{WARN, true},
@@ -44,8 +69,9 @@ func TestLogLevelIsValid(t *testing.T) {
 		{FATAL, true},
 		{PANIC, true},
 		{LogLevel(999), false}, // Неизвестный уровень
-		{LogLevel(-1), false},  // Отрицательный уровень
-		{LogLevel(6), false},   // Уровень за пределами диапазона
+		{LogLevel(-2), false},  // Уровень ниже TRACE
+		{OFF, true},            // OFF допустим как порог
+		{LogLevel(7), false},   // Уровень за пределами диапазона
 	}
 
 	for _, tt := range tests {
@@ -77,7 +103,8 @@ func TestParseLevel(t *testing.T) {
 		{"  WARN  ", WARN, false}, // Пробелы должны обрезаться
 		{"UNKNOWN", INFO, true},   // Неизвестный уровень -> ошибка, возврат INFO
 		{"", INFO, true},          // Пустая строка -> ошибка, возврат INFO
-		{"123", INFO, true},       // Числовая строка -> ошибка, возврат INFO
+		{"123", INFO, true},       // Число вне диапазона -> ошибка, возврат INFO
+		{"-2", INFO, true},        // Число ниже TRACE -> ошибка, возврат INFO
 	}
 
 	for _, tt := range tests {
@@ -104,6 +131,88 @@ func TestParseLevel(t *testing.T) {
 	}
 }
 
+// TestParseLevelAliases проверяет синонимы и числовые значения уровней
+func TestParseLevelAliases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected LogLevel
+	}{
+		{"trace", TRACE},
+		{"-1", TRACE},
+		{"dbg", DEBUG},
+		{"information", INFO},
+		{"informational", INFO},
+		{"notice", INFO},
+		{"warning", WARN},
+		{"WARNING", WARN},
+		{"err", ERROR},
+		{"crit", FATAL},
+		{"critical", FATAL},
+		{"alert", PANIC},
+		{"emerg", PANIC},
+		{"Emergency", PANIC},
+		{"0", DEBUG},
+		{"1", INFO},
+		{"2", WARN},
+		{"3", ERROR},
+		{"4", FATAL},
+		{" 5 ", PANIC},
+		{"off", OFF},
+		{"none", OFF},
+		{"6", OFF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if level != tt.expected {
+				t.Errorf("ParseLevel(%q) = %v, ожидалось %v", tt.input, level, tt.expected)
+			}
+		})
+	}
+
+	// Ошибка должна перечислять допустимые значения
+	_, err := ParseLevel("verbose")
+	if err == nil || !strings.Contains(err.Error(), "warning") || !strings.Contains(err.Error(), "от -1 до 6") {
+		t.Errorf("ошибка должна перечислять допустимые значения: %v", err)
+	}
+}
+
+// TestLogLevelRoundTrip проверяет, что String() выдает ровно то, что принимает ParseLevel,
+// и что записи любого уровня читаются обратно из файла лога
+func TestLogLevelRoundTrip(t *testing.T) {
+	server := &LogServer{maxServiceLen: 4, maxLevelLen: 5}
+
+	for level := TRACE; level <= PANIC; level++ {
+		t.Run(level.String(), func(t *testing.T) {
+			parsed, err := ParseLevel(level.String())
+			if err != nil {
+				t.Fatalf("ParseLevel(%q) вернул ошибку: %v", level.String(), err)
+			}
+			if parsed != level {
+				t.Errorf("ParseLevel(%q) = %v, ожидалось %v", level.String(), parsed, level)
+			}
+
+			line := server.formatMessageAsTXT(LogMessage{
+				Service:   "TEST",
+				Level:     level,
+				Message:   "сообщение",
+				Timestamp: time.Now(),
+			})
+			entry, err := server.parseLogEntry(line)
+			if err != nil {
+				t.Fatalf("запись уровня %s не читается из файла: %v", level, err)
+			}
+			if entry.Level != level {
+				t.Errorf("уровень записи после чтения %v, ожидалось %v", entry.Level, level)
+			}
+		})
+	}
+}
+
 // TestLogLevelComparison проверяет сравнение уровней
 func TestLogLevelComparison(t *testing.T) {
 	tests := []struct {
@@ -209,6 +318,25 @@ func BenchmarkLogLevelString(b *testing.B) {
 	}
 }
 
+// BenchmarkLogLevelPaddedString сравнивает PaddedString с выравниванием через fmt
+func BenchmarkLogLevelPaddedString(b *testing.B) {
+	level := INFO
+
+	b.Run("Sprintf", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = fmt.Sprintf("%-*s", 5, level.String())
+		}
+	})
+
+	b.Run("PaddedString", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = level.PaddedString(5)
+		}
+	})
+}
+
 // BenchmarkParseLevel бенчмарк для парсинга уровня
 func BenchmarkParseLevel(b *testing.B) {
 	b.ResetTimer()
@@ -0,0 +1,466 @@
+// local.go - Локальный режим: запись в файл лога без сокета
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// localClientID идентификатор клиента локального режима для ограничителя скорости
+const localClientID = "local"
+
+// localClient клиент, передающий сообщения серверу в том же процессе без сокета
+// Сообщения проходят ту же проверку, буфер, пакетную запись и ротацию, что и
+// сообщения по сокету, а запросы записей читают файл лога напрямую.
+// Клиент владеет сервером и останавливает его при закрытии.
+type localClient struct {
+	server *LogServer
+
+	mu             sync.RWMutex
+	level          LogLevel                  // Локальный уровень логирования
+	serviceLoggers map[string]*ServiceLogger // Кеш логгеров сервисов
+	hooks          []MessageHook             // Обработчики сообщений перед записью
+	closed         atomic.Bool               // Клиент закрыт, сервер остановлен
+}
+
+var _ LogClientInterface = (*localClient)(nil)
+
+// newLocal создает логгер локального режима: сервер без сокета и клиент к нему
+func newLocal(config *LoggingConfig) (*Logger, error) {
+	server, err := newLogServer(config, nil, nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := server.Start(); err != nil {
+		_ = server.Stop()
+		return nil, err
+	}
+
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		level = INFO
+	}
+
+	client := &localClient{
+		server:         server,
+		level:          level,
+		serviceLoggers: make(map[string]*ServiceLogger),
+	}
+	return &Logger{client: client, server: server}, nil
+}
+
+// localError сопоставляет ошибку чтения лога с ошибкой клиента, как это делает сервер
+// Результат проверяется через errors.Is и как ошибка клиента (например,
+// ErrLogNotFound), и как исходная ошибка.
+func localError(err error) error {
+	return fmt.Errorf("%w: %w", errorCodeErrors[errorCodeFor(err)], err)
+}
+
+// SetService возвращает логгер для указанного сервиса (с кешированием)
+func (c *localClient) SetService(service string) *ServiceLogger {
+	service = NormalizeServiceName(service)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if serviceLogger, exists := c.serviceLoggers[service]; exists {
+		return serviceLogger
+	}
+	serviceLogger := newServiceLoggerWithRules(c, service, c.server.securityConfig)
+	c.serviceLoggers[service] = serviceLogger
+	return serviceLogger
+}
+
+func (c *localClient) SetLevel(level LogLevel) {
+	c.mu.Lock()
+	c.level = level
+	c.mu.Unlock()
+}
+
+// SetServerLevel устанавливает общий уровень, которым сервер фильтрует сообщения
+func (c *localClient) SetServerLevel(level LogLevel) error {
+	if !level.IsValid() {
+		return fmt.Errorf("недопустимый уровень логирования: %d", level)
+	}
+	c.server.setLevel(level)
+	return nil
+}
+
+func (c *localClient) GetServerLevel() (LogLevel, error) {
+	c.server.levelsMu.RLock()
+	defer c.server.levelsMu.RUnlock()
+	return c.server.minLevel, nil
+}
+
+// ServerCapabilities возвращает возможности встроенного сервера: доступны все запросы
+func (c *localClient) ServerCapabilities() (Capabilities, error) {
+	return Capabilities{
+		ProtocolVersion: PROTOCOL_VERSION,
+		MessageTypes:    supportedMessageTypes,
+		MaxMessageSize:  c.server.maxMessageSize,
+	}, nil
+}
+
+func (c *localClient) GetServiceLevels() (map[string]LogLevel, error) {
+	c.server.levelsMu.RLock()
+	defer c.server.levelsMu.RUnlock()
+
+	levels := maps.Clone(c.server.serviceLevels)
+	if levels == nil {
+		levels = make(map[string]LogLevel)
+	}
+	return levels, nil
+}
+
+func (c *localClient) SetServiceLevel(service string, level LogLevel) error {
+	if !level.IsValid() {
+		return fmt.Errorf("недопустимый уровень логирования: %d", level)
+	}
+	return c.setServiceLevel(service, &level)
+}
+
+func (c *localClient) ResetServiceLevel(service string) error {
+	return c.setServiceLevel(service, nil)
+}
+
+// RegisterService добавляет сервис в список разрешенных сервера
+func (c *localClient) RegisterService(service string) error {
+	service, err := checkServiceName(service, c.server.securityConfig)
+	if err != nil {
+		return err
+	}
+	c.server.registerService(service)
+	return nil
+}
+
+// setServiceLevel проверяет имя сервиса и передает уровень серверу
+func (c *localClient) setServiceLevel(service string, level *LogLevel) error {
+	service = NormalizeServiceName(service)
+	if err := ValidateServiceName(service, c.server.securityConfig); err != nil {
+		return fmt.Errorf("%w: недопустимое имя сервиса: %w", ErrInvalidRequest, err)
+	}
+	c.server.setServiceLevel(service, level)
+	return nil
+}
+
+// levelFor возвращает уровень для сервиса: заданный для него на сервере или локальный
+func (c *localClient) levelFor(service string) LogLevel {
+	if level, ok := c.server.serviceLevel(service); ok {
+		return level
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.level
+}
+
+func (c *localClient) GetLogFile() string {
+	return c.server.config.LogFile
+}
+
+// UpdateConfig применяет конфигурацию к встроенному серверу (см. LogServer.Reload)
+func (c *localClient) UpdateConfig(config *LoggingConfig) error {
+	if config == nil {
+		return fmt.Errorf("конфигурация не может быть nil")
+	}
+	if err := c.server.Reload(config); err != nil {
+		return err
+	}
+
+	if level, err := ParseLevel(config.Level); err == nil {
+		c.SetLevel(level)
+	}
+	return nil
+}
+
+// LogPanic записывает перехваченную панику и перебрасывает ее
+func (c *localClient) LogPanic() {
+	if r := recover(); r != nil {
+		_ = c.sendMessage("MAIN", PANIC, fmt.Sprintf("Восстановлено после паники: %v", r), nil)
+		panic(r)
+	}
+}
+
+func (c *localClient) GetLogEntries(filter FilterOptions) ([]LogEntry, error) {
+	return c.GetLogEntriesContext(context.Background(), filter)
+}
+
+// GetLogEntriesContext читает записи из файла лога, ограничивая время чтения ctx и QueryTimeout
+func (c *localClient) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
+	if c.closed.Load() {
+		return nil, ErrClosed
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+
+	ctx, cancel := c.server.queryContext(ctx)
+	defer cancel()
+
+	entries, err := c.server.getLogEntries(ctx, filter)
+	if err != nil {
+		return nil, localError(err)
+	}
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+	return entries, nil
+}
+
+func (c *localClient) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	if fn == nil {
+		return fmt.Errorf("не задан обработчик записей")
+	}
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+
+	if err := c.server.eachLogEntry(context.Background(), filter, fn); err != nil {
+		return localError(err)
+	}
+	return nil
+}
+
+func (c *localClient) ExportCSV(w io.Writer, filter FilterOptions) error {
+	return c.ExportCSVWithOptions(w, filter, CSVOptions{})
+}
+
+func (c *localClient) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeCSV(w, options, func(fn func(LogEntry) bool) error {
+		return c.StreamLogEntries(filter, fn)
+	})
+}
+
+func (c *localClient) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeNDJSON(w, func(fn func(LogEntry) bool) error {
+		return c.StreamLogEntries(filter, fn)
+	})
+}
+
+// GetRange читает записи из диапазона строк файла лога (нумерация с 1)
+func (c *localClient) GetRange(start, count int) ([]LogEntry, error) {
+	return c.GetFilteredRange(start, count, FilterOptions{})
+}
+
+func (c *localClient) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	if c.closed.Load() {
+		return nil, ErrClosed
+	}
+	req := RangeRequest{Start: start, Count: count, Filter: filter}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+
+	entries, err := c.server.getLogRange(req)
+	if err != nil {
+		return nil, localError(err)
+	}
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+	return entries, nil
+}
+
+// Ping проверяет, что клиент не закрыт: соединения с сервером нет
+func (c *localClient) Ping() error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (c *localClient) Health() (HealthStatus, error) {
+	if c.closed.Load() {
+		return HealthStatus{}, ErrClosed
+	}
+	return c.server.Health(), nil
+}
+
+// Flush дожидается записи на диск всех сообщений, принятых до вызова
+func (c *localClient) Flush() error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	return c.server.flushQueued(time.Duration(DEFAULT_CONNECTION_TIMEOUT) * time.Second)
+}
+
+// Close останавливает сервер, записав принятые сообщения; повторный вызов безопасен
+// После закрытия сообщения выводятся в stderr, а запись и запросы возвращают ErrClosed.
+func (c *localClient) Close() error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+
+	// Stop закрывает файл до разбора буфера, поэтому принятые сообщения записываются заранее
+	err := c.server.flushQueued(time.Duration(DEFAULT_CONNECTION_TIMEOUT) * time.Second)
+	if stopErr := c.server.Stop(); err == nil {
+		err = stopErr
+	}
+	return err
+}
+
+func (c *localClient) CloseAndFlush() error {
+	err := c.Flush()
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *localClient) AddHook(hook MessageHook) {
+	if hook == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Новый срез вместо append на месте: sendMessage применяет прежний вне блокировки
+	hooks := make([]MessageHook, len(c.hooks), len(c.hooks)+1)
+	copy(hooks, c.hooks)
+	c.hooks = append(hooks, hook)
+}
+
+// TruncatedFields всегда 0: сообщения не передаются кадрами протокола
+func (c *localClient) TruncatedFields() int64 { return 0 }
+
+func (c *localClient) Trace(args ...interface{}) error { return c.log(TRACE, args) }
+func (c *localClient) Debug(args ...interface{}) error { return c.log(DEBUG, args) }
+func (c *localClient) Info(args ...interface{}) error  { return c.log(INFO, args) }
+func (c *localClient) Warn(args ...interface{}) error  { return c.log(WARN, args) }
+func (c *localClient) Error(args ...interface{}) error { return c.log(ERROR, args) }
+
+// Fatal записывает сообщение в файл и stderr и завершает программу
+func (c *localClient) Fatal(args ...interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("отсутствуют аргументы")
+	}
+	message, fields := processArgs(args...)
+	return c.fatal("MAIN", message, fields)
+}
+
+// fatal записывает сообщение FATAL сервиса в файл и stderr и завершает программу
+func (c *localClient) fatal(service string, message string, fields map[string]string) error {
+	c.terminal(service, FATAL, message, fields)
+	exitFunc(1)
+	return nil
+}
+
+// Panic записывает сообщение в файл и stderr и вызывает панику
+func (c *localClient) Panic(args ...interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("отсутствуют аргументы")
+	}
+	message, fields := processArgs(args...)
+	return c.panic("MAIN", message, fields)
+}
+
+// panic записывает сообщение PANIC сервиса в файл и stderr и вызывает панику
+// с текстом после обработчиков
+func (c *localClient) panic(service string, message string, fields map[string]string) error {
+	panic(c.terminal(service, PANIC, message, fields))
+}
+
+// terminal записывает сообщение FATAL или PANIC в stderr и файл после обработчиков
+// и возвращает итоговый текст сообщения
+func (c *localClient) terminal(service string, level LogLevel, message string, fields map[string]string) string {
+	msg := c.newMessage(service, level, message, fields)
+	hooked := c.runHooks(msg)
+
+	// Сервер вернет сообщение в пул после записи, поэтому текст сохраняется заранее
+	text := msg.Message
+	if !hooked {
+		PutLogMessage(msg)
+		return text
+	}
+
+	writeToStderr(msg.Service, msg.Level, msg.Message, msg.Timestamp, msg.Fields)
+	if !c.accepts(service, level) || c.closed.Load() {
+		PutLogMessage(msg)
+		return text
+	}
+	_ = c.deliver(msg)
+	return text
+}
+
+// log записывает сообщение сервиса MAIN
+func (c *localClient) log(level LogLevel, args []interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("отсутствуют аргументы")
+	}
+	message, fields := processArgs(args...)
+	return c.sendMessage("MAIN", level, message, fields)
+}
+
+// sendMessage проверяет уровень, применяет обработчики и передает сообщение серверу
+// Сообщения FATAL и PANIC записываются на диск до возврата: после них программа завершается.
+func (c *localClient) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	if !c.accepts(service, level) {
+		return nil
+	}
+
+	if c.closed.Load() {
+		writeToStderr(service, level, message, c.server.now(), fields)
+		return ErrClosed
+	}
+
+	msg := c.newMessage(service, level, message, fields)
+	if !c.runHooks(msg) {
+		PutLogMessage(msg)
+		return nil
+	}
+	return c.deliver(msg)
+}
+
+// accepts сообщает, проходит ли сообщение уровень сервиса (OFF - не проходит ничего)
+func (c *localClient) accepts(service string, level LogLevel) bool {
+	threshold := c.levelFor(service)
+	return threshold != OFF && level >= threshold && level.IsMessageLevel()
+}
+
+// newMessage создает сообщение из пула
+// Сообщение ставится в буфер сервера и записывается позже, поэтому поля копируются
+func (c *localClient) newMessage(service string, level LogLevel, message string, fields map[string]string) *LogMessage {
+	msg := GetLogMessage()
+	msg.Service = service
+	msg.Level = level
+	msg.Message = message
+	msg.Timestamp = c.server.now()
+	msg.Fields = maps.Clone(fields)
+	return msg
+}
+
+// runHooks применяет обработчики клиента к сообщению
+func (c *localClient) runHooks(msg *LogMessage) bool {
+	c.mu.RLock()
+	hooks := c.hooks
+	c.mu.RUnlock()
+
+	return applyHooks(hooks, msg)
+}
+
+// deliver передает серверу сообщение, уже обработанное обработчиками
+func (c *localClient) deliver(msg *LogMessage) error {
+	level := msg.Level
+	if !c.server.acceptMessage(msg, localClientID) {
+		return ErrRateLimited
+	}
+	if level >= FATAL {
+		return c.server.flushQueued(time.Duration(DEFAULT_CONNECTION_TIMEOUT) * time.Second)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestLocalLogger проверяет запись в файл и чтение записей без сокета
+ * @param t *testing.T - тестовый контекст
+ */
+func TestLocalLogger(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.SocketPath = ""
+
+	log, err := New(config, []string{"API"})
+	if err != nil {
+		t.Fatalf("New без SocketPath вернул ошибку: %v", err)
+	}
+	defer log.Close()
+
+	if _, ok := log.client.(*localClient); !ok {
+		t.Fatalf("ожидался клиент локального режима, получили %T", log.client)
+	}
+
+	_ = log.Debug("отладка ниже уровня")
+	_ = log.Info("запуск %s", "службы")
+	_ = log.SetService("api").Warn("медленный запрос", "path", "/users")
+	if err := log.Flush(); err != nil {
+		t.Fatalf("Flush вернул ошибку: %v", err)
+	}
+
+	data, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл лога: %v", err)
+	}
+	if !strings.Contains(string(data), "запуск службы") || strings.Contains(string(data), "отладка ниже уровня") {
+		t.Errorf("неожиданное содержимое файла лога:\n%s", data)
+	}
+
+	entries, err := log.GetLogEntries(FilterOptions{Service: "API"})
+	if err != nil || len(entries) != 1 || entries[0].Fields["path"] != "/users" {
+		t.Errorf("ожидалась одна запись API с полем path, получили %+v, %v", entries, err)
+	}
+
+	entries, err = log.GetRange(1, 100)
+	if err != nil || len(entries) == 0 {
+		t.Errorf("ожидались записи диапазона, получили %+v, %v", entries, err)
+	}
+
+	if status, err := log.Health(); err != nil || !status.Writable {
+		t.Errorf("ожидался доступный для записи лог, получили %+v, %v", status, err)
+	}
+}
+
+/**
+ * TestLocalLoggerLevels проверяет уровни сервисов и общий уровень в локальном режиме
+ * @param t *testing.T - тестовый контекст
+ */
+func TestLocalLoggerLevels(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Mode = ModeLocal
+
+	log, err := New(config, nil)
+	if err != nil {
+		t.Fatalf("New в режиме ModeLocal вернул ошибку: %v", err)
+	}
+	defer log.Close()
+
+	// SocketPath задан, но в локальном режиме сокет не создается
+	if _, err := os.Stat(config.SocketPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("сокет не должен создаваться в локальном режиме: %v", err)
+	}
+
+	if err := log.SetServiceLevel("db", DEBUG); err != nil {
+		t.Fatalf("SetServiceLevel вернул ошибку: %v", err)
+	}
+	if err := log.SetServiceLevel("bad name!", DEBUG); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("ожидалась ошибка ErrInvalidRequest для недопустимого имени, получили %v", err)
+	}
+	_ = log.SetService("db").Debug("отладка базы")
+	_ = log.Debug("отладка MAIN")
+	_ = log.Flush()
+
+	if entries, _ := log.GetLogEntries(FilterOptions{Contains: "отладка"}); len(entries) != 1 || entries[0].Service != "DB" {
+		t.Errorf("ожидалась только отладка DB, получили %+v", entries)
+	}
+
+	levels, err := log.GetServiceLevels()
+	if err != nil || levels["DB"] != DEBUG {
+		t.Errorf("ожидался уровень DEBUG для DB, получили %v, %v", levels, err)
+	}
+
+	if err := log.SetServerLevel(ERROR); err != nil {
+		t.Fatalf("SetServerLevel вернул ошибку: %v", err)
+	}
+	if level, err := log.GetServerLevel(); err != nil || level != ERROR {
+		t.Errorf("ожидался уровень сервера ERROR, получили %v, %v", level, err)
+	}
+}
+
+/**
+ * TestLocalLoggerClose проверяет закрытие: сообщения записаны, дальнейшая запись возвращает ErrClosed
+ * @param t *testing.T - тестовый контекст
+ */
+func TestLocalLoggerClose(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.SocketPath = ""
+
+	log, err := New(config, nil)
+	if err != nil {
+		t.Fatalf("New вернул ошибку: %v", err)
+	}
+
+	_ = log.Info("перед закрытием")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close вернул ошибку: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("повторный Close вернул ошибку: %v", err)
+	}
+
+	data, err := os.ReadFile(config.LogFile)
+	if err != nil || !strings.Contains(string(data), "перед закрытием") {
+		t.Errorf("сообщение должно быть записано при закрытии: %q, %v", data, err)
+	}
+
+	if err := log.Ping(); !errors.Is(err, ErrClosed) {
+		t.Errorf("ожидалась ошибка ErrClosed после закрытия, получили %v", err)
+	}
+	if _, err := log.GetLogEntries(FilterOptions{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("ожидалась ошибка ErrClosed для запроса после закрытия, получили %v", err)
+	}
+}
+
+/**
+ * TestNewInvalidMode проверяет отклонение неизвестного режима и режим сервера без сокета
+ * @param t *testing.T - тестовый контекст
+ */
+func TestNewInvalidMode(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Mode = "embedded"
+	if _, err := New(config, nil); err == nil {
+		t.Error("ожидалась ошибка для неизвестного режима")
+	}
+
+	config.Mode = ModeServer
+	config.SocketPath = ""
+	if _, err := New(config, nil); err == nil {
+		t.Error("режим ModeServer без SocketPath должен возвращать ошибку")
+	}
+}
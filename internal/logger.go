@@ -2,13 +2,17 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"time"
 )
 
-var _ API = (*Logger)(nil) // Убедимся, что Logger соответствует интерфейсу API
+var _ API = (*Logger)(nil)             // Убедимся, что Logger соответствует интерфейсу API
+var _ ClientInterface = (*Logger)(nil) // и может передаваться как ClientInterface
 
 // Logger основная структура логгера для клиентских приложений
 type Logger struct {
@@ -17,30 +21,19 @@ type Logger struct {
 }
 
 // New создает новый экземпляр логгера для клиентского приложения
+// В режиме ModeLocal (а также при пустых Mode и SocketPath) сокет не создается:
+// сообщения записываются в файл лога в том же процессе, с той же проверкой,
+// пакетной записью и ротацией, а запросы записей читают файл напрямую.
 func New(config *LoggingConfig, services []string) (*Logger, error) {
 	if config == nil {
 		return nil, fmt.Errorf("конфигурация не может быть nil")
 	}
-
-	// 1. Запускаем демон логгера
-	loggerServer, err := NewLogServer(config)
-	if err != nil {
-		return nil, err
+	if !config.Mode.IsValid() {
+		return nil, fmt.Errorf("неизвестный режим логгера: %q", config.Mode)
 	}
 
-	if err := loggerServer.Start(); err != nil {
-		return nil, err
-	}
-
-	// Ждем готовности сокета (до 5 секунд)
-	if err := waitForSocket(config.SocketPath, 5*time.Second); err != nil {
-		return nil, err
-	}
-
-	// Дополнительная задержка для готовности обработчика соединений
-	time.Sleep(50 * time.Millisecond)
-
-	// Добавляем переданные сервисы к конфигурационным
+	// Добавляем переданные сервисы к конфигурационным до создания сервера:
+	// по ним рассчитывается ширина колонки сервиса
 	if len(services) > 0 {
 		allServices := make(map[string]bool)
 
@@ -61,6 +54,28 @@ func New(config *LoggingConfig, services []string) (*Logger, error) {
 		}
 	}
 
+	if config.Mode == ModeLocal || config.Mode == "" && config.SocketPath == "" {
+		return newLocal(config)
+	}
+
+	// 1. Запускаем демон логгера
+	loggerServer, err := NewLogServer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loggerServer.Start(); err != nil {
+		return nil, err
+	}
+
+	// Ждем готовности сокета (до 5 секунд)
+	if err := waitForSocket(config.SocketPath, 5*time.Second); err != nil {
+		return nil, err
+	}
+
+	// Дополнительная задержка для готовности обработчика соединений
+	time.Sleep(50 * time.Millisecond)
+
 	// Создаем клиент
 	client, err := NewLogClient(config)
 	if err != nil {
@@ -73,11 +88,182 @@ func New(config *LoggingConfig, services []string) (*Logger, error) {
 	}, nil
 }
 
+// Connect создает логгер, подключенный к уже запущенному серверу
+// В отличие от New сервер не запускается. Подходит, когда сервер работает
+// в отдельном процессе, и для утилит чтения логов.
+func Connect(config *LoggingConfig) (*Logger, error) {
+	if config == nil {
+		return nil, fmt.Errorf("конфигурация не может быть nil")
+	}
+
+	client, err := NewLogClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{client: client}, nil
+}
+
+// NewWithClient создает логгер, передающий вызовы клиенту client
+// Позволяет подставить FakeClient в тестах или собственную реализацию
+// ClientInterface (например, с другим транспортом). Логгеры сервисов из services
+// создаются сразу, поэтому недопустимые имена сообщаются при создании. Сервер
+// не запускается, Close закрывает client. При client == nil логгер ничего не записывает, как NewNop.
+// Собственная реализация получает сообщения сервисов через MessageSender, если
+// реализует его, иначе - через логгер, который возвращает ее SetService.
+func NewWithClient(client ClientInterface, services ...string) *Logger {
+	var inner LogClientInterface
+	switch c := client.(type) {
+	case nil:
+		inner = nopClient{}
+	case *Logger:
+		// Сервер остается у исходного логгера, новый логгер использует только его клиент
+		inner = c.client
+	case LogClientInterface:
+		inner = c
+	default:
+		inner = clientAdapter{c}
+	}
+
+	for _, service := range services {
+		inner.SetService(service)
+	}
+	return &Logger{client: inner}
+}
+
+// leveledLogger методы записи по уровням, общие для ClientInterface и ServiceLogger
+type leveledLogger interface {
+	Trace(args ...interface{}) error
+	Debug(args ...interface{}) error
+	Info(args ...interface{}) error
+	Warn(args ...interface{}) error
+	Error(args ...interface{}) error
+	Fatal(args ...interface{}) error
+	Panic(args ...interface{}) error
+}
+
+// clientAdapter дополняет внешнюю реализацию ClientInterface внутренним методом sendMessage
+type clientAdapter struct {
+	ClientInterface
+}
+
+// errNoServiceSender возвращается, если сообщение сервиса некуда передать без рекурсии
+var errNoServiceSender = errors.New("клиент не передает сообщения сервисов: реализуйте MessageSender и возвращайте NewServiceLogger(client, service) из SetService")
+
+// fatal передает сообщение FATAL сервиса клиенту
+// Реализация MessageSender только доставляет сообщение, поэтому программу
+// завершает адаптер; иначе завершение остается за Fatal логгера сервиса клиента.
+func (a clientAdapter) fatal(service string, message string, fields map[string]string) error {
+	if sender, ok := a.ClientInterface.(MessageSender); ok {
+		_ = sender.SendMessage(service, FATAL, message, fields)
+		exitFunc(1)
+		return nil
+	}
+	return a.sendMessage(service, FATAL, message, fields)
+}
+
+// panic передает сообщение PANIC сервиса клиенту
+// Логгер сервиса клиента сам вызывает панику; паника адаптера - страховка,
+// если он вернул управление.
+func (a clientAdapter) panic(service string, message string, fields map[string]string) error {
+	if sender, ok := a.ClientInterface.(MessageSender); ok {
+		_ = sender.SendMessage(service, PANIC, message, fields)
+		panic(message)
+	}
+	_ = a.sendMessage(service, PANIC, message, fields)
+	panic(message)
+}
+
+// SetService возвращает логгер сервиса, пишущий через адаптер
+// SetService клиента здесь не вызывается: реализация, возвращающая
+// NewWithClient(client).SetService(service), иначе вызывала бы себя бесконечно.
+func (a clientAdapter) SetService(service string) *ServiceLogger {
+	return newServiceLogger(a, service)
+}
+
+// sendMessage передает сообщение клиенту: реализации MessageSender - в SendMessage,
+// иначе методу его уровня: сервиса MAIN - самому клиенту, остальных сервисов -
+// логгеру, который возвращает SetService клиента
+func (a clientAdapter) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	if sender, ok := a.ClientInterface.(MessageSender); ok {
+		return sender.SendMessage(service, level, message, fields)
+	}
+
+	args := []interface{}{message}
+	if len(fields) > 0 {
+		args = append(args, fields)
+	}
+
+	var target leveledLogger = a.ClientInterface
+	if service != "MAIN" {
+		// Логгер без клиента или снова через адаптер не доставит сообщение клиенту
+		serviceLogger := a.ClientInterface.SetService(service)
+		if serviceLogger == nil || serviceLogger.client == nil {
+			return errNoServiceSender
+		}
+		if _, ok := serviceLogger.client.(clientAdapter); ok {
+			return errNoServiceSender
+		}
+		target = serviceLogger
+	}
+
+	switch level {
+	case TRACE:
+		return target.Trace(args...)
+	case DEBUG:
+		return target.Debug(args...)
+	case INFO:
+		return target.Info(args...)
+	case WARN:
+		return target.Warn(args...)
+	case ERROR:
+		return target.Error(args...)
+	case FATAL:
+		return target.Fatal(args...)
+	case PANIC:
+		return target.Panic(args...)
+	}
+	return fmt.Errorf("недопустимый уровень сообщения: %v", level)
+}
+
 // SetService возвращает логгер для указанного сервиса
 func (l *Logger) SetService(service string) *ServiceLogger {
 	return l.client.SetService(service)
 }
 
+// ServerCapabilities возвращает версию протокола сервера и поддерживаемые типы сообщений
+func (l *Logger) ServerCapabilities() (Capabilities, error) {
+	return l.client.ServerCapabilities()
+}
+
+// GetServerLevel возвращает общий уровень логирования, действующий на сервере
+func (l *Logger) GetServerLevel() (LogLevel, error) {
+	return l.client.GetServerLevel()
+}
+
+// GetServiceLevels возвращает уровни сервисов, действующие на сервере
+func (l *Logger) GetServiceLevels() (map[string]LogLevel, error) {
+	return l.client.GetServiceLevels()
+}
+
+// SetServiceLevel устанавливает уровень логирования для отдельного сервиса
+func (l *Logger) SetServiceLevel(service string, level LogLevel) error {
+	return l.client.SetServiceLevel(service, level)
+}
+
+// ResetServiceLevel возвращает сервис к общему уровню логирования
+func (l *Logger) ResetServiceLevel(service string) error {
+	return l.client.ResetServiceLevel(service)
+}
+
+// RegisterService регистрирует сервис, появившийся после создания логгера
+// Сервис добавляется в список разрешенных сервера: без регистрации при
+// RestrictServices сообщения SetService("NEW") отбрасываются сервером. Ширина
+// колонки сервиса в файле лога пересчитывается с учетом нового имени.
+func (l *Logger) RegisterService(service string) error {
+	return l.client.RegisterService(service)
+}
+
 // SetLevel устанавливает локальный уровень логирования
 func (l *Logger) SetLevel(level LogLevel) {
 	l.client.SetLevel(level)
@@ -88,6 +274,17 @@ func (l *Logger) SetServerLevel(level LogLevel) error {
 	return l.client.SetServerLevel(level)
 }
 
+// AddHook добавляет обработчик сообщений перед отправкой серверу (см. LogClient.AddHook)
+func (l *Logger) AddHook(hook MessageHook) {
+	l.client.AddHook(hook)
+}
+
+// TruncatedFields возвращает количество значений полей, укороченных или отброшенных
+// из-за размера кадра (см. LogClient.TruncatedFields)
+func (l *Logger) TruncatedFields() int64 {
+	return l.client.TruncatedFields()
+}
+
 // GetLogFile возвращает путь к файлу лога
 func (l *Logger) GetLogFile() string {
 	return l.client.GetLogFile()
@@ -108,11 +305,52 @@ func (l *Logger) GetLogEntries(filter FilterOptions) ([]LogEntry, error) {
 	return l.client.GetLogEntries(filter)
 }
 
+// GetLogEntriesContext получает записи из лога, ожидая ответ сервера не дольше, чем позволяет ctx
+func (l *Logger) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
+	return l.client.GetLogEntriesContext(ctx, filter)
+}
+
+// ExportCSV выгружает записи лога в w в формате CSV
+func (l *Logger) ExportCSV(w io.Writer, filter FilterOptions) error {
+	return l.client.ExportCSV(w, filter)
+}
+
+// ExportCSVWithOptions выгружает записи лога в CSV с выгрузкой дополнительных полей
+func (l *Logger) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	return l.client.ExportCSVWithOptions(w, filter, options)
+}
+
+// ExportNDJSON выгружает записи лога в w по одному JSON объекту на строку
+func (l *Logger) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	return l.client.ExportNDJSON(w, filter)
+}
+
+// StreamLogEntries получает записи из лога частями и передает их по одной в fn
+// Возврат false из fn прекращает чтение.
+func (l *Logger) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	return l.client.StreamLogEntries(filter, fn)
+}
+
+// GetRange получает записи из диапазона строк файла лога (нумерация с 1)
+func (l *Logger) GetRange(start, count int) ([]LogEntry, error) {
+	return l.client.GetRange(start, count)
+}
+
+// GetFilteredRange получает записи из диапазона строк с фильтрацией
+func (l *Logger) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	return l.client.GetFilteredRange(start, count, filter)
+}
+
 // Ping проверяет соединение с сервером
 func (l *Logger) Ping() error {
 	return l.client.Ping()
 }
 
+// Health возвращает состояние записи лога на сервере
+func (l *Logger) Health() (HealthStatus, error) {
+	return l.client.Health()
+}
+
 // Close закрывает логгер
 func (l *Logger) Close() error {
 	// Принудительно сбрасываем буфер перед закрытием
@@ -123,7 +361,29 @@ func (l *Logger) Close() error {
 	return l.client.Close()
 }
 
+// Flush дожидается записи отправленных сообщений на диск
+func (l *Logger) Flush() error {
+	return l.client.Flush()
+}
+
+// CloseAndFlush дожидается записи отправленных сообщений на диск и закрывает логгер
+func (l *Logger) CloseAndFlush() error {
+	return l.client.CloseAndFlush()
+}
+
 // Методы для MAIN сервиса
+func (l *Logger) Trace(args ...interface{}) error {
+	// Используем универсальный метод Trace
+	return l.client.Trace(args...)
+}
+
+// Tracef логирует форматированное сообщение уровня TRACE
+// В отличие от Trace, аргументы всегда подставляются в формат и не
+// разбираются как дополнительные поля.
+func (l *Logger) Tracef(format string, args ...interface{}) error {
+	return l.client.Trace(fmt.Sprintf(format, args...))
+}
+
 func (l *Logger) Debug(args ...interface{}) error {
 	// Используем универсальный метод Debug
 	return l.client.Debug(args...)
@@ -2,6 +2,8 @@
 package logger
 
 import (
+	"os"
+	"sync"
 	"testing"
 )
 
@@ -67,6 +69,97 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestSetDefault проверяет, что глобальные функции используют логгер по умолчанию
+func TestSetDefault(t *testing.T) {
+	mockClient := &MockLogClient{}
+	SetDefault(&Logger{client: mockClient})
+	t.Cleanup(func() { SetDefault(nil) })
+
+	if Default() == nil {
+		t.Fatal("логгер по умолчанию должен быть задан")
+	}
+
+	Debug("глобальная отладка")
+	Info("глобальное сообщение %d", 42)
+	Warn("глобальное предупреждение")
+	Error("глобальная ошибка")
+
+	// Глобальные функции и SetDefault безопасны при параллельном вызове
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Info("параллельное сообщение")
+			SetDefault(&Logger{client: mockClient})
+		}()
+	}
+	wg.Wait()
+
+	mockClient.mu.Lock()
+	calls := mockClient.calls
+	mockClient.mu.Unlock()
+
+	if len(calls) != 14 {
+		t.Fatalf("ожидалось 14 сообщений через логгер по умолчанию, получено %d", len(calls))
+	}
+	if calls[1].Level != INFO || calls[1].Message != "глобальное сообщение 42" {
+		t.Errorf("неожиданное сообщение: %+v", calls[1])
+	}
+	if calls[3].Level != ERROR {
+		t.Errorf("ожидался уровень ERROR, получен %v", calls[3].Level)
+	}
+
+	// Без логгера по умолчанию сообщения выводятся в stdout, а не в клиент
+	SetDefault(nil)
+	stdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	Info("сообщение в stdout")
+	os.Stdout.Close()
+	os.Stdout = stdout
+
+	mockClient.mu.Lock()
+	defer mockClient.mu.Unlock()
+	if len(mockClient.calls) != 14 {
+		t.Error("после SetDefault(nil) сообщения не должны попадать в клиент")
+	}
+}
+
+// TestDefaultService проверяет получение логгера сервиса из логгера по умолчанию
+func TestDefaultService(t *testing.T) {
+	// Без логгера по умолчанию возвращается логгер, пишущий в stderr
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	fallback := Service("http")
+	err := fallback.Info("сообщение без логгера по умолчанию")
+	os.Stderr.Close()
+	os.Stderr = stderr
+
+	if fallback == nil {
+		t.Fatal("Service не должен возвращать nil без логгера по умолчанию")
+	}
+	if err != nil {
+		t.Errorf("запись в stderr не должна возвращать ошибку: %v", err)
+	}
+
+	mockClient := &MockLogClient{serviceLoggers: make(map[string]*ServiceLogger)}
+	SetDefault(&Logger{client: mockClient})
+	t.Cleanup(func() { SetDefault(nil) })
+
+	if err := Service("HTTP").Warn("через логгер по умолчанию"); err != nil {
+		t.Fatalf("ошибка записи: %v", err)
+	}
+
+	mockClient.mu.Lock()
+	defer mockClient.mu.Unlock()
+	if len(mockClient.calls) != 1 {
+		t.Fatalf("ожидалось 1 сообщение через логгер по умолчанию, получено %d", len(mockClient.calls))
+	}
+	if call := mockClient.calls[0]; call.Service != "HTTP" || call.Level != WARN {
+		t.Errorf("неожиданное сообщение: %+v", call)
+	}
+}
+
 // TestLoggerMethods проверяет методы логирования
 func TestLoggerMethods(t *testing.T) {
 	// Создаем мок логгер с мок клиентом
@@ -80,6 +173,20 @@ func TestLoggerMethods(t *testing.T) {
 		message string
 		wantErr bool
 	}{
+		{
+			name:    "Trace",
+			method:  func() error { return logger.Trace("test trace") },
+			level:   TRACE,
+			message: "test trace",
+			wantErr: false,
+		},
+		{
+			name:    "Tracef",
+			method:  func() error { return logger.Tracef("test %s", "tracef") },
+			level:   TRACE,
+			message: "test tracef",
+			wantErr: false,
+		},
 		{
 			name:    "Debug",
 			method:  func() error { return logger.Debug("test debug") },
@@ -315,3 +422,101 @@ func TestPing(t *testing.T) {
 		})
 	}
 }
+
+// TestNewWithClient проверяет логгер с подставным клиентом и заранее созданными сервисами
+func TestNewWithClient(t *testing.T) {
+	fake, sink := NewFakeClient()
+	logger := NewWithClient(fake, "API", "DB")
+
+	if fake.Calls("SetService") != 2 {
+		t.Errorf("логгеры сервисов должны создаваться сразу, вызовов SetService: %d", fake.Calls("SetService"))
+	}
+
+	_ = logger.Info("запуск")
+	_ = logger.SetService("api").Warn("медленно")
+	if err := logger.Close(); err != nil || fake.Calls("Close") != 1 {
+		t.Errorf("Close должен закрывать клиент: %v, вызовов %d", err, fake.Calls("Close"))
+	}
+	if !sink.Contains(INFO, "запуск") || !sink.Contains(WARN, "медленно") {
+		t.Errorf("неожиданные записи: %+v", sink.Entries())
+	}
+
+	// Логгер, обернутый повторно, пишет через тот же клиент
+	wrapped := NewWithClient(logger)
+	_ = wrapped.Error("через обертку")
+	if !sink.Contains(ERROR, "через обертку") {
+		t.Error("обернутый логгер должен писать через клиент исходного")
+	}
+
+	// nil клиент дает логгер, который ничего не записывает
+	if err := NewWithClient(nil).Info("в никуда"); err != nil {
+		t.Errorf("логгер без клиента не должен возвращать ошибку: %v", err)
+	}
+}
+
+// customClient внешняя реализация ClientInterface, переопределяющая Info
+type customClient struct {
+	ClientInterface
+	infos []string
+}
+
+func (c *customClient) Info(args ...interface{}) error {
+	message, _ := processArgs(args...)
+	c.infos = append(c.infos, message)
+	return nil
+}
+
+// TestNewWithClientCustom проверяет передачу вызовов собственной реализации ClientInterface
+func TestNewWithClientCustom(t *testing.T) {
+	memory, sink := NewMemory()
+	custom := &customClient{ClientInterface: memory}
+	logger := NewWithClient(custom)
+
+	_ = logger.Info("свой %s", "транспорт")
+	_ = logger.Warn("мимо переопределения")
+	if len(custom.infos) != 1 || custom.infos[0] != "свой транспорт" {
+		t.Errorf("Info должен вызывать метод клиента, получили %v", custom.infos)
+	}
+	if !sink.Contains(WARN, "мимо переопределения") {
+		t.Error("не переопределенные методы должны выполняться встроенным клиентом")
+	}
+
+	// Сообщения сервисов передаются логгеру из SetService клиента
+	_ = logger.SetService("api").Error("ошибка сервиса")
+	if entries := sink.Entries(); len(entries) != 2 || entries[1].Service != "API" {
+		t.Errorf("ожидалась запись сервиса API, получили %+v", entries)
+	}
+
+	// Внутренний sendMessage адаптера выбирает метод по уровню и сервису
+	adapter := clientAdapter{custom}
+	_ = adapter.sendMessage("MAIN", INFO, "100% готово", map[string]string{"step": "1"})
+	_ = adapter.sendMessage("DB", DEBUG, "ниже уровня", nil)
+	if len(custom.infos) != 2 || custom.infos[1] != "100% готово" {
+		t.Errorf("сообщение MAIN уровня INFO должно попасть в Info без форматирования: %v", custom.infos)
+	}
+	if err := adapter.sendMessage("MAIN", OFF, "нет уровня", nil); err == nil {
+		t.Error("ожидалась ошибка для уровня OFF")
+	}
+}
+
+// TestNewWithClientFatal проверяет, что Fatal сервиса собственной реализации завершает программу так же, как ее клиент
+func TestNewWithClientFatal(t *testing.T) {
+	exits := 0
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(code int) { exits++ }
+
+	// Завершение остается за логгером сервиса клиента: FakeClient программу не завершает
+	fake, fakeSink := NewFakeClient()
+	_ = NewWithClient(&customClient{ClientInterface: fake}).SetService("api").Fatal("сбой")
+	if exits != 0 || !fakeSink.Contains(FATAL, "сбой") {
+		t.Errorf("ожидалась запись без завершения, завершений %d, записи %+v", exits, fakeSink.Entries())
+	}
+
+	// Логгер NewMemory завершает программу один раз
+	memory, memorySink := NewMemory()
+	_ = NewWithClient(&customClient{ClientInterface: memory}).SetService("api").Fatal("сбой памяти")
+	if exits != 1 || !memorySink.Contains(FATAL, "сбой памяти") {
+		t.Errorf("ожидалось одно завершение после записи, завершений %d, записи %+v", exits, memorySink.Entries())
+	}
+}
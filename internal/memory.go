@@ -0,0 +1,371 @@
+// memory.go - Логгер, сохраняющий записи в памяти, для модульных тестов
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemorySink приемник, сохраняющий записи лога в памяти
+// Используется логгером NewMemory, а также может быть передан серверу как
+// обычный Sink. Методы безопасны для вызова из нескольких горутин.
+type MemorySink struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// NewMemorySink создает пустой приемник в памяти
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Write сохраняет запись
+func (m *MemorySink) Write(entry LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Close ничего не делает: записи остаются доступны после закрытия
+func (m *MemorySink) Close() error {
+	return nil
+}
+
+// Entries возвращает копию сохраненных записей в порядке записи
+func (m *MemorySink) Entries() []LogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make([]LogEntry, len(m.entries))
+	copy(entries, m.entries)
+	return entries
+}
+
+// Contains проверяет, есть ли запись уровня level, текст которой содержит substr
+func (m *MemorySink) Contains(level LogLevel, substr string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range m.entries {
+		if entry.Level == level && strings.Contains(entry.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset удаляет все сохраненные записи
+func (m *MemorySink) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = nil
+}
+
+// memoryClient клиент, записывающий сообщения в MemorySink без сервера и сокета
+// Уровни логирования и обработчики сообщений применяются так же, как в LogClient,
+// поэтому тесты проверяют реальное поведение, а не только факт вызова.
+type memoryClient struct {
+	nopClient
+
+	sink *MemorySink
+
+	mu            sync.RWMutex
+	level         LogLevel            // Общий уровень логирования
+	serviceLevels map[string]LogLevel // Уровни, переопределяющие общий для отдельных сервисов
+	hooks         []MessageHook       // Обработчики сообщений перед записью
+	seq           int64               // Номер последней записи
+}
+
+var _ LogClientInterface = (*memoryClient)(nil)
+
+// NewMemory создает логгер, сохраняющий записи в памяти, и его приемник
+// Сервер и сокет не используются. Общий уровень логирования - INFO, его можно
+// изменить методами SetLevel и SetServerLevel. Запросы записей (GetLogEntries,
+// GetRange, экспорт) выполняются по сохраненным записям.
+func NewMemory() (*Logger, *MemorySink) {
+	sink := NewMemorySink()
+	client := &memoryClient{
+		sink:          sink,
+		level:         INFO,
+		serviceLevels: make(map[string]LogLevel),
+	}
+	return NewWithClient(client), sink
+}
+
+// SetService возвращает логгер сервиса, записывающий сообщения в память
+func (c *memoryClient) SetService(service string) *ServiceLogger {
+	return newServiceLogger(c, service)
+}
+
+func (c *memoryClient) SetLevel(level LogLevel) {
+	c.mu.Lock()
+	c.level = level
+	c.mu.Unlock()
+}
+
+// SetServerLevel меняет общий уровень: в памяти клиент и сервер совпадают
+func (c *memoryClient) SetServerLevel(level LogLevel) error {
+	if !level.IsValid() {
+		return fmt.Errorf("недопустимый уровень логирования: %d", level)
+	}
+	c.SetLevel(level)
+	return nil
+}
+
+func (c *memoryClient) GetServerLevel() (LogLevel, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.level, nil
+}
+
+func (c *memoryClient) GetServiceLevels() (map[string]LogLevel, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.serviceLevels), nil
+}
+
+func (c *memoryClient) SetServiceLevel(service string, level LogLevel) error {
+	if !level.IsValid() {
+		return fmt.Errorf("недопустимый уровень логирования: %d", level)
+	}
+
+	c.mu.Lock()
+	c.serviceLevels[NormalizeServiceName(service)] = level
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *memoryClient) ResetServiceLevel(service string) error {
+	c.mu.Lock()
+	delete(c.serviceLevels, NormalizeServiceName(service))
+	c.mu.Unlock()
+	return nil
+}
+
+// RegisterService только проверяет имя: записи всех сервисов сохраняются без ограничений
+func (c *memoryClient) RegisterService(service string) error {
+	_, err := checkServiceName(service, nil)
+	return err
+}
+
+// LogPanic записывает перехваченную панику и перебрасывает ее
+func (c *memoryClient) LogPanic() {
+	if r := recover(); r != nil {
+		_ = c.sendMessage("MAIN", PANIC, fmt.Sprintf("Восстановлено после паники: %v", r), nil)
+		panic(r)
+	}
+}
+
+// GetLogEntries возвращает сохраненные записи, подходящие под фильтр
+func (c *memoryClient) GetLogEntries(filter FilterOptions) ([]LogEntry, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	entries := []LogEntry{}
+	eachMatchingEntry(c.sink.Entries(), filter, func(entry LogEntry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	return entries, nil
+}
+
+// GetLogEntriesContext возвращает сохраненные записи, если ctx еще не завершен
+func (c *memoryClient) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.GetLogEntries(filter)
+}
+
+func (c *memoryClient) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	if fn == nil {
+		return fmt.Errorf("не задан обработчик записей")
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	eachMatchingEntry(c.sink.Entries(), filter, fn)
+	return nil
+}
+
+func (c *memoryClient) ExportCSV(w io.Writer, filter FilterOptions) error {
+	return c.ExportCSVWithOptions(w, filter, CSVOptions{})
+}
+
+func (c *memoryClient) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeCSV(w, options, func(fn func(LogEntry) bool) error {
+		return c.StreamLogEntries(filter, fn)
+	})
+}
+
+func (c *memoryClient) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return writeNDJSON(w, func(fn func(LogEntry) bool) error {
+		return c.StreamLogEntries(filter, fn)
+	})
+}
+
+// GetRange возвращает записи с номерами от start (нумерация с 1)
+// В памяти нет строк файла, поэтому диапазон задается номерами записей.
+func (c *memoryClient) GetRange(start, count int) ([]LogEntry, error) {
+	return c.GetFilteredRange(start, count, FilterOptions{})
+}
+
+func (c *memoryClient) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	req := RangeRequest{Start: start, Count: count, Filter: filter}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	all := c.sink.Entries()
+	if start > len(all) {
+		return nil, fmt.Errorf("начальная запись %d превышает количество записей (%d)", start, len(all))
+	}
+
+	entries := []LogEntry{}
+	eachMatchingEntry(all[start-1:min(start-1+count, len(all))], filter, func(entry LogEntry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	return entries, nil
+}
+
+func (c *memoryClient) AddHook(hook MessageHook) {
+	if hook == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Новый срез вместо append на месте: sendMessage применяет прежний вне блокировки
+	hooks := make([]MessageHook, len(c.hooks), len(c.hooks)+1)
+	copy(hooks, c.hooks)
+	c.hooks = append(hooks, hook)
+}
+
+func (c *memoryClient) Trace(args ...interface{}) error { return c.log(TRACE, args) }
+func (c *memoryClient) Debug(args ...interface{}) error { return c.log(DEBUG, args) }
+func (c *memoryClient) Info(args ...interface{}) error  { return c.log(INFO, args) }
+func (c *memoryClient) Warn(args ...interface{}) error  { return c.log(WARN, args) }
+func (c *memoryClient) Error(args ...interface{}) error { return c.log(ERROR, args) }
+
+// Fatal записывает сообщение и завершает программу
+func (c *memoryClient) Fatal(args ...interface{}) error {
+	message, fields := processArgs(args...)
+	return c.fatal("MAIN", message, fields)
+}
+
+// fatal записывает сообщение FATAL сервиса без вывода в stderr и завершает программу
+func (c *memoryClient) fatal(service string, message string, fields map[string]string) error {
+	_, _ = c.record(service, FATAL, message, fields)
+	exitFunc(1)
+	return nil
+}
+
+// Panic записывает сообщение и вызывает панику
+func (c *memoryClient) Panic(args ...interface{}) error {
+	message, fields := processArgs(args...)
+	return c.panic("MAIN", message, fields)
+}
+
+// panic записывает сообщение PANIC сервиса и вызывает панику с текстом после обработчиков
+func (c *memoryClient) panic(service string, message string, fields map[string]string) error {
+	text, _ := c.record(service, PANIC, message, fields)
+	panic(text)
+}
+
+// log записывает сообщение сервиса MAIN
+func (c *memoryClient) log(level LogLevel, args []interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("отсутствуют аргументы")
+	}
+	message, fields := processArgs(args...)
+	return c.sendMessage("MAIN", level, message, fields)
+}
+
+// sendMessage проверяет уровень, применяет обработчики и сохраняет запись
+func (c *memoryClient) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	_, err := c.record(service, level, message, fields)
+	return err
+}
+
+// record применяет обработчики и сохраняет запись, если она проходит уровень сервиса
+// Возвращает текст сообщения после обработчиков. Для FATAL и PANIC обработчики
+// применяются и при отфильтрованном уровне: этот текст передается в panic.
+func (c *memoryClient) record(service string, level LogLevel, message string, fields map[string]string) (string, error) {
+	c.mu.RLock()
+	threshold, ok := c.serviceLevels[service]
+	if !ok {
+		threshold = c.level
+	}
+	hooks := c.hooks
+	c.mu.RUnlock()
+
+	accepted := threshold != OFF && level >= threshold && level.IsMessageLevel()
+	if !accepted && level < FATAL {
+		return message, nil
+	}
+
+	msg := LogMessage{
+		Service:   service,
+		Level:     level,
+		Message:   message,
+		Timestamp: time.Now(),
+		Fields:    maps.Clone(fields),
+	}
+	if !applyHooks(hooks, &msg) || !accepted {
+		return msg.Message, nil
+	}
+
+	// Номер назначается под блокировкой записи, чтобы записи шли по порядку номеров
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	return msg.Message, c.sink.Write(LogEntry{
+		Service:   msg.Service,
+		Level:     msg.Level,
+		Message:   msg.Message,
+		Timestamp: msg.Timestamp,
+		Fields:    msg.Fields,
+		Seq:       c.seq,
+	})
+}
+
+// eachMatchingEntry передает в fn записи, подходящие под фильтр, с учетом смещения и лимита
+func eachMatchingEntry(entries []LogEntry, filter FilterOptions, fn func(LogEntry) bool) {
+	sent := 0
+	skipped := 0
+	for _, entry := range entries {
+		if !filter.matches(entry) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if !fn(entry) {
+			return
+		}
+		sent++
+		if filter.Limit > 0 && sent >= filter.Limit {
+			return
+		}
+	}
+}
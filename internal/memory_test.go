@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestMemoryLogger проверяет запись сообщений и полей в память
+ * @param t *testing.T - тестовый контекст
+ */
+func TestMemoryLogger(t *testing.T) {
+	log, sink := NewMemory()
+
+	if err := log.Info("запуск %s", "службы"); err != nil {
+		t.Fatalf("Info вернул ошибку: %v", err)
+	}
+	api := log.SetService("api")
+	if err := api.Error("ошибка запроса", "path", "/users", "code", "500"); err != nil {
+		t.Fatalf("Error сервиса вернул ошибку: %v", err)
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("ожидалось 2 записи, получили %d", len(entries))
+	}
+	if entries[0].Service != "MAIN" || entries[0].Message != "запуск службы" || entries[0].Seq != 1 {
+		t.Errorf("неожиданная первая запись: %+v", entries[0])
+	}
+	if entries[1].Service != "API" || entries[1].Fields["path"] != "/users" || entries[1].Fields["code"] != "500" {
+		t.Errorf("неожиданная запись сервиса: %+v", entries[1])
+	}
+
+	if !sink.Contains(ERROR, "запроса") {
+		t.Error("Contains не нашел запись уровня ERROR")
+	}
+	if sink.Contains(INFO, "запроса") {
+		t.Error("Contains не должен учитывать записи другого уровня")
+	}
+
+	sink.Reset()
+	if len(sink.Entries()) != 0 {
+		t.Error("Reset должен удалить все записи")
+	}
+}
+
+/**
+ * TestMemoryLoggerLevels проверяет фильтрацию по общему уровню и уровням сервисов
+ * @param t *testing.T - тестовый контекст
+ */
+func TestMemoryLoggerLevels(t *testing.T) {
+	log, sink := NewMemory()
+
+	_ = log.Debug("отладка по умолчанию")
+	if len(sink.Entries()) != 0 {
+		t.Fatal("DEBUG не должен записываться при уровне INFO")
+	}
+
+	log.SetLevel(DEBUG)
+	_ = log.Debug("отладка после SetLevel")
+	if !sink.Contains(DEBUG, "после SetLevel") {
+		t.Error("DEBUG должен записываться после SetLevel(DEBUG)")
+	}
+
+	if err := log.SetServiceLevel("db", ERROR); err != nil {
+		t.Fatalf("SetServiceLevel вернул ошибку: %v", err)
+	}
+	db := log.SetService("db")
+	_ = db.Warn("предупреждение базы")
+	_ = db.Error("ошибка базы")
+	if sink.Contains(WARN, "предупреждение базы") || !sink.Contains(ERROR, "ошибка базы") {
+		t.Error("уровень сервиса должен переопределять общий")
+	}
+
+	levels, err := log.GetServiceLevels()
+	if err != nil || levels["DB"] != ERROR {
+		t.Errorf("ожидался уровень ERROR для DB, получили %v, %v", levels, err)
+	}
+
+	if err := log.ResetServiceLevel("db"); err != nil {
+		t.Fatalf("ResetServiceLevel вернул ошибку: %v", err)
+	}
+	_ = db.Warn("предупреждение после сброса")
+	if !sink.Contains(WARN, "после сброса") {
+		t.Error("после сброса должен действовать общий уровень")
+	}
+}
+
+/**
+ * TestMemoryLoggerHooks проверяет применение обработчиков сообщений
+ * @param t *testing.T - тестовый контекст
+ */
+func TestMemoryLoggerHooks(t *testing.T) {
+	log, sink := NewMemory()
+	log.AddHook(func(msg *LogMessage) bool {
+		if strings.Contains(msg.Message, "секрет") {
+			return false
+		}
+		msg.Message = strings.ToUpper(msg.Message)
+		return true
+	})
+
+	_ = log.Info("секрет")
+	_ = log.Info("открыто")
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].Message != "ОТКРЫТО" {
+		t.Errorf("ожидалась одна измененная запись, получили %+v", entries)
+	}
+}
+
+/**
+ * TestMemoryLoggerQueries проверяет запросы записей и экспорт
+ * @param t *testing.T - тестовый контекст
+ */
+func TestMemoryLoggerQueries(t *testing.T) {
+	log, _ := NewMemory()
+	_ = log.Info("первое")
+	_ = log.SetService("api").Warn("второе")
+	_ = log.Error("третье")
+
+	minLevel := WARN
+	entries, err := log.GetLogEntries(FilterOptions{MinLevel: &minLevel})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ожидалось 2 записи не ниже WARN, получили %d, %v", len(entries), err)
+	}
+
+	entries, err = log.GetLogEntries(FilterOptions{Service: "API"})
+	if err != nil || len(entries) != 1 || entries[0].Message != "второе" {
+		t.Errorf("неожиданный результат фильтра по сервису: %+v, %v", entries, err)
+	}
+
+	entries, err = log.GetRange(2, 5)
+	if err != nil || len(entries) != 2 || entries[0].Message != "второе" {
+		t.Errorf("неожиданный диапазон: %+v, %v", entries, err)
+	}
+	if _, err := log.GetRange(4, 1); err == nil {
+		t.Error("ожидалась ошибка для начала за пределами записей")
+	}
+
+	var buf bytes.Buffer
+	if err := log.ExportNDJSON(&buf, FilterOptions{}); err != nil {
+		t.Fatalf("ExportNDJSON вернул ошибку: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Errorf("ожидалось 3 строки NDJSON, получили %d", lines)
+	}
+}
+
+/**
+ * TestMemoryLoggerFatal проверяет, что Fatal записывает сообщение и завершает программу
+ * @param t *testing.T - тестовый контекст
+ */
+func TestMemoryLoggerFatal(t *testing.T) {
+	exitCodes := make([]int, 0, 2)
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(code int) { exitCodes = append(exitCodes, code) }
+
+	log, sink := NewMemory()
+	_ = log.Fatal("критично")
+	_ = log.SetService("api").Fatal("критично в сервисе")
+
+	if len(exitCodes) != 2 {
+		t.Fatalf("ожидалось два вызова exitFunc, получили %v", exitCodes)
+	}
+	if !sink.Contains(FATAL, "критично") || !sink.Contains(FATAL, "в сервисе") {
+		t.Errorf("сообщения FATAL должны быть записаны: %+v", sink.Entries())
+	}
+}
@@ -2,7 +2,10 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -35,24 +38,39 @@ type LogMessage struct {
 	Timestamp time.Time         `json:"timestamp"`           // Время создания
 	ClientID  string            `json:"client_id,omitempty"` // Идентификатор клиента
 	Fields    map[string]string `json:"fields,omitempty"`    // Дополнительные поля для структурированного логирования
+	Seq       int64             `json:"seq,omitempty"`       // Порядковый номер, присваивается сервером при записи
+
+	flushed chan struct{} // Маркер сброса в буфере сервера: закрывается после записи предыдущих сообщений
 }
 
 // LogEntry структура записи лога для чтения с кешированием
 type LogEntry struct {
-	Service   string    `json:"service"`   // Название сервиса
-	Level     LogLevel  `json:"level"`     // Уровень логирования
-	Message   string    `json:"message"`   // Текст сообщения
-	Timestamp time.Time `json:"timestamp"` // Время создания
-	Raw       string    `json:"raw"`       // Исходная строка лога
+	Service   string            `json:"service"`          // Название сервиса
+	Level     LogLevel          `json:"level"`            // Уровень логирования
+	Message   string            `json:"message"`          // Текст сообщения
+	Timestamp time.Time         `json:"timestamp"`        // Время создания
+	Fields    map[string]string `json:"fields,omitempty"` // Дополнительные поля записи
+	Seq       int64             `json:"seq,omitempty"`    // Порядковый номер записи (0 - запись без номера)
+	Raw       string            `json:"raw"`              // Исходные строки лога (заголовок и поля)
 }
 
 // FilterOptions опции фильтрации логов с валидацией
 type FilterOptions struct {
-	StartTime *time.Time `json:"start_time,omitempty"` // Начальное время фильтрации
-	EndTime   *time.Time `json:"end_time,omitempty"`   // Конечное время фильтрации
-	Level     *LogLevel  `json:"level,omitempty"`      // Фильтр по уровню
-	Service   string     `json:"service,omitempty"`    // Фильтр по сервису
-	Limit     int        `json:"limit,omitempty"`      // Лимит количества записей
+	StartTime       *time.Time `json:"start_time,omitempty"`       // Начальное время фильтрации
+	EndTime         *time.Time `json:"end_time,omitempty"`         // Конечное время фильтрации
+	Level           *LogLevel  `json:"level,omitempty"`            // Фильтр по точному совпадению уровня
+	MinLevel        *LogLevel  `json:"min_level,omitempty"`        // Фильтр по уровню: указанный и более серьезные
+	Service         string     `json:"service,omitempty"`          // Фильтр по сервису (эквивалентен Services из одного элемента)
+	Services        []string   `json:"services,omitempty"`         // Фильтр по нескольким сервисам (совпадение с любым)
+	ExcludeServices []string   `json:"exclude_services,omitempty"` // Сервисы, записи которых исключаются (например, SERVER_LOGGER_NAME)
+	Limit           int        `json:"limit,omitempty"`            // Лимит количества записей
+	Offset          int        `json:"offset,omitempty"`           // Количество подходящих записей, пропускаемых с начала
+
+	FieldMatch map[string]string `json:"field_match,omitempty"` // Точное совпадение значений дополнительных полей
+	Contains   string            `json:"contains,omitempty"`    // Подстрока, которую должен содержать текст сообщения (с учетом регистра)
+	IncludeRaw bool              `json:"include_raw,omitempty"` // Возвращать исходные строки лога в LogEntry.Raw (по умолчанию Raw пустой)
+
+	SearchRotated bool `json:"search_rotated,omitempty"` // Искать и в резервных копиях (LogFile.N), если StartTime раньше первой записи файла лога
 }
 
 // Validate проверяет корректность параметров фильтрации
@@ -63,23 +81,154 @@ func (f *FilterOptions) Validate() error {
 	if f.Limit < 0 {
 		return fmt.Errorf("лимит не может быть отрицательным")
 	}
-	if f.Limit > 10000 { // Защита от чрезмерных запросов
-		return fmt.Errorf("лимит не может превышать 10000 записей")
+	if f.Limit > DEFAULT_MAX_QUERY_LIMIT { // Защита от чрезмерных запросов
+		return fmt.Errorf("лимит не может превышать %d записей", DEFAULT_MAX_QUERY_LIMIT)
+	}
+	if f.Offset < 0 {
+		return fmt.Errorf("смещение не может быть отрицательным")
+	}
+
+	// Удаляем повторы сервисов, пустые имена не допускаются
+	if len(f.Services) > 0 {
+		seen := make(map[string]struct{}, len(f.Services))
+		services := make([]string, 0, len(f.Services))
+		for _, service := range f.Services {
+			if service == "" {
+				return fmt.Errorf("имя сервиса в списке не может быть пустым")
+			}
+			if _, ok := seen[service]; ok {
+				continue
+			}
+			seen[service] = struct{}{}
+			services = append(services, service)
+		}
+		f.Services = services
+	}
+
+	for _, service := range f.ExcludeServices {
+		if service == "" {
+			return fmt.Errorf("имя исключаемого сервиса не может быть пустым")
+		}
+	}
+
+	for key := range f.FieldMatch {
+		if key == "" {
+			return fmt.Errorf("имя поля в фильтре не может быть пустым")
+		}
 	}
 	return nil
 }
 
+// matches проверяет соответствие записи фильтру
+// Если заданы и Level, и MinLevel, используется точное совпадение Level, а MinLevel игнорируется.
+func (f *FilterOptions) matches(entry LogEntry) bool {
+	// Фильтр по времени
+	if f.StartTime != nil && entry.Timestamp.Before(*f.StartTime) {
+		return false
+	}
+	if f.EndTime != nil && entry.Timestamp.After(*f.EndTime) {
+		return false
+	}
+
+	// Фильтр по уровню: точное совпадение имеет приоритет над минимальным уровнем
+	if f.Level != nil {
+		if entry.Level != *f.Level {
+			return false
+		}
+	} else if f.MinLevel != nil && entry.Level < *f.MinLevel {
+		return false
+	}
+
+	// Фильтр по тексту сообщения
+	if f.Contains != "" && !strings.Contains(entry.Message, f.Contains) {
+		return false
+	}
+
+	// Фильтр по дополнительным полям: все указанные поля должны совпадать
+	for key, value := range f.FieldMatch {
+		if fieldValue, ok := entry.Fields[key]; !ok || fieldValue != value {
+			return false
+		}
+	}
+
+	// Фильтр по сервису (одному или нескольким)
+	return f.matchesService(entry.Service)
+}
+
+// matchesService проверяет, подходит ли сервис под фильтр
+// Service и Services объединяются: запись подходит, если ее сервис совпадает с любым из них.
+// Сервисы из ExcludeServices отбрасываются всегда, даже если указаны в Service или Services.
+func (f *FilterOptions) matchesService(service string) bool {
+	if slices.Contains(f.ExcludeServices, service) {
+		return false
+	}
+	if f.Service == "" && len(f.Services) == 0 {
+		return true
+	}
+	if f.Service != "" && f.Service == service {
+		return true
+	}
+	for _, s := range f.Services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// RangeRequest запрос записей по диапазону строк файла лога
+type RangeRequest struct {
+	Start  int           `json:"start"`  // Номер первой строки (нумерация с 1)
+	Count  int           `json:"count"`  // Количество строк в диапазоне
+	Filter FilterOptions `json:"filter"` // Фильтр, применяемый внутри диапазона
+}
+
+// Validate проверяет корректность диапазона и фильтра
+func (r *RangeRequest) Validate() error {
+	if r.Start < 1 {
+		return fmt.Errorf("номер начальной строки должен быть не меньше 1")
+	}
+	if r.Count <= 0 {
+		return fmt.Errorf("количество строк должно быть положительным")
+	}
+	if r.Count > DEFAULT_MAX_QUERY_LIMIT {
+		return fmt.Errorf("количество строк не может превышать %d", DEFAULT_MAX_QUERY_LIMIT)
+	}
+	return r.Filter.Validate()
+}
+
 // Протокол взаимодействия клиент-сервер
 type ProtocolMessage struct {
-	Type string      `json:"type"` // Тип сообщения
-	Data interface{} `json:"data"` // Данные сообщения
+	Type     string      `json:"type"`               // Тип сообщения
+	Data     interface{} `json:"data"`               // Данные сообщения
+	Encoding string      `json:"encoding,omitempty"` // Сжатие данных (пусто - без сжатия, EncodingGzip)
+	Code     ErrorCode   `json:"code,omitempty"`     // Код ошибки для MsgTypeError (пусто у серверов старых версий)
+}
+
+// requestMessage входящее сообщение протокола на стороне сервера
+// Data остается исходным JSON и декодируется обработчиком сразу в нужную
+// структуру, без промежуточного map[string]interface{} и повторной сериализации.
+type requestMessage struct {
+	Type     string          `json:"type"`               // Тип сообщения
+	Data     json.RawMessage `json:"data"`               // Данные сообщения в исходном виде
+	Encoding string          `json:"encoding,omitempty"` // Сжатие данных (не используется в запросах)
+}
+
+// decodeRequestData декодирует данные запроса в v
+// Отсутствующие данные равносильны null и оставляют v без изменений.
+func decodeRequestData(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
 }
 
 // Константы типов сообщений протокола
 const (
 	MsgTypeLog         = "log"          // Сообщение лога
 	MsgTypeGetEntries  = "get_entries"  // Запрос записей
-	MsgTypeUpdateLevel = "update_level" // Обновление уровня
+	MsgTypeGetRange    = "get_range"    // Запрос записей по диапазону строк
+	MsgTypeUpdateLevel = "update_level" // Deprecated: используйте MsgTypeSetLevel (сервер принимает для совместимости)
 	MsgTypeShutdown    = "shutdown"     // Команда остановки
 	MsgTypeResponse    = "response"     // Ответ сервера
 	MsgTypeError       = "error"        // Ошибка
@@ -91,8 +240,77 @@ const (
 	MsgTypeSetLevel    = "set_level"    // Установка уровня логирования
 	MsgTypeLogFile     = "log_file"     // Файл лога
 	MsgTypeGetLogFile  = "get_log_file" // Получение файла лога
+	MsgTypeHealth      = "health"       // Проверка состояния записи лога
+	MsgTypeFlush       = "flush"        // Сброс принятых сообщений на диск
+
+	MsgTypeSetServiceLevel = "set_service_level" // Установка уровня логирования для сервиса
+	MsgTypeGetLevel        = "get_level"         // Запрос действующих уровней логирования сервера
+	MsgTypeCapabilities    = "capabilities"      // Запрос версии протокола и поддерживаемых типов сообщений
+	MsgTypeHello           = "hello"             // Приветствие клиента с версией протокола при подключении
+	MsgTypeStreamEntries   = "stream_entries"    // Запрос записей с ответом частями
+	MsgTypeEntriesChunk    = "entries_chunk"     // Очередная часть записей потокового ответа
+	MsgTypeStreamEnd       = "stream_end"        // Завершение потокового ответа
+	MsgTypeLogBatch        = "log_batch"         // Пакет сообщений лога (массив LogMessage)
+	MsgTypeRegisterService = "register_service"  // Регистрация сервиса во время работы
 )
 
+// HealthStatus состояние записи лога на сервере
+type HealthStatus struct {
+	Writable       bool   `json:"writable"`                   // Файл лога доступен для записи
+	Error          string `json:"error,omitempty"`            // Причина, по которой запись невозможна
+	LogFile        string `json:"log_file"`                   // Путь к файлу лога
+	FileSize       int64  `json:"file_size"`                  // Текущий размер файла лога в байтах
+	FreeDiskBytes  int64  `json:"free_disk_bytes"`            // Свободное место на диске в байтах (-1, если неизвестно)
+	BufferUsed     int    `json:"buffer_used"`                // Количество сообщений в буфере сервера
+	BufferSize     int    `json:"buffer_size"`                // Емкость буфера сервера
+	LastWriteError string `json:"last_write_error,omitempty"` // Последняя ошибка записи (пусто, если запись после нее удалась)
+	FailedMessages int64  `json:"failed_messages"`            // Сообщения, не записанные из-за ошибок записи
+}
+
+// ServiceLevelRequest запрос установки уровня логирования для отдельного сервиса
+type ServiceLevelRequest struct {
+	Service string `json:"service"` // Имя сервиса
+	Level   string `json:"level"`   // Уровень сервиса (пусто - снять переопределение)
+}
+
+// LevelInfo действующие уровни логирования сервера
+type LevelInfo struct {
+	Level    string            `json:"level"`              // Общий минимальный уровень
+	Services map[string]string `json:"services,omitempty"` // Уровни сервисов, переопределяющие общий
+}
+
+// Hello приветствие, которое клиент отправляет сразу после подключения
+// Сервер отвечает на него Capabilities.
+type Hello struct {
+	ProtocolVersion int      `json:"protocol_version"`      // Версия протокола клиента
+	Compression     []string `json:"compression,omitempty"` // Сжатие ответов, которое клиент умеет распаковывать
+}
+
+// Capabilities версия протокола сервера и поддерживаемые им типы запросов
+type Capabilities struct {
+	ProtocolVersion int      `json:"protocol_version"`           // Версия протокола сервера
+	MessageTypes    []string `json:"message_types"`              // Типы сообщений, которые принимает сервер
+	Compression     string   `json:"compression,omitempty"`      // Сжатие больших ответов на этом соединении (пусто - без сжатия)
+	MaxMessageSize  int      `json:"max_message_size,omitempty"` // Максимальный размер входящего кадра в байтах (0 - не объявлен)
+}
+
+// Supports проверяет, принимает ли сервер указанный тип сообщения
+func (c Capabilities) Supports(msgType string) bool {
+	for _, t := range c.MessageTypes {
+		if t == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownMessageTypeMessage начало текста ошибки сервера о неподдерживаемом типе сообщения
+// Серверы без поддержки приветствия отвечают на MsgTypeHello ошибкой с этим текстом.
+const unknownMessageTypeMessage = "Неизвестный тип сообщения"
+
+// serverAtCapacityMessage текст ошибки, которую сервер отправляет при превышении лимита подключений
+const serverAtCapacityMessage = "сервер перегружен: достигнут лимит подключений"
+
 // Пул объектов для переиспользования (оптимизация памяти)
 var (
 	logMessagePool = sync.Pool{
@@ -115,12 +333,9 @@ func GetLogMessage() *LogMessage {
 
 // PutLogMessage возвращает объект LogMessage в пул
 func PutLogMessage(msg *LogMessage) {
-	// Очищаем поля перед возвратом в пул
-	msg.Service = ""
-	msg.Message = ""
-	msg.ClientID = ""
-	msg.Timestamp = time.Time{}
-	msg.Fields = nil // Очищаем дополнительные поля
+	// Очищаем все поля перед возвратом в пул, включая уровень: сообщение без
+	// поля level после декодирования не должно получить уровень предыдущего
+	*msg = LogMessage{}
 	logMessagePool.Put(msg)
 }
 
@@ -136,5 +351,6 @@ func PutLogEntry(entry *LogEntry) {
 	entry.Message = ""
 	entry.Raw = ""
 	entry.Timestamp = time.Time{}
+	entry.Fields = nil
 	logEntryPool.Put(entry)
 }
@@ -169,6 +169,20 @@ func TestFilterOptionsValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "пустое имя поля в фильтре",
+			filter: FilterOptions{
+				FieldMatch: map[string]string{"": "1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "пустое имя в списке сервисов",
+			filter: FilterOptions{
+				Services: []string{"API", ""},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -186,6 +200,43 @@ func TestFilterOptionsValidate(t *testing.T) {
 	}
 }
 
+// TestFilterOptionsValidateDedupesServices проверяет удаление повторов из списка сервисов
+func TestFilterOptionsValidateDedupesServices(t *testing.T) {
+	filter := FilterOptions{Services: []string{"API", "DB", "API", "DB", "CACHE"}}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("неожиданная ошибка валидации: %v", err)
+	}
+
+	expected := []string{"API", "DB", "CACHE"}
+	if len(filter.Services) != len(expected) {
+		t.Fatalf("ожидалось %d сервисов, получено %v", len(expected), filter.Services)
+	}
+	for i, service := range expected {
+		if filter.Services[i] != service {
+			t.Errorf("сервис %d: ожидалось %q, получено %q", i, service, filter.Services[i])
+		}
+	}
+}
+
+// TestFilterOptionsExcludeServices проверяет исключение сервисов из выборки
+func TestFilterOptionsExcludeServices(t *testing.T) {
+	filter := FilterOptions{Services: []string{"API", "SLOG"}, ExcludeServices: []string{"SLOG"}}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("неожиданная ошибка валидации: %v", err)
+	}
+	if !filter.matches(LogEntry{Service: "API"}) {
+		t.Error("запись сервиса API должна подходить под фильтр")
+	}
+	if filter.matches(LogEntry{Service: "SLOG"}) {
+		t.Error("исключенный сервис не должен подходить под фильтр, даже если указан в Services")
+	}
+
+	filter = FilterOptions{ExcludeServices: []string{""}}
+	if err := filter.Validate(); err == nil {
+		t.Error("пустое имя исключаемого сервиса должно отклоняться")
+	}
+}
+
 // TestProtocolMessage проверяет структуру ProtocolMessage
 func TestProtocolMessage(t *testing.T) {
 	data := map[string]interface{}{
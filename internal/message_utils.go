@@ -3,6 +3,7 @@ package logger
 
 import (
 	"fmt"
+	"strings"
 )
 
 // processLogArgs обрабатывает различные типы аргументов для методов логирования
@@ -127,3 +128,89 @@ func parseKeyValueArgs(args []interface{}) (string, map[string]string) {
 
 	return message, fields
 }
+
+// messageEscaper экранирует символы, нарушающие формат строки лога
+var messageEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// escapeMessage экранирует кавычки, обратные слэши и переводы строк в тексте сообщения,
+// чтобы запись занимала одну строку файла и однозначно читалась обратно
+func escapeMessage(message string) string {
+	if !strings.ContainsAny(message, "\\\"\n\r") {
+		return message
+	}
+	return messageEscaper.Replace(message)
+}
+
+// writeEscapedMessage дописывает в builder текст сообщения, экранированный как в escapeMessage
+func writeEscapedMessage(builder *strings.Builder, message string) {
+	if !strings.ContainsAny(message, "\\\"\n\r") {
+		builder.WriteString(message)
+		return
+	}
+	_, _ = messageEscaper.WriteString(builder, message)
+}
+
+// fieldEscaper экранирует переводы строк в ключах и значениях дополнительных полей
+var fieldEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// escapeField экранирует переводы строк в ключе или значении поля,
+// чтобы поле не разрывало блок полей записи. Восстанавливается через unescapeMessage.
+func escapeField(value string) string {
+	if !strings.ContainsAny(value, "\\\n\r") {
+		return value
+	}
+	return fieldEscaper.Replace(value)
+}
+
+// writeEscapedField дописывает в builder ключ или значение поля, экранированные как в escapeField
+func writeEscapedField(builder *strings.Builder, value string) {
+	if !strings.ContainsAny(value, "\\\n\r") {
+		builder.WriteString(value)
+		return
+	}
+	_, _ = fieldEscaper.WriteString(builder, value)
+}
+
+// unescapeMessage восстанавливает текст сообщения, экранированный escapeMessage
+// Неизвестные последовательности оставляются как есть, что позволяет читать
+// файлы, записанные до появления экранирования.
+func unescapeMessage(message string) string {
+	if !strings.Contains(message, `\`) {
+		return message
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(message))
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c != '\\' || i+1 >= len(message) {
+			builder.WriteByte(c)
+			continue
+		}
+
+		switch message[i+1] {
+		case '\\':
+			builder.WriteByte('\\')
+		case '"':
+			builder.WriteByte('"')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		default:
+			builder.WriteByte(c)
+			continue
+		}
+		i++
+	}
+	return builder.String()
+}
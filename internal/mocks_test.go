@@ -2,6 +2,8 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"sync"
 	"time"
 )
@@ -38,6 +40,7 @@ type MockLogClient struct {
 	logFile    string
 	logEntries []LogEntry
 	pingError  error
+	health     HealthStatus
 }
 
 // Проверка, что MockLogClient реализует интерфейс LogClientInterface
@@ -109,6 +112,97 @@ func (m *MockLogClient) SetServerLevel(level LogLevel) error {
 	return nil
 }
 
+// ServerCapabilities возвращает возможности сервера (мок)
+func (m *MockLogClient) ServerCapabilities() (Capabilities, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "ServerCapabilities",
+	})
+	return Capabilities{ProtocolVersion: PROTOCOL_VERSION}, nil
+}
+
+// AddHook регистрирует добавление обработчика (мок)
+func (m *MockLogClient) AddHook(hook MessageHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "AddHook",
+	})
+}
+
+// TruncatedFields возвращает количество укороченных полей (мок)
+func (m *MockLogClient) TruncatedFields() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "TruncatedFields",
+	})
+	return 0
+}
+
+// GetServerLevel возвращает уровень сервера (мок)
+func (m *MockLogClient) GetServerLevel() (LogLevel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "GetServerLevel",
+	})
+	return m.level, nil
+}
+
+// GetServiceLevels возвращает уровни сервисов (мок)
+func (m *MockLogClient) GetServiceLevels() (map[string]LogLevel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "GetServiceLevels",
+	})
+	return map[string]LogLevel{}, nil
+}
+
+// SetServiceLevel устанавливает уровень сервиса (мок)
+func (m *MockLogClient) SetServiceLevel(service string, level LogLevel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method:  "SetServiceLevel",
+		Service: service,
+		Level:   level,
+	})
+	return nil
+}
+
+// ResetServiceLevel снимает уровень сервиса (мок)
+func (m *MockLogClient) ResetServiceLevel(service string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method:  "ResetServiceLevel",
+		Service: service,
+	})
+	return nil
+}
+
+// RegisterService регистрирует сервис (мок)
+func (m *MockLogClient) RegisterService(service string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method:  "RegisterService",
+		Service: service,
+	})
+	return nil
+}
+
 // GetLogFile возвращает путь к файлу лога (мок)
 func (m *MockLogClient) GetLogFile() string {
 	m.mu.Lock()
@@ -151,6 +245,93 @@ func (m *MockLogClient) GetLogEntries(filter FilterOptions) ([]LogEntry, error)
 	return m.logEntries, nil
 }
 
+// GetLogEntriesContext получает записи лога с учетом контекста (мок)
+func (m *MockLogClient) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.GetLogEntries(filter)
+}
+
+// StreamLogEntries передает записи лога обработчику (мок)
+func (m *MockLogClient) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, MockCall{
+		Method: "StreamLogEntries",
+	})
+	entries := m.logEntries
+	m.mu.Unlock()
+
+	for _, entry := range entries {
+		if !fn(entry) {
+			break
+		}
+	}
+	return nil
+}
+
+// ExportCSV выгружает записи в CSV (мок)
+func (m *MockLogClient) ExportCSV(w io.Writer, filter FilterOptions) error {
+	return m.ExportCSVWithOptions(w, filter, CSVOptions{})
+}
+
+// ExportCSVWithOptions выгружает записи в CSV с параметрами (мок)
+func (m *MockLogClient) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, MockCall{
+		Method: "ExportCSV",
+		Args:   []interface{}{options},
+	})
+	entries := m.logEntries
+	m.mu.Unlock()
+
+	return writeCSV(w, options, func(fn func(LogEntry) bool) error {
+		for _, entry := range entries {
+			if !fn(entry) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// ExportNDJSON выгружает записи в NDJSON (мок)
+func (m *MockLogClient) ExportNDJSON(w io.Writer, filter FilterOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, MockCall{
+		Method: "ExportNDJSON",
+	})
+	entries := m.logEntries
+	m.mu.Unlock()
+
+	return writeNDJSON(w, func(fn func(LogEntry) bool) error {
+		for _, entry := range entries {
+			if !fn(entry) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// GetRange получает записи по диапазону строк (мок)
+func (m *MockLogClient) GetRange(start, count int) ([]LogEntry, error) {
+	return m.GetFilteredRange(start, count, FilterOptions{})
+}
+
+// GetFilteredRange получает записи по диапазону строк с фильтром (мок)
+func (m *MockLogClient) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "GetRange",
+		Args:   []interface{}{start, count},
+	})
+
+	return m.logEntries, nil
+}
+
 // Ping проверяет соединение (мок)
 func (m *MockLogClient) Ping() error {
 	m.mu.Lock()
@@ -163,6 +344,29 @@ func (m *MockLogClient) Ping() error {
 	return m.pingError
 }
 
+// Health возвращает состояние записи лога (мок)
+func (m *MockLogClient) Health() (HealthStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "Health",
+	})
+
+	return m.health, m.pingError
+}
+
+// Flush сбрасывает сообщения на диск (мок)
+func (m *MockLogClient) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{
+		Method: "Flush",
+	})
+	return nil
+}
+
 // Close закрывает соединение (мок)
 func (m *MockLogClient) Close() error {
 	m.mu.Lock()
@@ -175,6 +379,18 @@ func (m *MockLogClient) Close() error {
 	return nil
 }
 
+// CloseAndFlush закрывает соединение после сброса (мок)
+func (m *MockLogClient) CloseAndFlush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.calls = append(m.calls, MockCall{
+		Method: "CloseAndFlush",
+	})
+	return nil
+}
+
 // sendMessage отправляет сообщение (мок)
 func (m *MockLogClient) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
 	m.mu.Lock()
@@ -196,6 +412,12 @@ func (m *MockLogClient) sendMessage(service string, level LogLevel, message stri
 }
 
 // Методы логирования для MAIN сервиса (моки)
+func (m *MockLogClient) Trace(args ...interface{}) error {
+	// Обрабатываем аргументы и отправляем сообщение
+	message, fields := processArgs(args...)
+	return m.sendMessage("MAIN", TRACE, message, fields)
+}
+
 func (m *MockLogClient) Debug(args ...interface{}) error {
 	// Обрабатываем аргументы и отправляем сообщение
 	message, fields := processArgs(args...)
@@ -226,12 +448,25 @@ func (m *MockLogClient) Fatal(args ...interface{}) error {
 	return m.sendMessage("MAIN", FATAL, message, fields)
 }
 
+// fatal отправляет сообщение FATAL сервиса и завершает программу через exitFunc (мок)
+func (m *MockLogClient) fatal(service string, message string, fields map[string]string) error {
+	_ = m.sendMessage(service, FATAL, message, fields)
+	exitFunc(1)
+	return nil
+}
+
 func (m *MockLogClient) Panic(args ...interface{}) error {
 	// Обрабатываем аргументы и отправляем сообщение
 	message, fields := processArgs(args...)
 	return m.sendMessage("MAIN", PANIC, message, fields)
 }
 
+// panic отправляет сообщение PANIC сервиса и вызывает панику (мок)
+func (m *MockLogClient) panic(service string, message string, fields map[string]string) error {
+	_ = m.sendMessage(service, PANIC, message, fields)
+	panic(message)
+}
+
 // Комментарий: Устаревшие форматированные методы и методы с суффиксом WithFields удалены.
 // Теперь все функции логирования используют универсальный интерфейс с вариативными аргументами.
 
@@ -0,0 +1,123 @@
+// nop.go - Логгер, который ничего не записывает
+package logger
+
+import (
+	"context"
+	"io"
+)
+
+// nopClient клиент, отбрасывающий все сообщения
+// Не подключается к сокету и ничего не выводит в stderr; запросы к серверу
+// возвращают пустые результаты без ошибок.
+type nopClient struct{}
+
+var _ LogClientInterface = nopClient{}
+
+// NewNop создает логгер, методы которого ничего не делают
+// Подходит для тестов и библиотек, которым нужен *Logger, но не нужен вывод.
+// Fatal и Panic сохраняют поведение: завершают программу и вызывают панику,
+// так как вызывающий код рассчитывает, что после них выполнение не продолжится.
+func NewNop() *Logger {
+	return NewWithClient(nopClient{})
+}
+
+// SetService возвращает логгер сервиса, отбрасывающий сообщения
+// Имя сервиса не проверяется: сообщения все равно никуда не отправляются.
+func (c nopClient) SetService(service string) *ServiceLogger {
+	return &ServiceLogger{client: c, service: NormalizeServiceName(service)}
+}
+
+func (nopClient) SetLevel(level LogLevel)             {}
+func (nopClient) SetServerLevel(level LogLevel) error { return nil }
+func (nopClient) GetServerLevel() (LogLevel, error)   { return INFO, nil }
+
+func (nopClient) ServerCapabilities() (Capabilities, error) {
+	return Capabilities{ProtocolVersion: PROTOCOL_VERSION}, nil
+}
+
+func (nopClient) GetServiceLevels() (map[string]LogLevel, error) {
+	return map[string]LogLevel{}, nil
+}
+
+func (nopClient) SetServiceLevel(service string, level LogLevel) error { return nil }
+func (nopClient) ResetServiceLevel(service string) error               { return nil }
+func (nopClient) RegisterService(service string) error                 { return nil }
+func (nopClient) GetLogFile() string                                   { return "" }
+func (nopClient) UpdateConfig(config *LoggingConfig) error             { return nil }
+
+// LogPanic перебрасывает перехваченную панику, ничего не записывая
+func (nopClient) LogPanic() {
+	if r := recover(); r != nil {
+		panic(r)
+	}
+}
+
+func (nopClient) GetLogEntries(filter FilterOptions) ([]LogEntry, error) {
+	return []LogEntry{}, nil
+}
+
+func (nopClient) GetLogEntriesContext(ctx context.Context, filter FilterOptions) ([]LogEntry, error) {
+	return []LogEntry{}, nil
+}
+
+func (nopClient) StreamLogEntries(filter FilterOptions, fn func(LogEntry) bool) error {
+	return nil
+}
+
+func (nopClient) ExportCSV(w io.Writer, filter FilterOptions) error { return nil }
+
+func (nopClient) ExportCSVWithOptions(w io.Writer, filter FilterOptions, options CSVOptions) error {
+	return nil
+}
+
+func (nopClient) ExportNDJSON(w io.Writer, filter FilterOptions) error { return nil }
+
+func (nopClient) GetRange(start, count int) ([]LogEntry, error) {
+	return []LogEntry{}, nil
+}
+
+func (nopClient) GetFilteredRange(start, count int, filter FilterOptions) ([]LogEntry, error) {
+	return []LogEntry{}, nil
+}
+
+func (nopClient) Ping() error { return nil }
+
+func (nopClient) Health() (HealthStatus, error) {
+	return HealthStatus{Writable: true, FreeDiskBytes: -1}, nil
+}
+
+func (nopClient) Flush() error                    { return nil }
+func (nopClient) Close() error                    { return nil }
+func (nopClient) CloseAndFlush() error            { return nil }
+func (nopClient) AddHook(hook MessageHook)        {}
+func (nopClient) TruncatedFields() int64          { return 0 }
+func (nopClient) Trace(args ...interface{}) error { return nil }
+func (nopClient) Debug(args ...interface{}) error { return nil }
+func (nopClient) Info(args ...interface{}) error  { return nil }
+func (nopClient) Warn(args ...interface{}) error  { return nil }
+func (nopClient) Error(args ...interface{}) error { return nil }
+
+// Fatal завершает программу, ничего не записывая
+func (nopClient) Fatal(args ...interface{}) error {
+	exitFunc(1)
+	return nil
+}
+
+func (nopClient) fatal(service string, message string, fields map[string]string) error {
+	exitFunc(1)
+	return nil
+}
+
+// Panic вызывает панику с текстом сообщения, ничего не записывая
+func (nopClient) Panic(args ...interface{}) error {
+	message, _ := processArgs(args...)
+	panic(message)
+}
+
+func (nopClient) panic(service string, message string, fields map[string]string) error {
+	panic(message)
+}
+
+func (nopClient) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+/**
+ * TestNopLogger проверяет, что логгер NewNop ничего не записывает и не возвращает ошибок
+ * @param t *testing.T - тестовый контекст
+ */
+func TestNopLogger(t *testing.T) {
+	log := NewNop()
+
+	if err := log.Info("сообщение"); err != nil {
+		t.Errorf("Info вернул ошибку: %v", err)
+	}
+	if err := log.Error("ошибка %d", 1); err != nil {
+		t.Errorf("Error вернул ошибку: %v", err)
+	}
+
+	// Имя сервиса не проверяется, запись не возвращает ошибку
+	svc := log.SetService("bad name!")
+	if err := svc.Warn("сообщение сервиса"); err != nil {
+		t.Errorf("Warn сервиса вернул ошибку: %v", err)
+	}
+	if err := svc.WithTraceID("abc").Info("с трассировкой"); err != nil {
+		t.Errorf("Info с трассировкой вернул ошибку: %v", err)
+	}
+
+	entries, err := log.GetLogEntries(FilterOptions{})
+	if err != nil || entries == nil || len(entries) != 0 {
+		t.Errorf("ожидался пустой список записей без ошибки, получили %v, %v", entries, err)
+	}
+	entries, err = log.GetRange(0, 10)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("ожидался пустой диапазон без ошибки, получили %v, %v", entries, err)
+	}
+
+	levels, err := log.GetServiceLevels()
+	if err != nil || levels == nil || len(levels) != 0 {
+		t.Errorf("ожидалась пустая карта уровней без ошибки, получили %v, %v", levels, err)
+	}
+
+	var buf bytes.Buffer
+	if err := log.ExportCSV(&buf, FilterOptions{}); err != nil || buf.Len() != 0 {
+		t.Errorf("экспорт не должен ничего записывать: %q, %v", buf.String(), err)
+	}
+
+	if err := log.Ping(); err != nil {
+		t.Errorf("Ping вернул ошибку: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("Close вернул ошибку: %v", err)
+	}
+}
+
+/**
+ * TestNopLoggerFatalAndPanic проверяет, что Fatal и Panic сохраняют поведение
+ * @param t *testing.T - тестовый контекст
+ */
+func TestNopLoggerFatalAndPanic(t *testing.T) {
+	exitCodes := make([]int, 0, 2)
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(code int) { exitCodes = append(exitCodes, code) }
+
+	log := NewNop()
+	_ = log.Fatal("завершение")
+	_ = log.SetService("API").Fatal("завершение сервиса")
+	if len(exitCodes) != 2 {
+		t.Fatalf("ожидалось два вызова exitFunc, получили %v", exitCodes)
+	}
+
+	defer func() {
+		if r := recover(); r != "паника" {
+			t.Errorf("ожидалась паника с текстом сообщения, получили %v", r)
+		}
+	}()
+	_ = log.Panic("паника")
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+// peer_linux.go - Определение процесса клиента unix сокета для linux
+package logger
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// peerID возвращает идентификатор процесса клиента по SO_PEERCRED (false - недоступно)
+// В отличие от номера подключения идентификатор сохраняется при переподключении,
+// поэтому бан клиента и его эскалация не сбрасываются новым соединением.
+func peerID(conn net.Conn) (string, bool) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return "", false
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return "", false
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || credErr != nil {
+		return "", false
+	}
+	return fmt.Sprintf("uid_%d_pid_%d", cred.Uid, cred.Pid), true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+// peer_other.go - Заглушка определения процесса клиента для остальных систем
+package logger
+
+import "net"
+
+// peerID на этих системах не поддерживается: клиенты различаются по подключениям
+func peerID(conn net.Conn) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,108 @@
+// quota.go - Учет объема записи по сервисам и квота на объем
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// serviceQuota считает байты, записанные в файл лога каждым сервисом,
+// и ограничивает объем записи сервиса за окно времени
+// Окно фиксированное: счетчики окна обнуляются, когда с его начала прошло
+// больше window. Байты учитываются после записи пакета, поэтому сообщения,
+// уже стоящие в буфере, могут превысить квоту не больше чем на один пакет.
+// Методы допускают nil: у сервера, созданного без NewLogServer, учета нет.
+type serviceQuota struct {
+	mu          sync.Mutex
+	limit       int64            // Байт на сервис за окно (0 - без ограничения)
+	window      time.Duration    // Длительность окна квоты
+	windowStart time.Time        // Начало текущего окна
+	windowBytes map[string]int64 // Байт записано сервисами в текущем окне
+	totalBytes  map[string]int64 // Байт записано сервисами с запуска сервера
+}
+
+// validateServiceQuota проверяет параметры квоты на объем записи сервисов
+func validateServiceQuota(config *LoggingConfig) error {
+	if config.ServiceByteQuota < 0 {
+		return fmt.Errorf("квота на объем записи сервиса не может быть отрицательной: %d", config.ServiceByteQuota)
+	}
+	if config.ServiceQuotaWindow < 0 {
+		return fmt.Errorf("окно квоты на объем записи не может быть отрицательным: %v", config.ServiceQuotaWindow)
+	}
+	return nil
+}
+
+// newServiceQuota создает учет объема записи с квотой limit байт за окно window
+func newServiceQuota(limit int64, window time.Duration, now time.Time) *serviceQuota {
+	q := &serviceQuota{
+		windowStart: now,
+		windowBytes: make(map[string]int64),
+		totalBytes:  make(map[string]int64),
+	}
+	q.SetLimit(limit, window)
+	return q
+}
+
+// SetLimit меняет квоту и длительность окна; нулевое окно - DEFAULT_SERVICE_QUOTA_WINDOW
+func (q *serviceQuota) SetLimit(limit int64, window time.Duration) {
+	if q == nil {
+		return
+	}
+	if window == 0 {
+		window = time.Duration(DEFAULT_SERVICE_QUOTA_WINDOW) * time.Second
+	}
+
+	q.mu.Lock()
+	q.limit = limit
+	q.window = window
+	q.mu.Unlock()
+}
+
+// Add учитывает n байт, записанных сервисом
+func (q *serviceQuota) Add(service string, n int64, now time.Time) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.resetExpired(now)
+	q.totalBytes[service] += n
+	q.windowBytes[service] += n
+}
+
+// Exceeded проверяет, исчерпал ли сервис квоту в текущем окне
+func (q *serviceQuota) Exceeded(service string, now time.Time) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.limit <= 0 {
+		return false
+	}
+	q.resetExpired(now)
+	return q.windowBytes[service] >= q.limit
+}
+
+// TotalBytes возвращает копию счетчиков байт по сервисам с запуска сервера
+func (q *serviceQuota) TotalBytes() map[string]int64 {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return maps.Clone(q.totalBytes)
+}
+
+// resetExpired начинает новое окно, если текущее истекло (вызывается под q.mu)
+func (q *serviceQuota) resetExpired(now time.Time) {
+	if now.Sub(q.windowStart) < q.window {
+		return
+	}
+	clear(q.windowBytes)
+	q.windowStart = now
+}
@@ -0,0 +1,95 @@
+// quota_test.go - Тесты учета объема записи и квоты сервисов
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestServiceQuotaWindow проверяет превышение квоты и сброс счетчиков окна
+func TestServiceQuotaWindow(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	quota := newServiceQuota(100, time.Minute, now)
+
+	quota.Add("API", 60, now)
+	if quota.Exceeded("API", now) {
+		t.Error("квота не должна быть исчерпана после 60 байт из 100")
+	}
+	quota.Add("API", 40, now.Add(time.Second))
+	if !quota.Exceeded("API", now.Add(time.Second)) {
+		t.Error("квота должна быть исчерпана после 100 байт")
+	}
+	if quota.Exceeded("DB", now.Add(time.Second)) {
+		t.Error("квота одного сервиса не должна влиять на другие")
+	}
+
+	// Новое окно обнуляет счетчики квоты, но не общий объем
+	if quota.Exceeded("API", now.Add(time.Minute)) {
+		t.Error("после окончания окна квота должна восстанавливаться")
+	}
+	if got := quota.TotalBytes()["API"]; got != 100 {
+		t.Errorf("общий объем записи не должен сбрасываться, получено %d", got)
+	}
+
+	// Нулевая квота только считает байты
+	quota.SetLimit(0, 0)
+	quota.Add("API", 1000, now.Add(time.Minute))
+	if quota.Exceeded("API", now.Add(time.Minute)) {
+		t.Error("без квоты сообщения не должны отбрасываться")
+	}
+}
+
+// TestServiceQuotaDropsMessages проверяет, что сервер отбрасывает сообщения сервиса сверх квоты
+func TestServiceQuotaDropsMessages(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.DisableSelfLog = true
+	config.ServiceByteQuota = 100
+	config.Clock = NewFakeClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local))
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	send := func(service, message string) {
+		data, _ := json.Marshal(LogMessage{Service: service, Level: INFO, Message: message})
+		server.handleLogMessage(data, "client")
+		server.batchMu.Lock()
+		for len(server.buffer) > 0 {
+			server.writeBatch = append(server.writeBatch, <-server.buffer)
+		}
+		server.flushBatch()
+		server.batchMu.Unlock()
+	}
+
+	long := strings.Repeat("x", 80)
+	send("NOISY", long) // Первое сообщение записывается и исчерпывает квоту
+	send("NOISY", long)
+	send("QUIET", "в пределах квоты")
+
+	stats := server.Stats()
+	if stats.QuotaDroppedMessages != 1 {
+		t.Errorf("ожидалось одно отброшенное сообщение, получено %d", stats.QuotaDroppedMessages)
+	}
+	if stats.ServiceBytes["NOISY"] <= 80 || stats.ServiceBytes["QUIET"] == 0 {
+		t.Errorf("объем записи должен учитываться по сервисам: %v", stats.ServiceBytes)
+	}
+
+	// После окончания окна сервис снова может писать
+	config.Clock.(*FakeClock).Advance(time.Duration(DEFAULT_SERVICE_QUOTA_WINDOW) * time.Second)
+	send("NOISY", long)
+	if got := server.Stats().QuotaDroppedMessages; got != 1 {
+		t.Errorf("после окончания окна сообщения не должны отбрасываться, отброшено %d", got)
+	}
+
+	config.ServiceByteQuota = -1
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("отрицательная квота должна отклоняться")
+	}
+}
@@ -2,34 +2,142 @@
 package logger
 
 import (
+	"cmp"
 	"fmt"
-
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // SecurityConfig конфигурация безопасности
 type SecurityConfig struct {
 	MaxMessageLength    int            // Максимальная длина сообщения
+	MaxFieldsLength     int            // Максимальный суммарный размер ключей и значений полей (0 - без ограничения)
+	MaxFieldCount       int            // Максимальное количество дополнительных полей (0 - без ограничения)
 	MaxServiceLength    int            // Максимальная длина имени сервиса
 	AllowedServiceChars *regexp.Regexp // Разрешенные символы в именах сервисов
-	RateLimitPerSecond  int            // Ограничение скорости сообщений в секунду
+	RateLimitPerSecond  int            // Ограничение количества сообщений за окно RateWindow
+	RateWindow          time.Duration  // Скользящее окно ограничения скорости (0 - одна секунда)
 	BanDuration         time.Duration  // Длительность бана за превышение лимитов
+	MaxBanDuration      time.Duration  // Предел удвоения бана при повторных нарушениях (0 - без эскалации)
+	BanResetAfter       time.Duration  // Период без банов, после которого эскалация сбрасывается (0 - MaxBanDuration)
+	ExemptServices      []string       // Доверенные сервисы, на которые не действует ограничение скорости
+	Clock               Clock          // Источник времени для окон и банов ограничителя скорости (nil - часы сервера или SystemClock)
+
+	// Проверка содержимого текста и полей сообщения; null-байты отклоняются всегда
+	RejectControlChars bool // Отклонять управляющие символы, кроме \t, \n и \r (например, ESC-последовательности терминала)
+	RequireValidUTF8   bool // Отклонять некорректные последовательности UTF-8
+	Sanitize           bool // Исправлять сообщение вместо отклонения: удалять null-байты и управляющие символы, заменять некорректный UTF-8 на U+FFFD
 }
 
 // DefaultSecurityConfig возвращает конфигурацию безопасности по умолчанию
 func DefaultSecurityConfig() *SecurityConfig {
 	return &SecurityConfig{
-		MaxMessageLength:    4096,                                // 4KB максимум
-		MaxServiceLength:    32,                                  // 32 символа для имени сервиса
-		AllowedServiceChars: regexp.MustCompile(`^[A-Z0-9_-]+$`), // Только заглавные буквы, цифры, _ и -
-		RateLimitPerSecond:  100,                                 // 100 сообщений в секунду на клиента
-		BanDuration:         time.Minute * 5,                     // Бан на 5 минут
+		MaxMessageLength:    4096,                     // 4KB максимум
+		MaxFieldsLength:     4096,                     // 4KB на все поля сообщения
+		MaxServiceLength:    32,                       // 32 символа для имени сервиса
+		AllowedServiceChars: defaultServiceNameRegexp, // Только заглавные буквы, цифры, _ и -
+		RateLimitPerSecond:  100,                      // 100 сообщений в секунду на клиента
+		RateWindow:          time.Second,              // Лимит считается за последнюю секунду
+		BanDuration:         time.Minute * 5,          // Бан на 5 минут
+		MaxBanDuration:      time.Hour,                // Повторные баны удваиваются до часа
+		BanResetAfter:       time.Hour,                // Час без банов сбрасывает эскалацию
+	}
+}
+
+// defaultServiceNameRegexp скомпилированный DEFAULT_SERVICE_NAME_PATTERN
+var defaultServiceNameRegexp = regexp.MustCompile(DEFAULT_SERVICE_NAME_PATTERN)
+
+// securityConfigFor собирает конфигурацию безопасности клиента и сервера
+// За основу берется DefaultSecurityConfig, поверх нее - ненулевые поля
+// LoggingConfig.Security, а затем отдельные параметры LoggingConfig: RateLimit,
+// RateLimitExempt и AllowedServiceChars. LoggingConfig.Security не изменяется.
+// Имя сервиса проверяется после приведения к верхнему регистру
+// (NormalizeServiceName), поэтому шаблон имен должен допускать заглавные буквы.
+func securityConfigFor(config *LoggingConfig) (*SecurityConfig, error) {
+	security := DefaultSecurityConfig()
+	if custom := config.Security; custom != nil {
+		if err := validateSecurityConfig(custom); err != nil {
+			return nil, err
+		}
+		mergeSecurityConfig(security, custom)
+	}
+
+	if config.RateLimit > 0 {
+		security.RateLimitPerSecond = config.RateLimit
+	}
+	if config.RateLimitExempt != nil {
+		security.ExemptServices = config.RateLimitExempt
+	}
+
+	pattern := config.AllowedServiceChars
+	if pattern != "" && pattern != DEFAULT_SERVICE_NAME_PATTERN {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("неверный шаблон имен сервисов %q: %w", pattern, err)
+		}
+		security.AllowedServiceChars = re
 	}
+	return security, nil
+}
+
+// validateSecurityConfig отклоняет отрицательные лимиты LoggingConfig.Security
+func validateSecurityConfig(config *SecurityConfig) error {
+	if config.MaxMessageLength < 0 || config.MaxFieldsLength < 0 || config.MaxFieldCount < 0 || config.MaxServiceLength < 0 || config.RateLimitPerSecond < 0 {
+		return fmt.Errorf("лимиты конфигурации безопасности не могут быть отрицательными")
+	}
+	if config.RateWindow < 0 || config.BanDuration < 0 || config.MaxBanDuration < 0 || config.BanResetAfter < 0 {
+		return fmt.Errorf("длительности конфигурации безопасности не могут быть отрицательными")
+	}
+	return nil
+}
+
+// mergeSecurityConfig переносит в dst ненулевые поля src
+func mergeSecurityConfig(dst, src *SecurityConfig) {
+	if src.MaxMessageLength > 0 {
+		dst.MaxMessageLength = src.MaxMessageLength
+	}
+	if src.MaxFieldsLength > 0 {
+		dst.MaxFieldsLength = src.MaxFieldsLength
+	}
+	if src.MaxFieldCount > 0 {
+		dst.MaxFieldCount = src.MaxFieldCount
+	}
+	if src.MaxServiceLength > 0 {
+		dst.MaxServiceLength = src.MaxServiceLength
+	}
+	if src.AllowedServiceChars != nil {
+		dst.AllowedServiceChars = src.AllowedServiceChars
+	}
+	if src.RateLimitPerSecond > 0 {
+		dst.RateLimitPerSecond = src.RateLimitPerSecond
+	}
+	if src.RateWindow > 0 {
+		dst.RateWindow = src.RateWindow
+	}
+	if src.BanDuration > 0 {
+		dst.BanDuration = src.BanDuration
+	}
+	if src.MaxBanDuration > 0 {
+		dst.MaxBanDuration = src.MaxBanDuration
+	}
+	if src.BanResetAfter > 0 {
+		dst.BanResetAfter = src.BanResetAfter
+	}
+	if src.ExemptServices != nil {
+		dst.ExemptServices = src.ExemptServices
+	}
+	if src.Clock != nil {
+		dst.Clock = src.Clock
+	}
+	dst.RejectControlChars = dst.RejectControlChars || src.RejectControlChars
+	dst.RequireValidUTF8 = dst.RequireValidUTF8 || src.RequireValidUTF8
+	dst.Sanitize = dst.Sanitize || src.Sanitize
 }
 
 // RateLimiter ограничитель скорости для клиентов
@@ -38,14 +146,42 @@ type RateLimiter struct {
 	mu      sync.RWMutex           // Мьютекс для безопасного доступа
 	config  *SecurityConfig        // Конфигурация безопасности
 	done    chan struct{}          // Канал для остановки cleanup горутины
+	clock   Clock                  // Источник времени
+	onBan   func(BanEvent)         // Обработчик банов и их окончания (nil - без уведомлений)
+}
+
+// BanEvent уведомление о бане клиента или об окончании бана
+type BanEvent struct {
+	ClientID string        // Идентификатор клиента
+	Banned   bool          // true - клиент забанен, false - бан истек
+	Duration time.Duration // Длительность бана с учетом эскалации
+	Until    time.Time     // Время окончания бана
+	BanLevel int           // Ступень эскалации (1 - первый бан после периода без нарушений)
 }
 
 // ClientInfo информация о клиенте для rate limiting
 type ClientInfo struct {
 	LastAccess    time.Time // Время последнего доступа
-	MessageCount  int       // Количество сообщений в текущую секунду
+	MessageCount  int       // Количество сообщений за последнее окно RateWindow (заполняется в Snapshot)
 	BannedUntil   time.Time // Время окончания бана
 	TotalMessages int64     // Общее количество сообщений
+	TimesLimited  int64     // Количество отклоненных сообщений
+	TimesBanned   int64     // Количество банов клиента
+	BanLevel      int       // Ступень эскалации бана (0 - банов не было или эскалация сброшена)
+
+	banned bool // Бан действует или его окончание еще не отправлено в onBan
+
+	// Время (UnixNano) последних принятых сообщений, не больше лимита: кольцевой
+	// буфер фиксированного размера, самое старое сообщение по индексу next
+	hits []int64
+	next int
+}
+
+// RateLimitOffender сводка по клиенту, превышавшему лимит скорости
+type RateLimitOffender struct {
+	ClientID     string `json:"client_id"`     // Идентификатор клиента
+	TimesLimited int64  `json:"times_limited"` // Количество отклоненных сообщений
+	TimesBanned  int64  `json:"times_banned"`  // Количество банов
 }
 
 // NewRateLimiter создает новый ограничитель скорости
@@ -54,10 +190,11 @@ func NewRateLimiter(config *SecurityConfig) *RateLimiter {
 		clients: make(map[string]*ClientInfo),
 		config:  config,
 		done:    make(chan struct{}),
+		clock:   clockOrSystem(config.Clock),
 	}
 
 	// Запускаем фоновую очистку старых записей
-	go rl.cleanup()
+	go rl.cleanup(rl.clock.NewTicker(time.Minute * 10)) // Чистим каждые 10 минут
 
 	// Регистрируем финалайзер для гарантированного закрытия горутины
 	runtime.SetFinalizer(rl, func(r *RateLimiter) {
@@ -69,107 +206,454 @@ func NewRateLimiter(config *SecurityConfig) *RateLimiter {
 
 // IsAllowed проверяет, разрешен ли доступ для клиента
 func (rl *RateLimiter) IsAllowed(clientID string) bool {
+	return rl.IsAllowedFor(clientID, "")
+}
+
+// IsAllowedFor проверяет, разрешен ли доступ для клиента, отправляющего сообщение от имени сервиса
+// Сообщения доверенных сервисов (ExemptServices) пропускаются без учета в счетчике клиента
+func (rl *RateLimiter) IsAllowedFor(clientID, service string) bool {
+	var event *BanEvent
+	allowed := rl.isAllowed(clientID, service, &event)
+	if event != nil {
+		rl.notify(*event)
+	}
+	return allowed
+}
+
+// isAllowed проверяет доступ под блокировкой; бан или его окончание сохраняется в event
+// Обработчик вызывается после снятия блокировки: он может писать в лог сервера.
+func (rl *RateLimiter) isAllowed(clientID, service string, event **BanEvent) bool {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	if service != "" {
+		for _, exempt := range rl.config.ExemptServices {
+			if service == exempt {
+				return true
+			}
+		}
+	}
+
+	now := clockOrSystem(rl.clock).Now()
 	client, exists := rl.clients[clientID]
 
 	if !exists {
 		// Новый клиент
-		rl.clients[clientID] = &ClientInfo{
-			LastAccess:    now,
-			MessageCount:  1,
-			TotalMessages: 1,
-		}
-		return true
+		client = &ClientInfo{}
+		rl.clients[clientID] = client
 	}
 
 	// Проверяем, не забанен ли клиент
 	if now.Before(client.BannedUntil) {
+		client.TimesLimited++
 		return false
 	}
-
-	// Сбрасываем счетчик если прошла секунда
-	if now.Sub(client.LastAccess) >= time.Second {
-		client.MessageCount = 0
-		client.LastAccess = now
+	if client.banned {
+		*event = client.unban(clientID)
 	}
 
-	client.MessageCount++
+	client.LastAccess = now
 	client.TotalMessages++
 
-	// Проверяем лимит
-	if client.MessageCount > rl.config.RateLimitPerSecond {
-		// Баним клиента
-		client.BannedUntil = now.Add(rl.config.BanDuration)
+	// Скользящее окно: сообщение принимается, если за предыдущие RateWindow
+	// принято меньше RateLimitPerSecond сообщений. В отличие от сброса счетчика
+	// раз в окно, на границе окон нельзя отправить двойной лимит подряд.
+	client.resize(rl.config.RateLimitPerSecond)
+	if !client.record(now.UnixNano(), rl.window()) {
+		*event = rl.ban(clientID, client, now)
 		return false
 	}
 
 	return true
 }
 
+// ban банит клиента с учетом эскалации и возвращает событие бана
+// Каждый бан, следующий раньше BanResetAfter после окончания предыдущего,
+// вдвое длиннее предыдущего, но не длиннее MaxBanDuration.
+func (rl *RateLimiter) ban(clientID string, client *ClientInfo, now time.Time) *BanEvent {
+	if client.BanLevel > 0 && now.Sub(client.BannedUntil) >= rl.banResetAfter() {
+		client.BanLevel = 0
+	}
+	client.BanLevel++
+
+	duration := rl.banDuration(client.BanLevel)
+	client.BannedUntil = now.Add(duration)
+	client.TimesLimited++
+	client.TimesBanned++
+	client.banned = true
+
+	return &BanEvent{
+		ClientID: clientID,
+		Banned:   true,
+		Duration: duration,
+		Until:    client.BannedUntil,
+		BanLevel: client.BanLevel,
+	}
+}
+
+// unban отмечает окончание бана и возвращает событие
+func (c *ClientInfo) unban(clientID string) *BanEvent {
+	c.banned = false
+	return &BanEvent{ClientID: clientID, Until: c.BannedUntil, BanLevel: c.BanLevel}
+}
+
+// banDuration возвращает длительность бана для ступени эскалации level (с 1)
+func (rl *RateLimiter) banDuration(level int) time.Duration {
+	duration := rl.config.BanDuration
+	limit := max(rl.config.MaxBanDuration, duration)
+	for i := 1; i < level && duration < limit; i++ {
+		duration *= 2
+	}
+	return min(duration, limit)
+}
+
+// banResetAfter возвращает период без банов, сбрасывающий эскалацию
+func (rl *RateLimiter) banResetAfter() time.Duration {
+	if rl.config.BanResetAfter > 0 {
+		return rl.config.BanResetAfter
+	}
+	return max(rl.config.MaxBanDuration, rl.config.BanDuration)
+}
+
+// SetBanHandler задает обработчик банов клиентов и окончания банов
+// Для каждого бана обработчик вызывается один раз при бане и один раз при
+// окончании: при первом сообщении клиента после бана или при очистке таблицы
+// клиентов. Обработчик вызывается без блокировки ограничителя.
+func (rl *RateLimiter) SetBanHandler(handler func(BanEvent)) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.onBan = handler
+}
+
+// notify передает событие обработчику банов
+func (rl *RateLimiter) notify(event BanEvent) {
+	rl.mu.RLock()
+	handler := rl.onBan
+	rl.mu.RUnlock()
+
+	if handler != nil {
+		handler(event)
+	}
+}
+
+// window возвращает окно ограничения скорости
+func (rl *RateLimiter) window() time.Duration {
+	if rl.config.RateWindow <= 0 {
+		return time.Second
+	}
+	return rl.config.RateWindow
+}
+
+// record учитывает сообщение в момент now, если за окно перед ним принято
+// меньше сообщений, чем вмещает буфер. Возвращает false, если лимит исчерпан.
+// Проверка не зависит от лимита: сравнивается только самое старое сообщение.
+func (c *ClientInfo) record(now int64, window time.Duration) bool {
+	if len(c.hits) < cap(c.hits) {
+		c.hits = append(c.hits, now)
+		return true
+	}
+	if len(c.hits) == 0 {
+		return false // Нулевой лимит
+	}
+
+	// Самое старое из последних принятых сообщений должно выйти из окна
+	if now-c.hits[c.next] < int64(window) {
+		return false
+	}
+	c.hits[c.next] = now
+	c.next = (c.next + 1) % len(c.hits)
+	return true
+}
+
+// countSince возвращает количество принятых сообщений позже момента since
+// Используется только в Snapshot, проверка сообщений обходится без подсчета.
+func (c *ClientInfo) countSince(since int64) int {
+	n := len(c.hits)
+	// Буфер упорядочен по времени, начиная с индекса next
+	older := sort.Search(n, func(i int) bool {
+		return c.hits[(c.next+i)%n] > since
+	})
+	return n - older
+}
+
+// resize приводит емкость буфера к лимиту, сохраняя последние сообщения
+// Буфер копируется только при изменении лимита.
+func (c *ClientInfo) resize(limit int) {
+	limit = max(limit, 0)
+	if cap(c.hits) == limit {
+		return
+	}
+
+	n := len(c.hits)
+	hits := make([]int64, 0, limit)
+	for i := max(n-limit, 0); i < n; i++ {
+		hits = append(hits, c.hits[(c.next+i)%n])
+	}
+	c.hits = hits
+	c.next = 0
+}
+
+// Snapshot возвращает копию таблицы клиентов для наблюдения за ограничителем
+func (rl *RateLimiter) Snapshot() map[string]ClientInfo {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	since := clockOrSystem(rl.clock).Now().UnixNano() - int64(rl.window())
+	snapshot := make(map[string]ClientInfo, len(rl.clients))
+	for clientID, client := range rl.clients {
+		info := *client
+		info.MessageCount = client.countSince(since)
+		info.hits = nil // Буфер остается у ограничителя
+		snapshot[clientID] = info
+	}
+	return snapshot
+}
+
+// TopOffenders возвращает до n клиентов с наибольшим числом отклоненных сообщений
+func (rl *RateLimiter) TopOffenders(n int) []RateLimitOffender {
+	offenders := make([]RateLimitOffender, 0)
+	for clientID, client := range rl.Snapshot() {
+		if client.TimesLimited > 0 {
+			offenders = append(offenders, RateLimitOffender{
+				ClientID:     clientID,
+				TimesLimited: client.TimesLimited,
+				TimesBanned:  client.TimesBanned,
+			})
+		}
+	}
+
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].TimesLimited != offenders[j].TimesLimited {
+			return offenders[i].TimesLimited > offenders[j].TimesLimited
+		}
+		return offenders[i].ClientID < offenders[j].ClientID
+	})
+
+	if len(offenders) > n {
+		offenders = offenders[:n]
+	}
+	return offenders
+}
+
+// SetRateLimit изменяет лимит сообщений за окно RateWindow для всех клиентов
+// Буферы клиентов подстраиваются под новый лимит при следующем сообщении.
+func (rl *RateLimiter) SetRateLimit(perSecond int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.config.RateLimitPerSecond = perSecond
+}
+
 // cleanup очищает старые записи клиентов
-func (rl *RateLimiter) cleanup() {
-	ticker := time.NewTicker(time.Minute * 10) // Чистим каждые 10 минут
+func (rl *RateLimiter) cleanup(ticker Ticker) {
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-rl.done:
 			return // Завершаем горутину
-		case <-ticker.C:
-			rl.mu.Lock()
-			now := time.Now()
-
-			for clientID, client := range rl.clients {
-				// Удаляем клиентов, которые не активны более часа
-				if now.Sub(client.LastAccess) > time.Hour {
-					delete(rl.clients, clientID)
-				}
+		case <-ticker.C():
+			for _, event := range rl.sweep() {
+				rl.notify(*event)
 			}
+		}
+	}
+}
+
+// sweep удаляет неактивных клиентов и возвращает события окончания банов,
+// о которых еще не сообщалось (клиент не отправлял сообщений после бана)
+func (rl *RateLimiter) sweep() []*BanEvent {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := clockOrSystem(rl.clock).Now()
+	resetAfter := rl.banResetAfter()
 
-			rl.mu.Unlock()
+	var events []*BanEvent
+	for clientID, client := range rl.clients {
+		if now.Before(client.BannedUntil) {
+			continue
+		}
+		if client.banned {
+			events = append(events, client.unban(clientID))
+		}
+
+		// Удаляем клиентов, которые не активны более часа; ступень эскалации
+		// хранится, пока не истечет период ее сброса
+		if now.Sub(client.LastAccess) > time.Hour && now.Sub(client.BannedUntil) >= resetAfter {
+			delete(rl.clients, clientID)
 		}
 	}
+	return events
+}
+
+// ValidateServiceName проверяет длину и символы имени сервиса
+func ValidateServiceName(service string, config *SecurityConfig) error {
+	if config == nil {
+		return fmt.Errorf("конфигурация не может быть nil")
+	}
+
+	// Проверяем длину имени сервиса
+	if len(service) > config.MaxServiceLength {
+		return fmt.Errorf("имя сервиса слишком длинное: %d > %d", len(service), config.MaxServiceLength)
+	}
+
+	// Проверяем символы в имени сервиса
+	if !config.AllowedServiceChars.MatchString(service) {
+		return fmt.Errorf("недопустимые символы в имени сервиса: %s", service)
+	}
+
+	return nil
+}
+
+// NormalizeServiceName приводит имя сервиса к виду, принятому сервером:
+// без пробелов по краям и в верхнем регистре
+func NormalizeServiceName(service string) string {
+	return strings.ToUpper(strings.TrimSpace(service))
 }
 
 // ValidateMessage проверяет корректность сообщения лога
+// Сообщение не изменяется: в режиме Sanitize проверяется его исправленная копия.
 func ValidateMessage(msg *LogMessage, config *SecurityConfig) error {
+	_, _, err := validateMessage(msg, config)
+	return err
+}
+
+// validateMessage проверяет сообщение и возвращает текст и поля для записи
+// В режиме Sanitize это исправленные копии, иначе - текст и поля msg.
+func validateMessage(msg *LogMessage, config *SecurityConfig) (string, map[string]string, error) {
 	// Проверяем, что параметры не nil
 	if msg == nil {
-		return fmt.Errorf("сообщение не может быть nil")
+		return "", nil, fmt.Errorf("сообщение не может быть nil")
 	}
 	if config == nil {
-		return fmt.Errorf("конфигурация не может быть nil")
+		return "", nil, fmt.Errorf("конфигурация не может быть nil")
 	}
 
 	// Проверяем длину сообщения
 	if len(msg.Message) > config.MaxMessageLength {
-		return fmt.Errorf("сообщение слишком длинное: %d > %d", len(msg.Message), config.MaxMessageLength)
+		return "", nil, fmt.Errorf("сообщение слишком длинное: %d > %d", len(msg.Message), config.MaxMessageLength)
 	}
 
-	// Проверяем длину имени сервиса
-	if len(msg.Service) > config.MaxServiceLength {
-		return fmt.Errorf("имя сервиса слишком длинное: %d > %d", len(msg.Service), config.MaxServiceLength)
+	// Проверяем количество полей
+	if config.MaxFieldCount > 0 && len(msg.Fields) > config.MaxFieldCount {
+		return "", nil, fmt.Errorf("слишком много полей: %d > %d", len(msg.Fields), config.MaxFieldCount)
 	}
 
-	// Проверяем символы в имени сервиса
-	if !config.AllowedServiceChars.MatchString(msg.Service) {
-		return fmt.Errorf("недопустимые символы в имени сервиса: %s", msg.Service)
+	// Проверяем суммарный размер полей
+	if config.MaxFieldsLength > 0 {
+		size := 0
+		for key, value := range msg.Fields {
+			size += len(key) + len(value)
+		}
+		if size > config.MaxFieldsLength {
+			return "", nil, fmt.Errorf("поля сообщения слишком длинные: %d > %d", size, config.MaxFieldsLength)
+		}
+	}
+
+	// Проверяем имя сервиса
+	if err := ValidateServiceName(msg.Service, config); err != nil {
+		return "", nil, err
 	}
 
 	// Проверяем уровень логирования
-	if !msg.Level.IsValid() {
-		return fmt.Errorf("недопустимый уровень логирования: %d", msg.Level)
+	if !msg.Level.IsMessageLevel() {
+		return "", nil, fmt.Errorf("недопустимый уровень логирования: %d", msg.Level)
 	}
 
-	// Проверяем на опасные символы в сообщении
-	if strings.Contains(msg.Message, "\x00") {
-		return fmt.Errorf("сообщение содержит null-байты")
+	// Проверяем на опасные символы в тексте и полях
+	return validateContent(msg, config)
+}
+
+// validateContent проверяет текст и поля сообщения на null-байты, а также, если
+// включено, на управляющие символы и некорректный UTF-8. В режиме Sanitize
+// недопустимые символы удаляются из копий текста и полей, а msg не изменяется.
+// Поле, имя которого после исправления пустое или совпадает с именем другого
+// поля, отклоняет сообщение: иначе значение одного из полей молча потерялось бы.
+func validateContent(msg *LogMessage, config *SecurityConfig) (string, map[string]string, error) {
+	message := msg.Message
+	if issue := contentIssue(message, config); issue != "" {
+		if !config.Sanitize {
+			return "", nil, fmt.Errorf("сообщение содержит %s", issue)
+		}
+		message = sanitizeText(message, config)
 	}
 
-	return nil
+	dirty := false
+	for key, value := range msg.Fields {
+		issue := cmp.Or(contentIssue(key, config), contentIssue(value, config))
+		if issue == "" {
+			continue
+		}
+		if !config.Sanitize {
+			return "", nil, fmt.Errorf("поле %q содержит %s", key, issue)
+		}
+		dirty = true
+	}
+	if !dirty {
+		return message, msg.Fields, nil
+	}
+
+	// Исправленные поля собираются в новую карту: исходная может использоваться отправителем
+	sanitized := make(map[string]string, len(msg.Fields))
+	for key, value := range msg.Fields {
+		clean := sanitizeText(key, config)
+		if clean == "" {
+			return "", nil, fmt.Errorf("имя поля %q пустое после исправления", key)
+		}
+		if _, exists := sanitized[clean]; exists {
+			return "", nil, fmt.Errorf("имя поля %q после исправления совпадает с другим полем", key)
+		}
+		sanitized[clean] = sanitizeText(value, config)
+	}
+	return message, sanitized, nil
+}
+
+// contentIssue возвращает описание недопустимого содержимого текста ("" - текст допустим)
+func contentIssue(text string, config *SecurityConfig) string {
+	if config.RequireValidUTF8 && !utf8.ValidString(text) {
+		return "некорректный UTF-8"
+	}
+	if !config.RejectControlChars {
+		if strings.IndexByte(text, 0) >= 0 {
+			return "null-байты"
+		}
+		return ""
+	}
+	for _, r := range text {
+		if r == 0 {
+			return "null-байты"
+		}
+		if isControlChar(r) {
+			return "управляющие символы"
+		}
+	}
+	return ""
+}
+
+// sanitizeText удаляет из текста недопустимые символы вместо отклонения сообщения
+func sanitizeText(text string, config *SecurityConfig) string {
+	if config.RequireValidUTF8 {
+		text = strings.ToValidUTF8(text, "\uFFFD")
+	}
+	if !config.RejectControlChars {
+		return strings.ReplaceAll(text, "\x00", "")
+	}
+	return strings.Map(func(r rune) rune {
+		if r == 0 || isControlChar(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// isControlChar проверяет, что символ управляющий (C0, DEL или C1), кроме \t, \n и \r
+// Переводы строк в файле лога экранируются, а табуляция не портит вывод терминала.
+func isControlChar(r rune) bool {
+	switch r {
+	case '\t', '\n', '\r':
+		return false
+	}
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f)
 }
 
 // ValidateConfig проверяет безопасность конфигурации
@@ -184,7 +668,12 @@ func ValidateConfig(config *LoggingConfig) error {
 		return fmt.Errorf("путь к файлу лога должен быть абсолютным")
 	}
 
-	if !filepath.IsAbs(config.SocketPath) {
+	if !config.Mode.IsValid() {
+		return fmt.Errorf("неизвестный режим логгера: %q", config.Mode)
+	}
+
+	// В локальном режиме сокет не создается, и путь к нему можно не указывать
+	if !(config.Mode == ModeLocal && config.SocketPath == "") && !filepath.IsAbs(config.SocketPath) {
 		return fmt.Errorf("путь к сокету должен быть абсолютным")
 	}
 
@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"encoding/json"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -68,11 +70,13 @@ func TestNewRateLimiter(t *testing.T) {
 func TestRateLimiterIsAllowed(t *testing.T) {
 	config := DefaultSecurityConfig()
 	config.RateLimitPerSecond = 2 // Устанавливаем низкий лимит для тестирования
+	clock := NewFakeClock(time.Now())
 
 	// Создаем ограничитель без автоматической очистки для контролируемого тестирования
 	limiter := &RateLimiter{
 		clients: make(map[string]*ClientInfo),
 		config:  config,
+		clock:   clock,
 	}
 	clientID := "test-client"
 
@@ -90,21 +94,129 @@ func TestRateLimiterIsAllowed(t *testing.T) {
 		t.Error("третий запрос должен быть заблокирован")
 	}
 
-	// Имитируем сброс времени доступа и бана (вместо ожидания)
+	// Сбрасываем бан и сдвигаем время за пределы окна (вместо ожидания)
 	limiter.mu.Lock()
 	if client, exists := limiter.clients[clientID]; exists {
-		client.LastAccess = time.Now().Add(-2 * time.Second) // Делаем вид, что прошло время
-		client.BannedUntil = time.Time{}                     // Сбрасываем бан
-		// НЕ сбрасываем MessageCount здесь - это сделает сам IsAllowed
+		client.BannedUntil = time.Time{}
 	}
 	limiter.mu.Unlock()
+	clock.Advance(2 * time.Second)
 
-	// Теперь запрос должен быть разрешен (IsAllowed сам сбросит счетчик)
+	// Теперь запрос должен быть разрешен: прежние сообщения вышли из окна
 	if !limiter.IsAllowed(clientID) {
 		t.Error("запрос после сброса лимита должен быть разрешен")
 	}
 }
 
+// TestRateLimiterSteadyRate проверяет, что равномерный поток на границе лимита не ограничивается
+func TestRateLimiterSteadyRate(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 10
+	config.Clock = clock
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	// Ровно 10 сообщений в секунду на протяжении 100 секунд
+	for i := 0; i < 1000; i++ {
+		clock.Advance(100 * time.Millisecond)
+		if !limiter.IsAllowed("steady") {
+			t.Fatalf("сообщение %d равномерного потока не должно ограничиваться", i+1)
+		}
+	}
+
+	if count := limiter.Snapshot()["steady"].MessageCount; count != 10 {
+		t.Errorf("ожидалось 10 сообщений в окне, получено %d", count)
+	}
+
+	// Счетчик в снимке отражает окно на момент снимка, а не последнего сообщения
+	clock.Advance(2 * time.Second)
+	if count := limiter.Snapshot()["steady"].MessageCount; count != 0 {
+		t.Errorf("после окна без сообщений счетчик должен быть 0, получено %d", count)
+	}
+}
+
+// BenchmarkRateLimiterIsAllowed измеряет проверку сообщения при большом лимите:
+// ее стоимость не должна зависеть от размера буфера клиента
+func BenchmarkRateLimiterIsAllowed(b *testing.B) {
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 100000
+	config.RateWindow = time.Nanosecond
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		limiter.IsAllowed("bench")
+	}
+}
+
+// TestRateLimiterSlidingWindow проверяет, что всплеск на границе окон не удваивает лимит
+func TestRateLimiterSlidingWindow(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 10
+	config.Clock = clock
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	// Лимит исчерпан в конце секунды
+	clock.Advance(900 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		if !limiter.IsAllowed("burst") {
+			t.Fatalf("сообщение %d первого всплеска должно быть разрешено", i+1)
+		}
+	}
+
+	// Через 200 мс прежние сообщения еще в окне, хотя началась новая секунда
+	clock.Advance(200 * time.Millisecond)
+	if limiter.IsAllowed("burst") {
+		t.Error("всплеск сверх лимита на границе окон должен ограничиваться")
+	}
+
+	// Всплеск сверх лимита ограничивается и у нового клиента
+	for i := 0; i < 10; i++ {
+		limiter.IsAllowed("fresh")
+	}
+	if limiter.IsAllowed("fresh") {
+		t.Error("сообщение сверх лимита должно ограничиваться")
+	}
+}
+
+// TestRateLimiterRateWindow проверяет настраиваемое окно и изменение лимита
+func TestRateLimiterRateWindow(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 2
+	config.RateWindow = 2 * time.Second
+	config.Clock = clock
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	limiter.IsAllowed("client")
+	clock.Advance(time.Second)
+	limiter.IsAllowed("client")
+
+	// Первое сообщение еще в окне 2 секунды
+	clock.Advance(500 * time.Millisecond)
+	if limiter.IsAllowed("client") {
+		t.Error("третье сообщение за 2 секунды должно ограничиваться")
+	}
+
+	// При увеличении лимита учтенные сообщения сохраняются
+	if !limiter.IsAllowed("other") || !limiter.IsAllowed("other") {
+		t.Fatal("сообщения в пределах лимита должны быть разрешены")
+	}
+	limiter.SetRateLimit(3)
+	if !limiter.IsAllowed("other") || limiter.IsAllowed("other") {
+		t.Error("после увеличения лимита должно разрешаться 3 сообщения за окно")
+	}
+}
+
 /**
  * TestRateLimiterCleanup проверяет очистку старых записей
  * @param t *testing.T - тестовый контекст
@@ -251,6 +363,128 @@ func TestValidateMessage(t *testing.T) {
 	if err := ValidateMessage(invalidMsg5, config); err == nil {
 		t.Error("сообщение с недопустимым уровнем должно быть отклонено")
 	}
+
+	// Тестируем слишком длинные поля
+	invalidMsg6 := &LogMessage{
+		Service:   "MAIN",
+		Level:     INFO,
+		Message:   "test message",
+		Timestamp: time.Now(),
+		Fields:    map[string]string{"payload": strings.Repeat("A", 100*1024)},
+	}
+
+	if err := ValidateMessage(invalidMsg6, config); err == nil {
+		t.Error("сообщение со слишком длинными полями должно быть отклонено")
+	}
+
+	// Без ограничения размер полей не проверяется
+	config.MaxFieldsLength = 0
+	if err := ValidateMessage(invalidMsg6, config); err != nil {
+		t.Errorf("при MaxFieldsLength = 0 поля не должны проверяться: %v", err)
+	}
+}
+
+/**
+ * TestValidateMessageContent проверяет проверки содержимого: null-байты,
+ * управляющие символы, некорректный UTF-8 и режим исправления Sanitize
+ * @param t *testing.T - тестовый контекст
+ */
+func TestValidateMessageContent(t *testing.T) {
+	newMsg := func(message string, fields map[string]string) *LogMessage {
+		return &LogMessage{Service: "MAIN", Level: INFO, Message: message, Fields: fields, Timestamp: time.Now()}
+	}
+
+	// По умолчанию отклоняются только null-байты, в том числе в полях
+	config := DefaultSecurityConfig()
+	if err := ValidateMessage(newMsg("a\x00b", nil), config); err == nil {
+		t.Error("сообщение с null-байтом должно быть отклонено")
+	}
+	if err := ValidateMessage(newMsg("ok", map[string]string{"key": "v\x00"}), config); err == nil {
+		t.Error("поле с null-байтом должно быть отклонено")
+	}
+	if err := ValidateMessage(newMsg("\x1b[31mкрасный\xff", nil), config); err != nil {
+		t.Errorf("без дополнительных проверок сообщение должно проходить: %v", err)
+	}
+
+	config.RejectControlChars = true
+	config.RequireValidUTF8 = true
+	if err := ValidateMessage(newMsg("строка\tс табуляцией\nи переводом\r", nil), config); err != nil {
+		t.Errorf("\\t, \\n и \\r не должны считаться управляющими символами: %v", err)
+	}
+	for _, message := range []string{"\x1b[31mкрасный", "символ\u0085C1", "del\x7f", "байт\xff", "обрезан\xd0"} {
+		if err := ValidateMessage(newMsg(message, nil), config); err == nil {
+			t.Errorf("сообщение %q должно быть отклонено", message)
+		}
+	}
+	if err := ValidateMessage(newMsg("ok", map[string]string{"k\x1b": "v"}), config); err == nil {
+		t.Error("ключ поля с управляющим символом должен быть отклонен")
+	}
+
+	// Sanitize исправляет копию сообщения, не изменяя ни текст, ни карту полей отправителя
+	config.Sanitize = true
+	fields := map[string]string{"bad\x00key": "\x1b[0mзначение\xff", "ok": "норма"}
+	msg := newMsg("\x00\x1b[31mкрасный\xd0\tтекст", fields)
+	if err := ValidateMessage(msg, config); err != nil {
+		t.Fatalf("в режиме Sanitize сообщение не должно отклоняться: %v", err)
+	}
+	if msg.Message != "\x00\x1b[31mкрасный\xd0\tтекст" || len(fields) != 2 || fields["bad\x00key"] != "\x1b[0mзначение\xff" {
+		t.Errorf("ValidateMessage не должна изменять сообщение: %q, %q", msg.Message, msg.Fields)
+	}
+	message, sanitized, err := validateMessage(msg, config)
+	if err != nil {
+		t.Fatalf("в режиме Sanitize сообщение не должно отклоняться: %v", err)
+	}
+	if message != "[31mкрасный\uFFFD\tтекст" {
+		t.Errorf("неожиданный исправленный текст: %q", message)
+	}
+	if sanitized["badkey"] != "[0mзначение\uFFFD" || sanitized["ok"] != "норма" || len(sanitized) != 2 {
+		t.Errorf("неожиданные исправленные поля: %q", sanitized)
+	}
+
+	// Ключи, которые после исправления пустые или совпадают, отклоняют сообщение
+	for _, fields := range []map[string]string{
+		{"a\x00": "1", "a": "2"},
+		{"b\x00": "1", "\x00b": "2"},
+		{"\x00": "1"},
+		{"\x1b": "1", "ok": "2"},
+	} {
+		if err := ValidateMessage(newMsg("ok", fields), config); err == nil {
+			t.Errorf("поля %q должны быть отклонены", fields)
+		}
+	}
+
+	// Без RejectControlChars режим Sanitize удаляет только null-байты
+	config = DefaultSecurityConfig()
+	config.Sanitize = true
+	message, _, err = validateMessage(newMsg("a\x00\x1bb", nil), config)
+	if err != nil || message != "a\x1bb" {
+		t.Errorf("ожидалось удаление только null-байта, получили %q, %v", message, err)
+	}
+}
+
+/**
+ * TestValidateMessageMaxFieldCount проверяет ограничение количества полей
+ * @param t *testing.T - тестовый контекст
+ */
+func TestValidateMessageMaxFieldCount(t *testing.T) {
+	config := DefaultSecurityConfig()
+	config.MaxFieldCount = 2
+
+	msg := &LogMessage{Service: "MAIN", Level: INFO, Message: "m", Timestamp: time.Now(),
+		Fields: map[string]string{"a": "1", "b": "2"}}
+	if err := ValidateMessage(msg, config); err != nil {
+		t.Errorf("сообщение с допустимым количеством полей отклонено: %v", err)
+	}
+
+	msg.Fields["c"] = "3"
+	if err := ValidateMessage(msg, config); err == nil || !strings.Contains(err.Error(), "слишком много полей") {
+		t.Errorf("ожидалась ошибка о количестве полей, получили %v", err)
+	}
+
+	// Отрицательное значение считается ошибкой конфигурации
+	if _, err := securityConfigFor(&LoggingConfig{Security: &SecurityConfig{MaxFieldCount: -1}}); err == nil {
+		t.Error("отрицательный MaxFieldCount должен быть ошибкой")
+	}
 }
 
 /**
@@ -319,6 +553,16 @@ func TestValidateConfig(t *testing.T) {
 		t.Error("конфигурация с пустым путем к сокету должна быть отклонена")
 	}
 
+	// В локальном режиме путь к сокету не нужен
+	invalidConfig4.Mode = ModeLocal
+	if err := ValidateConfig(invalidConfig4); err != nil {
+		t.Errorf("локальный режим без сокета должен проходить проверку: %v", err)
+	}
+	invalidConfig4.Mode = "embedded"
+	if err := ValidateConfig(invalidConfig4); err == nil {
+		t.Error("конфигурация с неизвестным режимом должна быть отклонена")
+	}
+
 	// Тестируем конфигурацию с опасными символами в пути
 	invalidConfig5 := &LoggingConfig{
 		Level:      "INFO",
@@ -370,6 +614,201 @@ func TestRateLimiterMultipleClients(t *testing.T) {
 	}
 }
 
+/**
+ * TestRateLimiterExemptServices проверяет, что доверенные сервисы не ограничиваются
+ * @param t *testing.T - тестовый контекст
+ */
+func TestRateLimiterExemptServices(t *testing.T) {
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 2
+	config.ExemptServices = []string{"MAIN"}
+
+	limiter := &RateLimiter{
+		clients: make(map[string]*ClientInfo),
+		config:  config,
+	}
+
+	// Доверенный сервис может превышать лимит
+	for i := 0; i < 10; i++ {
+		if !limiter.IsAllowedFor("client-1", "MAIN") {
+			t.Fatalf("сообщение %d доверенного сервиса должно быть разрешено", i+1)
+		}
+	}
+
+	// Сообщения доверенного сервиса не расходуют лимит клиента
+	if !limiter.IsAllowedFor("client-1", "API") || !limiter.IsAllowedFor("client-1", "API") {
+		t.Error("первые сообщения обычного сервиса должны быть разрешены")
+	}
+	if limiter.IsAllowedFor("client-1", "API") {
+		t.Error("обычный сервис должен ограничиваться после превышения лимита")
+	}
+
+	// Запросы без сервиса ограничиваются как обычно
+	if limiter.IsAllowed("client-2") && limiter.IsAllowed("client-2") && limiter.IsAllowed("client-2") {
+		t.Error("запросы без сервиса должны ограничиваться")
+	}
+}
+
+// TestRateLimiterSnapshot проверяет счетчики ограничений и копию таблицы клиентов
+func TestRateLimiterSnapshot(t *testing.T) {
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 2
+
+	limiter := &RateLimiter{
+		clients: make(map[string]*ClientInfo),
+		config:  config,
+	}
+
+	// Третье сообщение превышает лимит и банит клиента, четвертое отклоняется из-за бана
+	for i := 0; i < 4; i++ {
+		limiter.IsAllowed("noisy")
+	}
+	limiter.IsAllowed("quiet")
+
+	snapshot := limiter.Snapshot()
+	noisy, ok := snapshot["noisy"]
+	if !ok {
+		t.Fatal("клиент noisy должен быть в снимке")
+	}
+	if noisy.TimesLimited != 2 {
+		t.Errorf("ожидалось 2 отклоненных сообщения, получено %d", noisy.TimesLimited)
+	}
+	if noisy.TimesBanned != 1 {
+		t.Errorf("ожидался 1 бан, получено %d", noisy.TimesBanned)
+	}
+	if snapshot["quiet"].TimesLimited != 0 {
+		t.Error("клиент quiet не должен иметь отклоненных сообщений")
+	}
+
+	// Изменение снимка не должно затрагивать ограничитель
+	noisy.TimesLimited = 100
+	snapshot["noisy"] = noisy
+	if limiter.Snapshot()["noisy"].TimesLimited != 2 {
+		t.Error("снимок должен быть копией таблицы клиентов")
+	}
+
+	offenders := limiter.TopOffenders(DEFAULT_TOP_OFFENDERS)
+	if len(offenders) != 1 || offenders[0].ClientID != "noisy" {
+		t.Errorf("ожидался единственный нарушитель noisy, получено %+v", offenders)
+	}
+}
+
+// TestHandleLogMessageRateLimitService проверяет, что ограничение скорости учитывает сервис из сообщения
+func TestHandleLogMessageRateLimitService(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.RateLimit = 1
+	config.RateLimitExempt = []string{"AUDIT"}
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	api := rawJSON(t, LogMessage{Service: "API", Level: INFO, Message: "сообщение"})
+	audit := rawJSON(t, LogMessage{Service: "AUDIT", Level: INFO, Message: "аудит"})
+
+	if !server.handleLogMessage(api, "client") {
+		t.Fatal("первое сообщение должно быть принято")
+	}
+	if server.handleLogMessage(api, "client") {
+		t.Error("второе сообщение API в секунду должно быть отклонено")
+	}
+	if !server.handleLogMessage(audit, "client") {
+		t.Error("сервис из RateLimitExempt не должен ограничиваться")
+	}
+	if !server.handleLogMessage(json.RawMessage(`"строка"`), "client") {
+		t.Error("некорректные данные пропускаются без ошибки лимита")
+	}
+}
+
+// TestHandleLogMessageInvalidFields проверяет, что сообщение с длинными полями
+// отклоняется и учитывается в статистике, не прерывая обработку
+func TestHandleLogMessageInvalidFields(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	data := rawJSON(t, LogMessage{
+		Service: "API",
+		Level:   INFO,
+		Message: "сообщение",
+		Fields:  map[string]string{"payload": strings.Repeat("A", 100*1024)},
+	})
+	if !server.handleLogMessage(data, "client") {
+		t.Error("некорректное сообщение не должно считаться превышением лимита")
+	}
+	if got := server.Stats().InvalidMessages; got != 1 {
+		t.Errorf("ожидалось 1 отклоненное сообщение, получено %d", got)
+	}
+	if got := server.Stats().InvalidServiceNames; got != 0 {
+		t.Errorf("имя сервиса допустимо, счетчик имен должен быть 0, получено %d", got)
+	}
+	if len(server.buffer) != 0 {
+		t.Error("некорректное сообщение не должно попадать в буфер")
+	}
+}
+
+// TestHandleLogMessageSanitize проверяет, что в режиме Sanitize сервер принимает
+// исправленное сообщение, а сообщение с совпадающими после исправления ключами отклоняет
+func TestHandleLogMessageSanitize(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Security = DefaultSecurityConfig()
+	config.Security.Sanitize = true
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	server.handleLogMessage(rawJSON(t, LogMessage{Service: "API", Level: INFO, Message: "a\x00b",
+		Fields: map[string]string{"k\x00": "v\x00"}}), "client")
+	server.handleLogMessage(rawJSON(t, LogMessage{Service: "API", Level: INFO, Message: "m",
+		Fields: map[string]string{"k\x00": "1", "k": "2"}}), "client")
+
+	if len(server.buffer) != 1 {
+		t.Fatalf("ожидалось 1 принятое сообщение, в буфере %d", len(server.buffer))
+	}
+	msg := <-server.buffer
+	if msg.Message != "ab" || len(msg.Fields) != 1 || msg.Fields["k"] != "v" {
+		t.Errorf("неожиданное исправленное сообщение: %q, %q", msg.Message, msg.Fields)
+	}
+	if got := server.Stats().InvalidMessages; got != 1 {
+		t.Errorf("ожидалось 1 отклоненное сообщение, получено %d", got)
+	}
+}
+
+// TestHandleLogMessageServiceNames проверяет шаблон имен сервисов и счетчик отклоненных имен
+func TestHandleLogMessageServiceNames(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.AllowedServiceChars = `^[A-Za-z0-9_.-]+$`
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	for _, service := range []string{"auth-service", "API.V2", "bad name"} {
+		server.handleLogMessage(rawJSON(t, LogMessage{Service: service, Level: INFO, Message: "сообщение"}), "client")
+	}
+
+	if len(server.buffer) != 2 {
+		t.Errorf("ожидалось 2 принятых сообщения, в буфере %d", len(server.buffer))
+	}
+	stats := server.Stats()
+	if stats.InvalidServiceNames != 1 || stats.InvalidMessages != 1 {
+		t.Errorf("ожидалось одно сообщение с недопустимым именем, получено %d из %d", stats.InvalidServiceNames, stats.InvalidMessages)
+	}
+
+	config = createTestServerConfig(t)
+	config.AllowedServiceChars = `[`
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("ожидалась ошибка для некорректного шаблона имен сервисов")
+	}
+}
+
 /**
  * TestValidateMessageWithNilConfig проверяет валидацию с nil конфигурацией
  * @param t *testing.T - тестовый контекст
@@ -398,3 +837,217 @@ func TestValidateMessageWithNilMessage(t *testing.T) {
 		t.Error("валидация nil сообщения должна возвращать ошибку")
 	}
 }
+
+// TestRateLimiterBanEscalation проверяет удвоение банов, их предел, сброс и уведомления
+func TestRateLimiterBanEscalation(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 1
+	config.BanDuration = time.Minute
+	config.MaxBanDuration = 4 * time.Minute
+	config.BanResetAfter = 10 * time.Minute
+	config.Clock = clock
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	var events []BanEvent
+	limiter.SetBanHandler(func(event BanEvent) { events = append(events, event) })
+
+	// violate превышает лимит и возвращает длительность полученного бана
+	violate := func() time.Duration {
+		t.Helper()
+		clock.Advance(time.Second)
+		limiter.IsAllowed("abuser")
+		if limiter.IsAllowed("abuser") {
+			t.Fatal("второе сообщение в секунду должно быть отклонено")
+		}
+		last := events[len(events)-1]
+		if !last.Banned {
+			t.Fatalf("ожидалось событие бана, получено %+v", last)
+		}
+		clock.Advance(last.Duration)
+		return last.Duration
+	}
+
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		if got := violate(); got != want {
+			t.Errorf("бан %d: ожидалась длительность %s, получена %s", i+1, want, got)
+		}
+	}
+
+	// Сообщения во время бана не создают событий, окончание бана сообщается один раз
+	banned := 0
+	unbanned := 0
+	for _, event := range events {
+		if event.Banned {
+			banned++
+		} else {
+			unbanned++
+		}
+	}
+	if banned != 4 || unbanned != 3 {
+		t.Errorf("ожидалось 4 бана и 3 окончания, получено %d и %d", banned, unbanned)
+	}
+
+	// Окончание бана клиента, который больше не писал, сообщает очистка
+	if swept := limiter.sweep(); len(swept) != 1 || swept[0].Banned {
+		t.Errorf("ожидалось окончание последнего бана, получено %+v", swept)
+	}
+	if swept := limiter.sweep(); len(swept) != 0 {
+		t.Errorf("окончание бана уже отправлено, получено %+v", swept)
+	}
+
+	// После периода без банов эскалация начинается заново
+	clock.Advance(config.BanResetAfter)
+	if got := violate(); got != config.BanDuration {
+		t.Errorf("после сброса ожидался бан %s, получен %s", config.BanDuration, got)
+	}
+	if level := limiter.Snapshot()["abuser"].BanLevel; level != 1 {
+		t.Errorf("ожидалась ступень 1 после сброса, получена %d", level)
+	}
+}
+
+// TestRateLimiterSweepUnban проверяет уведомление об окончании бана клиента, который больше не писал
+func TestRateLimiterSweepUnban(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	config := DefaultSecurityConfig()
+	config.RateLimitPerSecond = 1
+	config.Clock = clock
+
+	limiter := NewRateLimiter(config)
+	defer limiter.Close()
+
+	limiter.IsAllowed("quiet")
+	limiter.IsAllowed("quiet")
+
+	if events := limiter.sweep(); len(events) != 0 {
+		t.Errorf("во время бана событий окончания быть не должно: %+v", events)
+	}
+
+	clock.Advance(config.BanDuration)
+	events := limiter.sweep()
+	if len(events) != 1 || events[0].Banned || events[0].ClientID != "quiet" {
+		t.Fatalf("ожидалось одно окончание бана клиента quiet, получено %+v", events)
+	}
+
+	// Клиент со ступенью эскалации хранится до истечения периода ее сброса
+	clock.Advance(2 * time.Hour)
+	limiter.sweep()
+	if _, ok := limiter.Snapshot()["quiet"]; ok {
+		t.Error("неактивный клиент должен быть удален после сброса эскалации")
+	}
+}
+
+// TestSecurityConfigFor проверяет объединение LoggingConfig.Security со значениями по умолчанию
+func TestSecurityConfigFor(t *testing.T) {
+	custom := &SecurityConfig{
+		MaxMessageLength:   100,
+		RateLimitPerSecond: 5,
+		RateWindow:         2 * time.Second,
+		ExemptServices:     []string{"AUDIT"},
+	}
+	config := &LoggingConfig{Security: custom}
+
+	security, err := securityConfigFor(config)
+	if err != nil {
+		t.Fatalf("ошибка конфигурации безопасности: %v", err)
+	}
+	defaults := DefaultSecurityConfig()
+	if security.MaxMessageLength != 100 || security.RateLimitPerSecond != 5 || security.RateWindow != 2*time.Second {
+		t.Errorf("заданные поля должны переопределять значения по умолчанию: %+v", security)
+	}
+	if security.MaxServiceLength != defaults.MaxServiceLength || security.BanDuration != defaults.BanDuration || security.AllowedServiceChars == nil {
+		t.Errorf("нулевые поля должны получать значения по умолчанию: %+v", security)
+	}
+	if len(security.ExemptServices) != 1 || security.ExemptServices[0] != "AUDIT" {
+		t.Errorf("ожидались исключения из Security, получено %v", security.ExemptServices)
+	}
+	if security == custom {
+		t.Error("конфигурация пользователя не должна использоваться напрямую")
+	}
+
+	// Отдельные параметры LoggingConfig имеют приоритет
+	config.RateLimit = 50
+	config.RateLimitExempt = []string{"HEALTH"}
+	security, err = securityConfigFor(config)
+	if err != nil {
+		t.Fatalf("ошибка конфигурации безопасности: %v", err)
+	}
+	if security.RateLimitPerSecond != 50 || security.ExemptServices[0] != "HEALTH" {
+		t.Errorf("RateLimit и RateLimitExempt должны переопределять Security: %+v", security)
+	}
+	if custom.RateLimitPerSecond != 5 {
+		t.Error("LoggingConfig.Security не должен изменяться")
+	}
+
+	config.Security = &SecurityConfig{BanDuration: -time.Second}
+	if _, err := securityConfigFor(config); err == nil {
+		t.Error("ожидалась ошибка для отрицательной длительности бана")
+	}
+}
+
+// TestNewLogServerSecurityConfig проверяет применение LoggingConfig.Security сервером
+func TestNewLogServerSecurityConfig(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Security = &SecurityConfig{
+		MaxMessageLength:    16,
+		MaxFieldsLength:     32,
+		AllowedServiceChars: regexp.MustCompile(`^[A-Z]+$`),
+	}
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	messages := []LogMessage{
+		{Service: "API", Level: INFO, Message: "короткое"},
+		{Service: "API", Level: INFO, Message: strings.Repeat("x", 17)},
+		{Service: "API", Level: INFO, Message: "поля", Fields: map[string]string{"payload": strings.Repeat("x", 32)}},
+		{Service: "API_V2", Level: INFO, Message: "имя"},
+	}
+	for _, msg := range messages {
+		server.handleLogMessage(rawJSON(t, msg), "client")
+	}
+
+	if len(server.buffer) != 1 {
+		t.Errorf("ожидалось одно принятое сообщение, в буфере %d", len(server.buffer))
+	}
+	if stats := server.Stats(); stats.InvalidMessages != 3 || stats.InvalidServiceNames != 1 {
+		t.Errorf("ожидалось 3 отклоненных сообщения, из них 1 по имени, получено %d и %d", stats.InvalidMessages, stats.InvalidServiceNames)
+	}
+	if config.Security.MaxFieldsLength != 32 {
+		t.Error("сервер не должен изменять LoggingConfig.Security")
+	}
+}
+
+// TestNewLogServerSecurityClock проверяет, что часы из LoggingConfig.Security не
+// заменяются часами сервера, а без них ограничитель использует LoggingConfig.Clock
+func TestNewLogServerSecurityClock(t *testing.T) {
+	serverClock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	securityClock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local))
+
+	config := createTestServerConfig(t)
+	config.Clock = serverClock
+	config.Security = &SecurityConfig{Clock: securityClock}
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+	if server.rateLimiter.clock != securityClock {
+		t.Error("ограничитель скорости должен использовать часы из LoggingConfig.Security")
+	}
+
+	config = createTestServerConfig(t)
+	config.Clock = serverClock
+	server, err = NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+	if server.rateLimiter.clock != serverClock {
+		t.Error("без LoggingConfig.Security ограничитель должен использовать часы сервера")
+	}
+}
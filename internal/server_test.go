@@ -2,16 +2,29 @@
 package logger
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 // createTestServerConfig создает тестовую конфигурацию для сервера
-func createTestServerConfig(t *testing.T) *LoggingConfig {
+func createTestServerConfig(t testing.TB) *LoggingConfig {
 	tmpDir, err := os.MkdirTemp("", "logger_server_test")
 	if err != nil {
 		t.Fatalf("не удалось создать временную директорию: %v", err)
@@ -30,6 +43,9 @@ func createTestServerConfig(t *testing.T) *LoggingConfig {
 		MaxFileSize:   1024 * 1024, // 1MB
 		MaxFiles:      3,
 		FlushInterval: time.Millisecond * 100, // 100ms для быстрых тестов
+
+		// Ноль означает переподключение без ограничения, тестам нужно ограничение
+		ReconnectMaxAttempts: DEFAULT_RECONNECT_ATTEMPTS,
 	}
 }
 
@@ -91,6 +107,56 @@ func TestNewLogServerWithInvalidConfig(t *testing.T) {
 	}
 }
 
+// TestNewLogServerBufferDefaults проверяет значения по умолчанию и отказ для отрицательных параметров буфера и ротации
+func TestNewLogServerBufferDefaults(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.BufferSize = 0
+	config.FlushInterval = 0
+	config.MaxFileSize = 0
+	config.MaxFiles = 0
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("нулевые значения должны заменяться значениями по умолчанию: %v", err)
+	}
+	defer server.Stop()
+
+	// Нулевой буфер не должен превращать канал в небуферизованный
+	if cap(server.buffer) != DEFAULT_BUFFER_SIZE {
+		t.Errorf("ожидалась емкость буфера %d, получено %d", DEFAULT_BUFFER_SIZE, cap(server.buffer))
+	}
+	if config.FlushInterval != time.Duration(DEFAULT_FLUSH_INTERVAL_MS)*time.Millisecond ||
+		config.MaxFileSize != DEFAULT_MAX_FILE_SIZE || config.MaxFiles != DEFAULT_MAX_FILES {
+		t.Errorf("неверные значения по умолчанию: %v %g %d", config.FlushInterval, config.MaxFileSize, config.MaxFiles)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*LoggingConfig)
+		want   string
+	}{
+		{"отрицательный буфер", func(c *LoggingConfig) { c.BufferSize = -1 }, "размер буфера"},
+		{"отрицательный интервал", func(c *LoggingConfig) { c.FlushInterval = -time.Second }, "интервал сброса"},
+		{"отрицательный размер файла", func(c *LoggingConfig) { c.MaxFileSize = -1 }, "размер файла лога"},
+		{"отрицательное количество файлов", func(c *LoggingConfig) { c.MaxFiles = -1 }, "количество файлов"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createTestServerConfig(t)
+			tt.modify(config)
+			if _, err := NewLogServer(config); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ожидалась ошибка с %q, получено: %v", tt.want, err)
+			}
+
+			// Reload проверяет те же параметры
+			if err := server.Reload(config); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Reload должен отклонять отрицательные значения, получено: %v", err)
+			}
+		})
+	}
+}
+
 // TestFormatMessageAsTXT тестирует форматирование сообщения в TXT формат
 func TestFormatMessageAsTXT(t *testing.T) {
 	config := createTestServerConfig(t)
@@ -128,6 +194,39 @@ func TestFormatMessageAsTXT(t *testing.T) {
 	}
 }
 
+// TestFormatMessageAsTXTLevelAlignment проверяет одинаковую ширину заголовка для всех уровней
+func TestFormatMessageAsTXTLevelAlignment(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	var width int
+	for level := TRACE; level <= PANIC; level++ {
+		if level.String() == "UNKNOWN" {
+			t.Fatalf("для уровня %d нет имени в levelNames", level)
+		}
+
+		formatted := server.formatMessageAsTXT(LogMessage{
+			Service:   "TEST",
+			Level:     level,
+			Message:   "сообщение",
+			Timestamp: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+		})
+
+		if level == TRACE {
+			width = len(formatted)
+			continue
+		}
+		if len(formatted) != width {
+			t.Errorf("ширина строки для уровня %s (%d) отличается от TRACE (%d): %q",
+				level, len(formatted), width, formatted)
+		}
+	}
+}
+
 // TestParseLogEntry тестирует парсинг строки лога в LogEntry
 func TestParseLogEntry(t *testing.T) {
 	config := createTestServerConfig(t)
@@ -152,6 +251,22 @@ func TestParseLogEntry(t *testing.T) {
 				Timestamp: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
 			},
 		},
+		{
+			name: "строка с порядковым номером",
+			line: "[TEST ] 01-01-2023 12:00:00 [INFO ] #42 \"тестовое сообщение\"",
+			expected: LogEntry{
+				Service:   "TEST",
+				Level:     INFO,
+				Message:   "тестовое сообщение",
+				Timestamp: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+				Seq:       42,
+			},
+		},
+		{
+			name:    "неверный порядковый номер",
+			line:    "[TEST ] 01-01-2023 12:00:00 [INFO ] #abc \"тестовое сообщение\"",
+			wantErr: true,
+		},
 		{
 			name:    "некорректная строка лога",
 			line:    "некорректная строка",
@@ -190,10 +305,268 @@ func TestParseLogEntry(t *testing.T) {
 			if entry.Message != tt.expected.Message {
 				t.Errorf("неверное сообщение: получено %s, ожидалось %s", entry.Message, tt.expected.Message)
 			}
+
+			if entry.Seq != tt.expected.Seq {
+				t.Errorf("неверный порядковый номер: получено %d, ожидалось %d", entry.Seq, tt.expected.Seq)
+			}
 		})
 	}
 }
 
+// TestLogEntrySeq проверяет сквозную нумерацию записей при пакетной и прямой записи,
+// ротации и перезапуске сервера
+func TestLogEntrySeq(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.DisableSelfLog = true
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+
+	server.batchMu.Lock()
+	for i := 1; i <= 2; i++ {
+		server.writeBatch = append(server.writeBatch, &LogMessage{Service: "API", Level: INFO, Message: fmt.Sprintf("пакет %d", i), Timestamp: time.Now()})
+	}
+	server.flushBatch()
+	server.batchMu.Unlock()
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "напрямую", Timestamp: time.Now()})
+
+	// Номер продолжается после ротации
+	server.mu.Lock()
+	if err := server.rotateIfNeeded(); err != nil {
+		server.mu.Unlock()
+		t.Fatalf("ошибка при ротации: %v", err)
+	}
+	server.mu.Unlock()
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "после ротации", Timestamp: time.Now()})
+
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{})
+	if err != nil {
+		t.Fatalf("ошибка чтения записей: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Seq != 4 {
+		t.Fatalf("ожидалась запись с номером 4 после ротации, получено %+v", entries)
+	}
+	backup, err := os.ReadFile(config.LogFile + ".1")
+	if err != nil {
+		t.Fatalf("не удалось прочитать резервную копию: %v", err)
+	}
+	for i, want := range []string{"#1 ", "#2 ", "#3 "} {
+		if line := strings.Split(string(backup), "\n")[i]; !strings.Contains(line, want) {
+			t.Errorf("строка %d должна содержать номер %q: %s", i+1, want, line)
+		}
+	}
+	server.logFile.file.Close()
+
+	// После перезапуска нумерация продолжается с последнего номера в файле
+	restarted, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := restarted.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer restarted.logFile.file.Close()
+	if seq := atomic.LoadInt64(&restarted.lastSeq); seq != 4 {
+		t.Errorf("после перезапуска ожидался последний номер 4, получено %d", seq)
+	}
+
+	// Пустой после ротации файл: номер берется из резервной копии
+	if err := os.Truncate(config.LogFile, 0); err != nil {
+		t.Fatalf("не удалось очистить файл лога: %v", err)
+	}
+	empty, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := empty.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer empty.logFile.file.Close()
+	if seq := atomic.LoadInt64(&empty.lastSeq); seq != 3 {
+		t.Errorf("при пустом файле ожидался номер 3 из резервной копии, получено %d", seq)
+	}
+}
+
+// TestFlushBatchOrderByTimestamp проверяет сортировку пакета по времени при OrderByTimestamp
+func TestFlushBatchOrderByTimestamp(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	// Сообщения с перемешанным временем; "b1" и "b2" с одинаковым временем
+	offsets := []struct {
+		message string
+		offset  time.Duration
+	}{
+		{"c", 3 * time.Second},
+		{"a", time.Second},
+		{"b1", 2 * time.Second},
+		{"d", 4 * time.Second},
+		{"b2", 2 * time.Second},
+	}
+
+	for _, order := range []bool{false, true} {
+		config := createTestServerConfig(t)
+		config.DisableSelfLog = true
+		config.OrderByTimestamp = order
+
+		server, err := NewLogServer(config)
+		if err != nil {
+			t.Fatalf("не удалось создать сервер: %v", err)
+		}
+		if err := server.initLogFile(); err != nil {
+			t.Fatalf("не удалось инициализировать файл лога: %v", err)
+		}
+
+		server.batchMu.Lock()
+		for _, o := range offsets {
+			server.writeBatch = append(server.writeBatch, &LogMessage{Service: "API", Level: INFO, Message: o.message, Timestamp: base.Add(o.offset)})
+		}
+		server.flushBatch()
+		server.batchMu.Unlock()
+		server.logFile.file.Close()
+
+		entries, err := server.getLogEntries(context.Background(), FilterOptions{})
+		if err != nil {
+			t.Fatalf("ошибка чтения записей: %v", err)
+		}
+		var got []string
+		for _, entry := range entries {
+			got = append(got, entry.Message)
+		}
+
+		want := []string{"c", "a", "b1", "d", "b2"}
+		if order {
+			want = []string{"a", "b1", "b2", "c", "d"}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("OrderByTimestamp=%v: ожидался порядок %v, получено %v", order, want, got)
+		}
+	}
+}
+
+// TestMessageEscapingRoundTrip проверяет, что кавычки, переводы строк и обратные слэши переживают запись и чтение
+func TestMessageEscapingRoundTrip(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	messages := []string{
+		`he said "hi"`,
+		`"в кавычках целиком"`,
+		"первая строка\nвторая строка\r\nтретья",
+		`C:\Program Files\app`,
+		`обратный слэш в конце \`,
+		`экранированная кавычка \" и \n как текст`,
+		`"`,
+	}
+
+	now := time.Now()
+	for _, message := range messages {
+		server.writeMessage(LogMessage{Service: "TEST", Level: INFO, Message: message, Timestamp: now})
+	}
+
+	content, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл лога: %v", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != len(messages) {
+		t.Errorf("каждое сообщение должно занимать одну строку: ожидалось %d строк, получено %d", len(messages), lines)
+	}
+
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{Service: "TEST"})
+	if err != nil {
+		t.Fatalf("ошибка получения записей: %v", err)
+	}
+	if len(entries) != len(messages) {
+		t.Fatalf("ожидалось %d записей, получено %d", len(messages), len(entries))
+	}
+	for i, message := range messages {
+		if entries[i].Message != message {
+			t.Errorf("сообщение %d искажено: ожидалось %q, получено %q", i, message, entries[i].Message)
+		}
+	}
+}
+
+// TestUnescapeMessageLegacy проверяет чтение записей, сделанных без экранирования
+func TestUnescapeMessageLegacy(t *testing.T) {
+	tests := map[string]string{
+		`C:\path\to`:   `C:\path\to`,
+		`he said "hi"`: `he said "hi"`,
+		`конец \`:      `конец \`,
+	}
+	for input, expected := range tests {
+		if got := unescapeMessage(input); got != expected {
+			t.Errorf("unescapeMessage(%q) = %q, ожидалось %q", input, got, expected)
+		}
+	}
+}
+
+// TestLogServerRejectedServices проверяет учет сообщений от сервисов вне списка разрешенных
+func TestLogServerRejectedServices(t *testing.T) {
+	config := createTestServerConfig(t)
+	services := make([]string, 1, 4)
+	services[0] = "API"
+	config.Services = services
+	config.RestrictServices = true
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	send := func(service string) {
+		server.handleLogMessage(rawJSON(t, map[string]interface{}{
+			"service": service,
+			"level":   INFO,
+			"message": "сообщение",
+		}), "client_1")
+	}
+
+	send("API")
+	send("DB")
+	send("DB")
+
+	if got := atomic.LoadInt64(&server.stats.RejectedServiceMessages); got != 2 {
+		t.Errorf("ожидалось 2 отброшенных сообщения, получено %d", got)
+	}
+
+	// Разрешенное сообщение и одно предупреждение о неизвестном сервисе
+	if len(server.buffer) != 2 {
+		t.Fatalf("ожидалось 2 сообщения в буфере, получено %d", len(server.buffer))
+	}
+	<-server.buffer
+	warning := <-server.buffer
+	if warning.Service != SERVER_LOGGER_NAME || warning.Level != WARN || !strings.Contains(warning.Message, "DB (1)") {
+		t.Errorf("неожиданное предупреждение: %+v", warning)
+	}
+
+	// Повторные предупреждения не чаще раза в минуту
+	send("CACHE")
+	if len(server.buffer) != 0 {
+		t.Error("предупреждение не должно повторяться в течение минуты")
+	}
+
+	server.rejectedMu.Lock()
+	server.lastRejectedWarning = time.Now().Add(-time.Duration(DEFAULT_REJECTED_WARNING) * time.Second)
+	server.rejectedMu.Unlock()
+
+	send("DB")
+	if len(server.buffer) != 1 {
+		t.Fatalf("ожидалось новое предупреждение после паузы, в буфере %d сообщений", len(server.buffer))
+	}
+	warning = <-server.buffer
+	if !strings.Contains(warning.Message, "CACHE (1), DB (2)") {
+		t.Errorf("предупреждение должно перечислять накопленные сервисы: %q", warning.Message)
+	}
+}
+
 // TestMatchesFilter тестирует проверку соответствия записи фильтру
 func TestMatchesFilter(t *testing.T) {
 	config := createTestServerConfig(t)
@@ -247,6 +620,66 @@ func TestMatchesFilter(t *testing.T) {
 			filter:   FilterOptions{},
 			expected: true,
 		},
+		{
+			name: "минимальный уровень - запись серьезнее",
+			filter: FilterOptions{
+				MinLevel: func() *LogLevel { l := DEBUG; return &l }(),
+			},
+			expected: true,
+		},
+		{
+			name: "минимальный уровень - запись ниже",
+			filter: FilterOptions{
+				MinLevel: func() *LogLevel { l := WARN; return &l }(),
+			},
+			expected: false,
+		},
+		{
+			name: "точный уровень имеет приоритет над минимальным",
+			filter: FilterOptions{
+				Level:    func() *LogLevel { l := INFO; return &l }(),
+				MinLevel: func() *LogLevel { l := ERROR; return &l }(),
+			},
+			expected: true,
+		},
+		{
+			name: "несколько сервисов - совпадение",
+			filter: FilterOptions{
+				Services: []string{"API", "TEST"},
+			},
+			expected: true,
+		},
+		{
+			name: "несколько сервисов - несовпадение",
+			filter: FilterOptions{
+				Services: []string{"API", "DB"},
+			},
+			expected: false,
+		},
+		{
+			name: "Service объединяется с Services",
+			filter: FilterOptions{
+				Service:  "TEST",
+				Services: []string{"API"},
+			},
+			expected: true,
+		},
+		{
+			name: "несколько сервисов и уровень - несовпадение по уровню",
+			filter: FilterOptions{
+				Services: []string{"API", "TEST"},
+				Level:    func() *LogLevel { l := ERROR; return &l }(),
+			},
+			expected: false,
+		},
+		{
+			name: "несколько сервисов и уровень - совпадение",
+			filter: FilterOptions{
+				Services: []string{"API", "TEST"},
+				Level:    func() *LogLevel { l := INFO; return &l }(),
+			},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -271,7 +704,7 @@ func TestSendError(t *testing.T) {
 	var output strings.Builder
 	encoder := json.NewEncoder(&output)
 
-	server.sendError(encoder, "тестовая ошибка")
+	server.sendError(encoder, ErrorCodeNotFound, "тестовая ошибка")
 
 	// Проверяем, что ошибка была записана в JSON формате
 	result := output.String()
@@ -282,6 +715,10 @@ func TestSendError(t *testing.T) {
 	if !strings.Contains(result, "error") {
 		t.Error("вывод должен содержать поле error")
 	}
+
+	if !strings.Contains(result, `"code":"not_found"`) {
+		t.Errorf("вывод должен содержать код ошибки: %s", result)
+	}
 }
 
 // TestHandlePing тестирует обработку ping запроса
@@ -325,7 +762,7 @@ func TestWriteMessage(t *testing.T) {
 	if err != nil {
 		t.Fatalf("не удалось инициализировать файл лога: %v", err)
 	}
-	defer server.file.Close()
+	defer server.logFile.file.Close()
 
 	msg := LogMessage{
 		Service:   "TEST",
@@ -371,7 +808,7 @@ func TestRotateIfNeeded(t *testing.T) {
 	}
 
 	// Записываем что-то в файл
-	_, _ = server.file.WriteString("тестовые данные")
+	_, _ = server.logFile.file.WriteString("тестовые данные")
 	server.currentSize = 100
 
 	// Выполняем ротацию
@@ -395,7 +832,57 @@ func TestRotateIfNeeded(t *testing.T) {
 		t.Error("файл лога должен быть пустым после ротации")
 	}
 
-	server.file.Close()
+	server.logFile.file.Close()
+}
+
+// TestInitLogFileTerminatesPartialLine проверяет, что запись после прерванной строки начинается с новой строки
+func TestInitLogFileTerminatesPartialLine(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.DisableSelfLog = true
+
+	// Процесс завершился посреди записи строки
+	partial := "[API ] 15-10-2026 06:50:04 [INFO ] \"оборванн"
+	if err := os.WriteFile(config.LogFile, []byte(partial), 0644); err != nil {
+		t.Fatalf("не удалось записать файл лога: %v", err)
+	}
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	if server.currentSize != int64(len(partial))+1 {
+		t.Errorf("размер файла должен учитывать добавленный перевод строки, получено %d", server.currentSize)
+	}
+
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "после перезапуска", Timestamp: time.Now()})
+
+	content, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл лога: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != partial {
+		t.Fatalf("новая запись не должна склеиваться с неполной строкой: %q", content)
+	}
+
+	entry, err := server.parseLogEntry(lines[1])
+	if err != nil || entry.Message != "после перезапуска" {
+		t.Errorf("новая запись должна разбираться, получено %+v, ошибка %v", entry, err)
+	}
+
+	// Файл, оканчивающийся переводом строки, не изменяется
+	server.logFile.file.Close()
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось повторно открыть файл лога: %v", err)
+	}
+	if again, _ := os.ReadFile(config.LogFile); string(again) != string(content) {
+		t.Errorf("целый файл лога не должен изменяться при открытии: %q", again)
+	}
 }
 
 // TestLogServerStart проверяет запуск сервера
@@ -464,41 +951,119 @@ func TestLogServerStop(t *testing.T) {
 	_ = server.Stop()
 }
 
-// TestLogServerGetLogEntries проверяет получение записей логов
-func TestLogServerGetLogEntries(t *testing.T) {
+// closeCountSink приемник, считающий вызовы Close
+type closeCountSink struct {
+	closes atomic.Int32
+}
+
+func (c *closeCountSink) Write(LogEntry) error { return nil }
+func (c *closeCountSink) Close() error         { c.closes.Add(1); return nil }
+
+// TestLogServerClock проверяет, что служебные записи сервера получают время из Config.Clock
+func TestLogServerClock(t *testing.T) {
 	config := createTestServerConfig(t)
+	config.Clock = NewFakeClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local))
+
 	server, err := NewLogServer(config)
 	if err != nil {
 		t.Fatalf("не удалось создать сервер: %v", err)
 	}
-
-	// Инициализируем файл лога
-	err = server.initLogFile()
-	if err != nil {
-		t.Fatalf("не удалось инициализировать файл лога: %v", err)
-	}
-	defer server.file.Close()
-
-	// Записываем тестовые данные в файл лога
-	testLines := []string{
-		"[TEST1    ] 25-07-2025 20:00:00 [INFO ] \"test message 1\"",
-		"[TEST2    ] 25-07-2025 20:00:01 [ERROR] \"test message 2\"",
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
 	}
+	defer server.Stop()
 
-	for _, line := range testLines {
-		_, err := server.file.WriteString(line + "\n")
-		if err != nil {
-			t.Fatalf("не удалось записать в файл лога: %v", err)
+	// Пакет записывается по таймеру управляемых часов без явного Flush
+	clock := config.Clock.(*FakeClock)
+	server.buffer <- &LogMessage{Service: "TEST", Level: INFO, Message: "запись по таймеру", Timestamp: clock.Now()}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		clock.Advance(config.FlushInterval)
+		if content, _ := os.ReadFile(config.LogFile); strings.Contains(string(content), "запись по таймеру") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("пакет должен записываться по срабатыванию таймера управляемых часов")
 		}
+		time.Sleep(time.Millisecond)
 	}
-	_ = server.file.Sync() // Принудительно сбрасываем на диск
 
-	// Получаем записи с пустым фильтром
-	filter := FilterOptions{
-		Limit: 10,
+	waitForLogContent(t, server, "02-01-2020 03:04:05")
+	if start := server.Stats().StartTime; !start.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)) {
+		t.Errorf("время запуска должно браться из управляемых часов, получено %v", start)
 	}
-	entries, err := server.getLogEntries(filter)
-	if err != nil {
+}
+
+// TestLogServerStopClearsFinalizer проверяет, что после Stop сервер собирается без повторной остановки финалайзером
+func TestLogServerStopClearsFinalizer(t *testing.T) {
+	config := createTestServerConfig(t)
+	sink := &closeCountSink{}
+	server, err := NewLogServer(config, sink)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	if err := server.Stop(); err != nil {
+		t.Fatalf("ошибка остановки сервера: %v", err)
+	}
+
+	// Очистка выполняется после освобождения памяти сервера; с финалайзером
+	// объект пережил бы первую сборку мусора
+	collected := make(chan struct{})
+	runtime.AddCleanup(server, func(ch chan struct{}) { close(ch) }, collected)
+	server = nil
+	runtime.GC()
+	select {
+	case <-collected:
+	case <-time.After(time.Second):
+		t.Error("остановленный сервер должен собираться первой сборкой мусора")
+	}
+
+	// Даем возможному финалайзеру выполниться: приемники закрываются только одной остановкой
+	runtime.GC()
+	time.Sleep(50 * time.Millisecond)
+	if closes := sink.closes.Load(); closes != 1 {
+		t.Errorf("Stop должен выполняться один раз, приемник закрыт %d раз", closes)
+	}
+}
+
+// TestLogServerGetLogEntries проверяет получение записей логов
+func TestLogServerGetLogEntries(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+
+	// Инициализируем файл лога
+	err = server.initLogFile()
+	if err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	// Записываем тестовые данные в файл лога
+	testLines := []string{
+		"[TEST1    ] 25-07-2025 20:00:00 [INFO ] \"test message 1\"",
+		"[TEST2    ] 25-07-2025 20:00:01 [ERROR] \"test message 2\"",
+	}
+
+	for _, line := range testLines {
+		_, err := server.logFile.file.WriteString(line + "\n")
+		if err != nil {
+			t.Fatalf("не удалось записать в файл лога: %v", err)
+		}
+	}
+	_ = server.logFile.file.Sync() // Принудительно сбрасываем на диск
+
+	// Получаем записи с пустым фильтром
+	filter := FilterOptions{
+		Limit: 10,
+	}
+	entries, err := server.getLogEntries(context.Background(), filter)
+	if err != nil {
 		t.Fatalf("ошибка получения записей: %v", err)
 	}
 
@@ -533,7 +1098,7 @@ func TestLogServerGetLogEntriesWithFilter(t *testing.T) {
 	if err != nil {
 		t.Fatalf("не удалось инициализировать файл лога: %v", err)
 	}
-	defer server.file.Close()
+	defer server.logFile.file.Close()
 
 	// Записываем тестовые данные с разными сервисами и уровнями
 	testLines := []string{
@@ -543,19 +1108,19 @@ func TestLogServerGetLogEntriesWithFilter(t *testing.T) {
 	}
 
 	for _, line := range testLines {
-		_, err := server.file.WriteString(line + "\n")
+		_, err := server.logFile.file.WriteString(line + "\n")
 		if err != nil {
 			t.Fatalf("не удалось записать в файл лога: %v", err)
 		}
 	}
-	_ = server.file.Sync()
+	_ = server.logFile.file.Sync()
 
 	// Фильтруем по сервису
 	apiFilter := FilterOptions{
 		Limit:   10,
 		Service: "API",
 	}
-	apiEntries, err := server.getLogEntries(apiFilter)
+	apiEntries, err := server.getLogEntries(context.Background(), apiFilter)
 	if err != nil {
 		t.Fatalf("ошибка получения записей API: %v", err)
 	}
@@ -569,7 +1134,7 @@ func TestLogServerGetLogEntriesWithFilter(t *testing.T) {
 		Limit: 10,
 		Level: &errorLevel,
 	}
-	errorEntries, err := server.getLogEntries(errorFilter)
+	errorEntries, err := server.getLogEntries(context.Background(), errorFilter)
 	if err != nil {
 		t.Fatalf("ошибка получения записей ERROR: %v", err)
 	}
@@ -584,7 +1149,7 @@ func TestLogServerGetLogEntriesWithFilter(t *testing.T) {
 		Service: "API",
 		Level:   &apiErrorLevel,
 	}
-	apiErrorEntries, err := server.getLogEntries(apiErrorFilter)
+	apiErrorEntries, err := server.getLogEntries(context.Background(), apiErrorFilter)
 	if err != nil {
 		t.Fatalf("ошибка получения записей API ERROR: %v", err)
 	}
@@ -593,6 +1158,48 @@ func TestLogServerGetLogEntriesWithFilter(t *testing.T) {
 	}
 }
 
+// TestFlushBatchReleasesMessages проверяет возврат записанных сообщений в пул
+func TestFlushBatchReleasesMessages(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.CacheSize = 10
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	msg := GetLogMessage()
+	msg.Service = "POOL"
+	msg.Level = WARN
+	msg.Message = "из пула"
+	msg.Timestamp = time.Now()
+	msg.Fields = map[string]string{"key": "value"}
+
+	server.batchMu.Lock()
+	server.writeBatch = append(server.writeBatch, msg)
+	server.flushBatch()
+	server.batchMu.Unlock()
+
+	if len(server.writeBatch) != 0 || cap(server.writeBatch) == 0 {
+		t.Errorf("пакет должен быть очищен для переиспользования: len=%d", len(server.writeBatch))
+	}
+	if msg.Service != "" || msg.Message != "" || msg.Fields != nil || msg.Level != 0 {
+		t.Errorf("сообщение должно быть возвращено в пул очищенным: %+v", msg)
+	}
+
+	// Запись кеша не зависит от возвращенного в пул сообщения
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{Service: "POOL"})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ожидалась одна запись, получено %d (%v)", len(entries), err)
+	}
+	if entries[0].Message != "из пула" || entries[0].Fields["key"] != "value" {
+		t.Errorf("запись повреждена после возврата сообщения в пул: %+v", entries[0])
+	}
+}
+
 // TestLogServerFlush проверяет сброс буфера
 func TestLogServerFlush(t *testing.T) {
 	config := createTestServerConfig(t)
@@ -606,7 +1213,7 @@ func TestLogServerFlush(t *testing.T) {
 	if err != nil {
 		t.Fatalf("не удалось инициализировать файл лога: %v", err)
 	}
-	defer server.file.Close()
+	defer server.logFile.file.Close()
 
 	// Добавляем сообщения в writeBatch (пакет для записи)
 	testMessage := LogMessage{
@@ -617,7 +1224,7 @@ func TestLogServerFlush(t *testing.T) {
 	}
 
 	server.batchMu.Lock()
-	server.writeBatch = append(server.writeBatch, testMessage)
+	server.writeBatch = append(server.writeBatch, &testMessage)
 	initialBatchSize := len(server.writeBatch)
 	server.batchMu.Unlock()
 
@@ -641,8 +1248,9 @@ func TestLogServerResourceMonitor(t *testing.T) {
 		t.Fatalf("не удалось создать сервер: %v", err)
 	}
 
-	// Запускаем мониторинг в отдельной горутине
+	// Запускаем мониторинг в отдельной горутине, регистрируя его в WaitGroup как Start
 	done := make(chan bool, 1)
+	server.wg.Add(1)
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -670,6 +1278,244 @@ func TestLogServerResourceMonitor(t *testing.T) {
 	}
 }
 
+// TestLogServerStatsInterval проверяет запись статистики с периодом StatsInterval
+func TestLogServerStatsInterval(t *testing.T) {
+	for _, interval := range []time.Duration{5 * time.Minute, -1} {
+		config := createTestServerConfig(t)
+		config.Clock = NewFakeClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local))
+		config.StatsInterval = interval
+
+		server, err := NewLogServer(config)
+		if err != nil {
+			t.Fatalf("не удалось создать сервер: %v", err)
+		}
+		clock := config.Clock.(*FakeClock)
+
+		server.wg.Add(1)
+		go server.resourceMonitor()
+
+		// Таймеры создаются в горутине мониторинга, поэтому время переводится до первого срабатывания
+		var msg *LogMessage
+		deadline := time.Now().Add(500 * time.Millisecond)
+		for msg == nil && time.Now().Before(deadline) {
+			clock.Advance(5 * time.Minute)
+			select {
+			case msg = <-server.buffer:
+			case <-time.After(time.Millisecond):
+			}
+		}
+
+		if interval > 0 && (msg == nil || msg.Fields[EVENT_FIELD] != EventStats) {
+			t.Errorf("ожидалась запись статистики каждые %v, получено %+v", interval, msg)
+		}
+		if interval < 0 && msg != nil {
+			t.Errorf("при отрицательном StatsInterval статистика не должна записываться, получено %+v", msg)
+		}
+
+		_ = server.Stop()
+	}
+}
+
+// TestLogServerCheckMemory проверяет порог памяти и очистку кеша только по ClearCacheOnPressure
+func TestLogServerCheckMemory(t *testing.T) {
+	for _, clear := range []bool{false, true} {
+		config := createTestServerConfig(t)
+		config.CacheSize = 10
+		config.MaxMemory = 1 // Порог заведомо превышен
+		config.DisableForcedGC = true
+		config.ClearCacheOnPressure = clear
+
+		server, err := NewLogServer(config)
+		if err != nil {
+			t.Fatalf("не удалось создать сервер: %v", err)
+		}
+
+		server.putToCache(LogEntry{Service: "TEST", Level: INFO, Message: "запись", Timestamp: time.Now()})
+		server.checkMemory()
+
+		if atomic.LoadInt64(&server.stats.MemoryUsage) <= 0 {
+			t.Error("потребление памяти должно быть измерено")
+		}
+		if size := server.cache.GetStats().Size; clear && size != 0 {
+			t.Errorf("при ClearCacheOnPressure кеш должен очищаться, записей: %d", size)
+		} else if !clear && size != 1 {
+			t.Errorf("без ClearCacheOnPressure кеш не должен очищаться, записей: %d", size)
+		}
+
+		_ = server.Stop()
+	}
+
+	config := createTestServerConfig(t)
+	config.MonitorInterval = -time.Second
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("ожидалась ошибка для отрицательного MonitorInterval")
+	}
+	config.MonitorInterval = 0
+	config.MaxMemory = -1
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("ожидалась ошибка для отрицательного MaxMemory")
+	}
+}
+
+// BenchmarkMemoryMeasurement сравнивает измерение памяти с принудительной сборкой мусора и без нее
+func BenchmarkMemoryMeasurement(b *testing.B) {
+	// Куча размером с типичный кеш записей сервера
+	entries := make([]*LogEntry, 10000)
+	for i := range entries {
+		entries[i] = &LogEntry{Service: "BENCH", Message: fmt.Sprintf("запись номер %d", i)}
+	}
+
+	var memStats runtime.MemStats
+	b.Run("ReadMemStats", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			runtime.ReadMemStats(&memStats)
+		}
+	})
+	b.Run("GC+ReadMemStats", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			runtime.GC()
+			runtime.ReadMemStats(&memStats)
+		}
+	})
+	runtime.KeepAlive(entries)
+}
+
+// BenchmarkHandleLogMessage измеряет разбор кадра сообщения лога и его постановку в буфер
+func BenchmarkHandleLogMessage(b *testing.B) {
+	config := createTestServerConfig(b)
+	config.RateLimitExempt = []string{"API"}
+	server, err := NewLogServer(config)
+	if err != nil {
+		b.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	frame := []byte(`{"type":"log","data":{"service":"API","level":1,"message":"Запрос обработан за 12 мс",` +
+		`"timestamp":"2024-01-15T14:30:23.123456789+03:00","fields":{"method":"GET","path":"/api/v1/users","status":"200"}}}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var request requestMessage
+		if err := json.Unmarshal(frame, &request); err != nil {
+			b.Fatal(err)
+		}
+		server.handleLogMessage(request.Data, "bench")
+		<-server.buffer
+	}
+}
+
+// TestBatchBufferSize проверяет оценку размера буфера пакета по средней длине строк
+func TestBatchBufferSize(t *testing.T) {
+	server := &LogServer{}
+
+	if got, want := server.batchBufferSize(10), 10*(DEFAULT_LINE_LENGTH+DEFAULT_LINE_LENGTH/8); got != want {
+		t.Errorf("начальная оценка: ожидалось %d, получено %d", want, got)
+	}
+
+	// Первый пакет задает среднюю длину
+	server.recordLineLength(4000, 10)
+	if server.avgLineLen != 400 {
+		t.Errorf("ожидалась средняя длина 400, получено %d", server.avgLineLen)
+	}
+	if got, want := server.batchBufferSize(10), 10*450; got != want {
+		t.Errorf("ожидалась оценка %d, получено %d", want, got)
+	}
+
+	// Следующие пакеты сглаживаются
+	server.recordLineLength(800, 10)
+	if server.avgLineLen != 320 {
+		t.Errorf("ожидалась средняя длина 320, получено %d", server.avgLineLen)
+	}
+
+	// Пустой пакет не меняет оценку
+	server.recordLineLength(0, 0)
+	if server.avgLineLen != 320 {
+		t.Errorf("пустой пакет изменил среднюю длину: %d", server.avgLineLen)
+	}
+}
+
+// TestServiceColumnWidth проверяет ширину колонки сервиса для сервиса, появившегося во время работы
+func TestServiceColumnWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		want  []string
+	}{
+		{"ширина растет", 0, []string{"[API ]", "[RUNTIME_SERVICE]", "[API            ]"}},
+		{"фиксированная ширина", 8, []string{"[API     ]", "[RUNTIME_SERVICE]", "[API     ]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createTestServerConfig(t)
+			config.DisableSelfLog = true
+			config.Services = []string{"API"}
+			config.ServiceNameWidth = tt.width
+
+			server, err := NewLogServer(config)
+			if err != nil {
+				t.Fatalf("не удалось создать сервер: %v", err)
+			}
+			defer server.logFile.file.Close()
+
+			// Сервис не из конфигурации длиннее всех известных
+			for _, service := range []string{"API", "RUNTIME_SERVICE", "API"} {
+				server.writeMessage(LogMessage{Service: service, Level: INFO, Message: "сообщение", Timestamp: time.Now()})
+			}
+
+			content, err := os.ReadFile(config.LogFile)
+			if err != nil {
+				t.Fatalf("не удалось прочитать файл лога: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("ожидалось %d строк, получено %q", len(tt.want), lines)
+			}
+			for i, prefix := range tt.want {
+				if !strings.HasPrefix(lines[i], prefix+" ") {
+					t.Errorf("строка %d: ожидалось начало %q, получено %q", i+1, prefix, lines[i])
+				}
+			}
+		})
+	}
+
+	config := createTestServerConfig(t)
+	config.ServiceNameWidth = -1
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("отрицательная ширина колонки должна отклоняться")
+	}
+}
+
+// BenchmarkAppendMessageTXT измеряет форматирование сообщения в TXT формат
+// при записи пакета в общий буфер
+func BenchmarkAppendMessageTXT(b *testing.B) {
+	server := &LogServer{maxServiceLen: 5, maxLevelLen: 5}
+	msg := LogMessage{
+		Service:   "API",
+		Level:     INFO,
+		Message:   "Запрос обработан за 12 мс",
+		Timestamp: time.Date(2024, 1, 15, 14, 30, 23, 0, time.UTC),
+	}
+
+	run := func(b *testing.B, msg LogMessage) {
+		var builder strings.Builder
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			// Буфер переиспользуется, как в flushBatch при записи пакета
+			if builder.Len() > 64*1024 {
+				builder.Reset()
+			}
+			server.appendMessageTXT(&builder, &msg)
+			builder.WriteByte('\n')
+		}
+	}
+
+	b.Run("NoFields", func(b *testing.B) { run(b, msg) })
+
+	msg.Fields = map[string]string{"method": "GET", "path": "/api/v1/users", "status": "200"}
+	b.Run("ThreeFields", func(b *testing.B) { run(b, msg) })
+}
+
 // TestLogServerLogStatsAsJSON проверяет вывод статистики в JSON
 func TestLogServerLogStatsAsJSON(t *testing.T) {
 	config := createTestServerConfig(t)
@@ -693,3 +1539,2076 @@ func TestLogServerLogStatsAsJSON(t *testing.T) {
 
 	server.logStatsAsJSON()
 }
+
+// TestLogServerReload проверяет перезагрузку конфигурации без перезапуска
+func TestLogServerReload(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	defer server.Stop()
+
+	listener := server.listener
+
+	newConfig := *config
+	newConfig.Level = "error"
+	newConfig.MaxFileSize = 5
+	newConfig.MaxFiles = 7
+	newConfig.FlushInterval = 50 * time.Millisecond
+	newConfig.RateLimit = 5
+
+	if err := server.Reload(&newConfig); err != nil {
+		t.Fatalf("ошибка перезагрузки: %v", err)
+	}
+
+	server.mu.RLock()
+	if server.minLevel != ERROR {
+		t.Errorf("ожидался уровень ERROR, получен %v", server.minLevel)
+	}
+	if server.config.MaxFileSize != 5 || server.config.MaxFiles != 7 {
+		t.Error("параметры ротации не обновлены")
+	}
+	if server.config.FlushInterval != 50*time.Millisecond {
+		t.Error("интервал сброса не обновлен")
+	}
+	if server.listener != listener {
+		t.Error("слушатель не должен пересоздаваться при перезагрузке")
+	}
+	server.mu.RUnlock()
+
+	if server.securityConfig.RateLimitPerSecond != 5 {
+		t.Errorf("ожидался лимит 5, получен %d", server.securityConfig.RateLimitPerSecond)
+	}
+
+	// Изменение путей требует перезапуска
+	badConfig := newConfig
+	badConfig.LogFile = config.LogFile + ".other"
+	if err := server.Reload(&badConfig); err == nil {
+		t.Error("ожидалась ошибка при изменении LogFile")
+	}
+
+	badConfig = newConfig
+	badConfig.SocketPath = config.SocketPath + ".other"
+	if err := server.Reload(&badConfig); err == nil {
+		t.Error("ожидалась ошибка при изменении SocketPath")
+	}
+
+	badConfig = newConfig
+	badConfig.Level = "verbose"
+	if err := server.Reload(&badConfig); err == nil {
+		t.Error("ожидалась ошибка для неизвестного уровня")
+	}
+
+	if err := server.Reload(nil); err == nil {
+		t.Error("ожидалась ошибка для nil конфигурации")
+	}
+
+	// Неудачные перезагрузки не должны менять уровень
+	if server.minLevel != ERROR {
+		t.Errorf("уровень изменился после неудачной перезагрузки: %v", server.minLevel)
+	}
+}
+
+// TestLogServerPermissions проверяет настраиваемые права доступа к файлу лога и сокету
+func TestLogServerPermissions(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.FileMode = 0600
+	config.SocketMode = 0660
+	config.SocketGroup = strconv.Itoa(os.Getgid())
+
+	// Существующий файл с более широкими правами должен получить заданные права
+	if err := os.WriteFile(config.LogFile, nil, 0644); err != nil {
+		t.Fatalf("не удалось создать файл лога: %v", err)
+	}
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	defer server.Stop()
+
+	if info, err := os.Stat(config.LogFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("ожидались права 0600 для файла лога, получено %v (ошибка: %v)", info.Mode().Perm(), err)
+	}
+	if info, err := os.Stat(config.SocketPath); err != nil || info.Mode().Perm() != 0660 {
+		t.Errorf("ожидались права 0660 для сокета, получено %v (ошибка: %v)", info.Mode().Perm(), err)
+	}
+
+	// Права по умолчанию
+	server = &LogServer{config: &LoggingConfig{}}
+	if server.fileMode() != os.FileMode(DEFAULT_FILE_PERMISSIONS) || server.socketMode() != os.FileMode(DEFAULT_SOCKET_PERMISSIONS) {
+		t.Error("при нулевых значениях должны использоваться права по умолчанию")
+	}
+
+	// Некорректные значения отклоняются
+	for _, mutate := range []func(*LoggingConfig){
+		func(c *LoggingConfig) { c.FileMode = os.ModeDir | 0644 },
+		func(c *LoggingConfig) { c.SocketMode = os.ModeSocket | 0666 },
+		func(c *LoggingConfig) { c.SocketGroup = "несуществующая_группа" },
+		func(c *LoggingConfig) { c.SocketGroup = "-5" },
+	} {
+		bad := createTestServerConfig(t)
+		mutate(bad)
+		if _, err := NewLogServer(bad); err == nil {
+			t.Error("ожидалась ошибка для некорректных прав доступа")
+		}
+	}
+}
+
+// TestLogServerDirMode проверяет права создаваемых директорий и понятную ошибку при файле в пути
+func TestLogServerDirMode(t *testing.T) {
+	config := createTestServerConfig(t)
+	logDir := filepath.Join(filepath.Dir(config.LogFile), "hardened", "logs")
+	config.LogFile = filepath.Join(logDir, "test.log")
+	config.DirMode = 0700
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	server.Stop()
+
+	for _, dir := range []string{logDir, filepath.Dir(logDir)} {
+		if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("ожидались права 0700 для %s, получено %v (ошибка: %v)", dir, info.Mode().Perm(), err)
+		}
+	}
+
+	// Файл на месте директории в пути к логу
+	config = createTestServerConfig(t)
+	blocker := filepath.Join(filepath.Dir(config.LogFile), "not_a_dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("не удалось создать файл: %v", err)
+	}
+	config.LogFile = filepath.Join(blocker, "logs", "test.log")
+
+	_, err = NewLogServer(config)
+	if err == nil {
+		t.Fatal("ожидалась ошибка, когда в пути к логу находится файл")
+	}
+	if !strings.Contains(err.Error(), blocker+" является файлом") {
+		t.Errorf("ошибка должна указывать на мешающий файл %s: %v", blocker, err)
+	}
+}
+
+// TestLogServerConnectionLimits проверяет настраиваемые лимиты подключений и размера сообщений
+func TestLogServerConnectionLimits(t *testing.T) {
+	// Нулевые значения заменяются значениями по умолчанию
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if server.maxConnections != DEFAULT_MAX_CONNECTIONS || server.maxMessageSize != DEFAULT_MAX_MESSAGE_SIZE {
+		t.Errorf("ожидались лимиты по умолчанию, получено %d и %d", server.maxConnections, server.maxMessageSize)
+	}
+	_ = server.Stop()
+
+	// Отрицательные значения отклоняются
+	for _, mutate := range []func(*LoggingConfig){
+		func(c *LoggingConfig) { c.MaxConnections = -1 },
+		func(c *LoggingConfig) { c.MaxMessageSize = -1 },
+	} {
+		bad := createTestServerConfig(t)
+		mutate(bad)
+		if _, err := NewLogServer(bad); err == nil {
+			t.Error("ожидалась ошибка для отрицательных лимитов")
+		}
+	}
+
+	// Подключение сверх лимита закрывается сервером
+	config = createTestServerConfig(t)
+	config.MaxConnections = 1
+	server, err = NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	defer server.Stop()
+
+	first, err := net.Dial("unix", config.SocketPath)
+	if err != nil {
+		t.Fatalf("не удалось подключиться: %v", err)
+	}
+	defer first.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&server.stats.CurrentClients) < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	second, err := net.Dial("unix", config.SocketPath)
+	if err != nil {
+		t.Fatalf("не удалось подключиться: %v", err)
+	}
+	defer second.Close()
+
+	// Перед закрытием сервер сообщает о перегрузке
+	_ = second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	decoder := json.NewDecoder(second)
+	var response ProtocolMessage
+	if err := decoder.Decode(&response); err != nil {
+		t.Fatalf("ожидалось сообщение об отказе, получено: %v", err)
+	}
+	if !isCapacityError(&response) {
+		t.Errorf("ожидалась ошибка о лимите подключений, получено %+v", response)
+	}
+	if err := decoder.Decode(&response); err != io.EOF {
+		t.Errorf("ожидалось закрытие подключения сверх лимита, получено: %v", err)
+	}
+	if atomic.LoadInt64(&server.stats.RejectedConnections) != 1 {
+		t.Errorf("ожидалось 1 отклоненное подключение, получено %d", server.stats.RejectedConnections)
+	}
+
+	// Клиент получает понятную ошибку и не повторяет попытки до истечения паузы
+	client := &LogClient{config: config}
+	if err := client.connect(); !errors.Is(err, ErrServerAtCapacity) {
+		t.Fatalf("ожидалась ошибка о перегрузке сервера, получено: %v", err)
+	}
+
+	start := time.Now()
+	if err := reconnectLocked(client); !errors.Is(err, ErrServerAtCapacity) {
+		t.Fatalf("ожидалась ошибка о перегрузке сервера при переподключении, получено: %v", err)
+	}
+	if client.capacityUntil.IsZero() {
+		t.Error("после отказа должна быть установлена пауза переподключения")
+	}
+	if err := reconnectLocked(client); !errors.Is(err, ErrServerAtCapacity) {
+		t.Errorf("во время паузы переподключение должно сразу возвращать ошибку, получено: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("при перегрузке сервера не должно быть частых повторов, прошло %v", elapsed)
+	}
+}
+
+// startTestServerWithClient запускает сервер и подключает к нему клиента
+func startTestServerWithClient(t testing.TB, config *LoggingConfig) (*LogServer, *LogClient) {
+	t.Helper()
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+
+	client, err := NewLogClient(config)
+	if err != nil {
+		_ = server.Stop()
+		t.Fatalf("не удалось создать клиент: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Stop()
+	})
+
+	return server, client
+}
+
+// rawJSON кодирует данные запроса так, как их получает обработчик сервера
+func rawJSON(t testing.TB, v interface{}) json.RawMessage {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("не удалось закодировать данные: %v", err)
+	}
+	return data
+}
+
+// waitForLogContent сбрасывает буфер сервера до появления подстроки в файле лога
+func waitForLogContent(t *testing.T, server *LogServer, substr string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		server.Flush()
+		if content, err := os.ReadFile(server.config.LogFile); err == nil && strings.Contains(string(content), substr) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("файл лога не содержит %q", substr)
+}
+
+// TestLogSnapshotSkipsPartialLine проверяет, что запросы не видят недописанную последнюю строку
+func TestLogSnapshotSkipsPartialLine(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+
+	content := "[API ] 15-10-2026 06:50:04 [INFO ] \"целая\"\n" +
+		"[API ] 15-10-2026 06:50:05 [INFO ] \"без перевода строки\"" // Поля записи еще не дописаны
+	if err := os.WriteFile(config.LogFile, []byte(content), 0644); err != nil {
+		t.Fatalf("не удалось записать файл лога: %v", err)
+	}
+
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{})
+	if err != nil {
+		t.Fatalf("ошибка чтения записей: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "целая" {
+		t.Errorf("ожидалась только целая запись, получено %+v", entries)
+	}
+
+	entries, err = server.getLogRange(RangeRequest{Start: 1, Count: 10})
+	if err != nil {
+		t.Fatalf("ошибка чтения диапазона: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("диапазон не должен включать недописанную строку, получено %+v", entries)
+	}
+}
+
+// TestLogSnapshotDoesNotBlockWrites проверяет, что долгий обход файла не задерживает запись
+func TestLogSnapshotDoesNotBlockWrites(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.DisableSelfLog = true
+	config.CacheSize = 0
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.initLogFile(); err != nil {
+		t.Fatalf("не удалось инициализировать файл лога: %v", err)
+	}
+	defer server.logFile.file.Close()
+
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "до запроса", Timestamp: time.Now()})
+
+	reading := make(chan struct{})
+	release := make(chan struct{})
+	var seen []string
+	done := make(chan error, 1)
+	go func() {
+		done <- server.eachLogEntry(context.Background(), FilterOptions{}, func(entry LogEntry) bool {
+			seen = append(seen, entry.Message)
+			if len(seen) == 1 {
+				close(reading)
+				<-release
+			}
+			return true
+		})
+	}()
+
+	<-reading
+	written := make(chan struct{})
+	go func() {
+		server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "во время запроса", Timestamp: time.Now()})
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(2 * time.Second):
+		t.Fatal("запись не должна ждать завершения запроса")
+	}
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("ошибка чтения записей: %v", err)
+	}
+	if !slices.Equal(seen, []string{"до запроса"}) {
+		t.Errorf("запрос должен видеть файл на момент начала, получено %v", seen)
+	}
+}
+
+// TestLogServerGetRange проверяет чтение записей по диапазону строк
+func TestLogServerGetRange(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	// Start записывает служебное сообщение первой строкой, дожидаемся его записи на диск
+	waitForLogContent(t, server, "Сервер логгера запущен")
+
+	for i := 1; i <= 10; i++ {
+		service := "API"
+		if i%2 == 0 {
+			service = "DB"
+		}
+		server.writeMessage(LogMessage{
+			Service:   service,
+			Level:     INFO,
+			Message:   fmt.Sprintf("сообщение %d", i),
+			Timestamp: time.Now(),
+		})
+	}
+
+	// Служебная запись занимает две строки (сообщение и поле event),
+	// строки 3-12 содержат сообщения 1-10
+	entries, err := client.GetRange(5, 3)
+	if err != nil {
+		t.Fatalf("ошибка получения диапазона: %v", err)
+	}
+	expected := []string{"сообщение 3", "сообщение 4", "сообщение 5"}
+	if len(entries) != len(expected) {
+		t.Fatalf("ожидалось %d записей, получено %d", len(expected), len(entries))
+	}
+	for i, msg := range expected {
+		if entries[i].Message != msg {
+			t.Errorf("запись %d: ожидалось %q, получено %q", i, msg, entries[i].Message)
+		}
+	}
+
+	// Фильтр применяется внутри диапазона
+	entries, err = client.GetFilteredRange(3, 6, FilterOptions{Service: "DB"})
+	if err != nil {
+		t.Fatalf("ошибка получения диапазона с фильтром: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ожидалось 3 записи DB, получено %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Service != "DB" {
+			t.Errorf("ожидался сервис DB, получен %s", entry.Service)
+		}
+	}
+
+	// Начало за пределами файла
+	if _, err := client.GetRange(1000, 5); err == nil || !strings.Contains(err.Error(), "превышает") {
+		t.Errorf("ожидалась ошибка выхода за пределы файла, получено: %v", err)
+	}
+
+	// Некорректные параметры отклоняются на клиенте
+	if _, err := client.GetRange(0, 5); err == nil {
+		t.Error("ожидалась ошибка для нулевой начальной строки")
+	}
+	if _, err := client.GetRange(1, 0); err == nil {
+		t.Error("ожидалась ошибка для нулевого количества строк")
+	}
+}
+
+// TestLogServerGetLogEntriesWithFields проверяет чтение дополнительных полей из файла и фильтр по ним
+func TestLogServerGetLogEntriesWithFields(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	now := time.Now()
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "вход", Timestamp: now,
+		Fields: map[string]string{"user_id": "12345", "ip": "10.0.0.1"}})
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "без полей", Timestamp: now})
+	server.writeMessage(LogMessage{Service: "API", Level: WARN, Message: "выход", Timestamp: now,
+		Fields: map[string]string{"user_id": "777"}})
+
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{Service: "API", IncludeRaw: true})
+	if err != nil {
+		t.Fatalf("ошибка получения записей: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("строки полей не должны считаться отдельными записями: получено %d записей", len(entries))
+	}
+	if entries[0].Fields["user_id"] != "12345" || entries[0].Fields["ip"] != "10.0.0.1" {
+		t.Errorf("поля первой записи не восстановлены: %v", entries[0].Fields)
+	}
+	if entries[1].Fields != nil {
+		t.Errorf("запись без полей не должна иметь Fields: %v", entries[1].Fields)
+	}
+	if !strings.Contains(entries[0].Raw, "user_id: 12345") {
+		t.Errorf("Raw должен содержать строки полей: %q", entries[0].Raw)
+	}
+
+	entries, err = server.getLogEntries(context.Background(), FilterOptions{FieldMatch: map[string]string{"user_id": "12345"}})
+	if err != nil {
+		t.Fatalf("ошибка получения записей: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "вход" {
+		t.Errorf("ожидалась 1 запись с user_id=12345, получено %+v", entries)
+	}
+
+	// Диапазон строк учитывает строки полей: запись "без полей" находится на строке 4
+	entries, err = server.getLogRange(RangeRequest{Start: 4, Count: 2})
+	if err != nil {
+		t.Fatalf("ошибка получения диапазона: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "без полей" || entries[1].Fields["user_id"] != "777" {
+		t.Errorf("неверные записи диапазона: %+v", entries)
+	}
+}
+
+// TestClientGetLogEntriesReturnsFields проверяет, что поля сообщения возвращаются клиенту без потерь
+func TestClientGetLogEntriesReturnsFields(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	if err := client.Info("запрос обработан", map[string]string{"user_id": "12345", "path": "/api/v1"}); err != nil {
+		t.Fatalf("ошибка отправки сообщения: %v", err)
+	}
+	if err := client.Info("следующее сообщение"); err != nil {
+		t.Fatalf("ошибка отправки сообщения: %v", err)
+	}
+	waitForLogContent(t, server, "следующее сообщение")
+
+	entries, err := client.GetLogEntries(FilterOptions{Service: "MAIN"})
+	if err != nil {
+		t.Fatalf("ошибка получения записей: %v", err)
+	}
+
+	var found *LogEntry
+	for i := range entries {
+		if entries[i].Message == "запрос обработан" {
+			found = &entries[i]
+		}
+		if strings.HasPrefix(entries[i].Message, "user_id") || strings.HasPrefix(entries[i].Message, "path") {
+			t.Errorf("строка поля не должна возвращаться отдельной записью: %+v", entries[i])
+		}
+	}
+	if found == nil {
+		t.Fatalf("запись с полями не найдена среди %d записей", len(entries))
+	}
+	if len(found.Fields) != 2 || found.Fields["user_id"] != "12345" || found.Fields["path"] != "/api/v1" {
+		t.Errorf("поля записи повреждены: %v", found.Fields)
+	}
+}
+
+// TestEntryScannerContinuationLines проверяет отнесение строк продолжения к предыдущей записи
+func TestEntryScannerContinuationLines(t *testing.T) {
+	server := &LogServer{}
+	content := strings.Join([]string{
+		`[API  ] 01-01-2024 12:00:00 [INFO ] "первая"`,
+		`    user_id: 1`,
+		`      произвольное продолжение`,
+		`мусор без формата`,
+		`    осиротевшее поле: 2`,
+		`[DB   ] 01-01-2024 12:00:01 [ERROR] "вторая"`,
+		`    code: 500`,
+	}, "\n")
+
+	scanner := server.newEntryScanner(strings.NewReader(content))
+	var entries []LogEntry
+	var lines []int
+	for scanner.Scan() {
+		entries = append(entries, scanner.Entry())
+		lines = append(lines, scanner.Line())
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("ожидалось 2 записи, получено %d", len(entries))
+	}
+	if entries[0].Fields["user_id"] != "1" || len(entries[0].Fields) != 1 {
+		t.Errorf("неверные поля первой записи: %v", entries[0].Fields)
+	}
+	if !strings.Contains(entries[0].Raw, "произвольное продолжение") {
+		t.Errorf("строка продолжения должна попасть в Raw: %q", entries[0].Raw)
+	}
+	if entries[1].Fields["code"] != "500" {
+		t.Errorf("неверные поля второй записи: %v", entries[1].Fields)
+	}
+	if lines[0] != 1 || lines[1] != 6 {
+		t.Errorf("неверные номера строк записей: %v", lines)
+	}
+	if scanner.LinesRead() != 7 {
+		t.Errorf("ожидалось 7 прочитанных строк, получено %d", scanner.LinesRead())
+	}
+}
+
+// TestParseFieldLine проверяет разбор строк дополнительных полей
+func TestParseFieldLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		key   string
+		value string
+		ok    bool
+	}{
+		{"    user_id: 12345", "user_id", "12345", true},
+		{"    url: http://host:8080/path", "url", "http://host:8080/path", true},
+		{"    empty: ", "empty", "", true},
+		{"user_id: 12345", "", "", false},
+		{"    без разделителя", "", "", false},
+		{"    : значение", "", "", false},
+	}
+
+	for _, tt := range tests {
+		key, value, ok := parseFieldLine(tt.line)
+		if ok != tt.ok || key != tt.key || value != tt.value {
+			t.Errorf("parseFieldLine(%q) = (%q, %q, %v), ожидалось (%q, %q, %v)",
+				tt.line, key, value, ok, tt.key, tt.value, tt.ok)
+		}
+	}
+}
+
+// TestLogServerGetLogEntriesFromCache проверяет, что недавние записи читаются из кеша без открытия файла
+func TestLogServerGetLogEntriesFromCache(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.CacheSize = DEFAULT_CACHE_SIZE
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	for i := 0; i < 3; i++ {
+		server.writeMessage(LogMessage{
+			Service:   "API",
+			Level:     INFO,
+			Message:   fmt.Sprintf("сообщение %d", i),
+			Timestamp: time.Now(),
+		})
+	}
+
+	// Удаляем файл: любой запрос, требующий чтения диска, завершится ошибкой
+	if err := os.Remove(config.LogFile); err != nil {
+		t.Fatalf("не удалось удалить файл лога: %v", err)
+	}
+
+	since := fileTimestamp(server.stats.StartTime)
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{StartTime: &since, Service: "API", Limit: 2})
+	if err != nil {
+		t.Fatalf("запрос должен обслуживаться из кеша: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "сообщение 0" || entries[1].Message != "сообщение 1" {
+		t.Fatalf("неожиданные записи из кеша: %+v", entries)
+	}
+	if server.stats.CacheHits != 1 {
+		t.Errorf("ожидалось 1 попадание в кеш, получено %d", server.stats.CacheHits)
+	}
+
+	// Интервал до запуска сервера может включать записи прошлых запусков - только файл
+	before := since.Add(-time.Hour)
+	if _, err := server.getLogEntries(context.Background(), FilterOptions{StartTime: &before}); err == nil {
+		t.Error("запрос за интервал до запуска сервера должен читать файл")
+	}
+	if server.stats.CacheMisses != 1 {
+		t.Errorf("ожидался 1 промах кеша, получено %d", server.stats.CacheMisses)
+	}
+
+	// Без начала интервала кеш не используется
+	if _, err := server.getLogEntries(context.Background(), FilterOptions{}); err == nil {
+		t.Error("запрос без начала интервала должен читать файл")
+	}
+}
+
+// TestLogServerCacheConfig проверяет настройку и отключение кеша сервера
+func TestLogServerCacheConfig(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.CacheSize = 5
+	config.CacheTTL = time.Minute
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if server.cache == nil || server.cache.maxSize != 5 || server.cache.ttl != time.Minute {
+		t.Error("кеш должен быть создан с параметрами из конфигурации")
+	}
+	_ = server.Stop()
+
+	// Нулевой размер отключает кеш, запись в файл продолжает работать
+	config = createTestServerConfig(t)
+	server, err = NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	if server.cache != nil {
+		t.Fatal("кеш должен быть отключен при CacheSize = 0")
+	}
+
+	server.batchMu.Lock()
+	server.writeBatch = append(server.writeBatch, &LogMessage{
+		Service: "TEST", Level: INFO, Message: "без кеша", Timestamp: time.Now(),
+	})
+	server.flushBatch()
+	server.batchMu.Unlock()
+
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{Service: "TEST"})
+	if err != nil || len(entries) != 1 {
+		t.Errorf("ожидалась 1 запись из файла, получено %d (ошибка: %v)", len(entries), err)
+	}
+
+	// Отрицательные значения отклоняются
+	for _, mutate := range []func(*LoggingConfig){
+		func(c *LoggingConfig) { c.CacheSize = -1 },
+		func(c *LoggingConfig) { c.CacheTTL = -time.Second },
+	} {
+		bad := createTestServerConfig(t)
+		mutate(bad)
+		if _, err := NewLogServer(bad); err == nil {
+			t.Error("ожидалась ошибка для отрицательных параметров кеша")
+		}
+	}
+}
+
+// TestLogServerHealth проверяет отчет о состоянии записи лога
+func TestLogServerHealth(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	if err := client.Info("проверка состояния"); err != nil {
+		t.Fatalf("ошибка отправки сообщения: %v", err)
+	}
+	waitForLogContent(t, server, "проверка состояния")
+
+	status, err := client.Health()
+	if err != nil {
+		t.Fatalf("ошибка запроса состояния: %v", err)
+	}
+	if !status.Writable || status.Error != "" {
+		t.Errorf("файл лога должен быть доступен для записи: %+v", status)
+	}
+	if status.LogFile != config.LogFile {
+		t.Errorf("ожидался файл %s, получен %s", config.LogFile, status.LogFile)
+	}
+	if status.FileSize <= 0 {
+		t.Errorf("размер файла должен быть положительным, получен %d", status.FileSize)
+	}
+	if status.FreeDiskBytes == 0 {
+		t.Error("свободное место должно быть известно (больше 0) или помечено как -1")
+	}
+	if status.BufferSize != config.BufferSize {
+		t.Errorf("ожидалась емкость буфера %d, получена %d", config.BufferSize, status.BufferSize)
+	}
+
+	// Удаленный файл лога больше не считается доступным для записи
+	if err := os.Remove(config.LogFile); err != nil {
+		t.Fatalf("не удалось удалить файл лога: %v", err)
+	}
+	status = server.Health()
+	if status.Writable || status.Error == "" {
+		t.Errorf("удаленный файл лога не должен считаться доступным: %+v", status)
+	}
+}
+
+// TestLogServerWriteErrors проверяет учет ошибок записи в файл лога
+// Незаписанные сообщения не занимают порядковые номера и не попадают в приемники
+func TestLogServerWriteErrors(t *testing.T) {
+	config := createTestServerConfig(t)
+	sink := &memorySink{}
+	server, err := NewLogServer(config, sink)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	// Подменяем файл дескриптором только для чтения, чтобы запись завершалась ошибкой
+	server.mu.Lock()
+	writable := server.logFile.file
+	readOnly, err := os.Open(config.LogFile)
+	if err != nil {
+		server.mu.Unlock()
+		t.Fatalf("не удалось открыть файл лога: %v", err)
+	}
+	server.logFile.file = readOnly
+	server.mu.Unlock()
+
+	before := atomic.LoadInt64(&server.stats.TotalMessages)
+	seqBefore := atomic.LoadInt64(&server.lastSeq)
+
+	server.batchMu.Lock()
+	for i := 0; i < 2; i++ {
+		server.writeBatch = append(server.writeBatch, &LogMessage{
+			Service:   "TEST",
+			Level:     INFO,
+			Message:   "не будет записано",
+			Timestamp: time.Now(),
+		})
+	}
+	server.flushBatch()
+	server.batchMu.Unlock()
+	server.writeMessage(LogMessage{Service: "TEST", Level: INFO, Message: "тоже не будет записано", Timestamp: time.Now()})
+
+	if total := atomic.LoadInt64(&server.stats.TotalMessages); total != before {
+		t.Errorf("незаписанные сообщения не должны учитываться в TotalMessages: было %d, стало %d", before, total)
+	}
+
+	status := server.Health()
+	if status.Writable || status.LastWriteError == "" {
+		t.Errorf("ошибка записи должна отражаться в состоянии: %+v", status)
+	}
+	if status.FailedMessages != 3 {
+		t.Errorf("ожидалось 3 незаписанных сообщения, получено %d", status.FailedMessages)
+	}
+	if seq := atomic.LoadInt64(&server.lastSeq); seq != seqBefore {
+		t.Errorf("незаписанные сообщения не должны занимать номера: было %d, стало %d", seqBefore, seq)
+	}
+
+	// После успешной записи ошибка сбрасывается
+	server.mu.Lock()
+	server.logFile.file = writable
+	server.mu.Unlock()
+	_ = readOnly.Close()
+
+	server.writeMessage(LogMessage{Service: "TEST", Level: INFO, Message: "запись восстановлена", Timestamp: time.Now()})
+
+	status = server.Health()
+	if !status.Writable || status.LastWriteError != "" {
+		t.Errorf("после успешной записи ошибка должна сбрасываться: %+v", status)
+	}
+	if total := atomic.LoadInt64(&server.stats.TotalMessages); total != before+1 {
+		t.Errorf("ожидалось %d сообщений, получено %d", before+1, total)
+	}
+
+	// Приемник получает только записанное сообщение с первым свободным номером
+	_ = server.Stop()
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.entries) != 1 || sink.entries[0].Message != "запись восстановлена" || sink.entries[0].Seq != seqBefore+1 {
+		t.Errorf("неожиданные записи приемника: %+v", sink.entries)
+	}
+}
+
+// TestRotateUnderConcurrentWrites проверяет, что ротация во время записи не теряет сообщений
+func TestRotateUnderConcurrentWrites(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.MaxFiles = 50
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	const writers = 4
+	const perWriter = 200
+	const rotations = 10
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				server.writeMessage(LogMessage{
+					Service:   "TEST",
+					Level:     INFO,
+					Message:   fmt.Sprintf("писатель %d сообщение %d", w, i),
+					Timestamp: time.Now(),
+				})
+			}
+		}(w)
+	}
+
+	for i := 0; i < rotations; i++ {
+		server.mu.Lock()
+		if err := server.rotateIfNeeded(); err != nil {
+			server.mu.Unlock()
+			t.Fatalf("ошибка ротации: %v", err)
+		}
+		server.mu.Unlock()
+
+		if _, err := os.Stat(config.LogFile); err != nil {
+			t.Fatalf("файл лога должен существовать после ротации: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if _, err := os.Stat(config.LogFile + ".new"); !os.IsNotExist(err) {
+		t.Error("временный файл ротации не должен оставаться на диске")
+	}
+
+	// Все сообщения должны найтись в текущем или архивных файлах
+	total := 0
+	files, _ := filepath.Glob(config.LogFile + "*")
+	for _, name := range files {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("не удалось прочитать %s: %v", name, err)
+		}
+		total += strings.Count(string(content), "писатель ")
+	}
+	if total != writers*perWriter {
+		t.Errorf("ожидалось %d сообщений во всех файлах, найдено %d", writers*perWriter, total)
+	}
+	if rotated := atomic.LoadInt64(&server.stats.FileRotations); rotated != rotations {
+		t.Errorf("ожидалось %d ротаций, получено %d", rotations, rotated)
+	}
+}
+
+// TestRotateRemovesExpiredFiles проверяет удаление резервных копий старше MaxFileAge
+func TestRotateRemovesExpiredFiles(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.MaxFiles = 5
+	config.MaxFileAge = 24 * time.Hour
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	old := time.Now().Add(-48 * time.Hour)
+	backups := map[string]time.Time{
+		config.LogFile + ".1": time.Now(),
+		config.LogFile + ".2": old,
+		config.LogFile + ".3": old,
+	}
+	for name, mtime := range backups {
+		if err := os.WriteFile(name, []byte("архив\n"), 0644); err != nil {
+			t.Fatalf("не удалось создать %s: %v", name, err)
+		}
+		if err := os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatalf("не удалось изменить время %s: %v", name, err)
+		}
+	}
+	// Посторонний файл с похожим именем не должен затрагиваться
+	unrelated := config.LogFile + ".bak"
+	if err := os.WriteFile(unrelated, nil, 0644); err != nil {
+		t.Fatalf("не удалось создать %s: %v", unrelated, err)
+	}
+	_ = os.Chtimes(unrelated, old, old)
+
+	server.mu.Lock()
+	err = server.rotateIfNeeded()
+	server.mu.Unlock()
+	if err != nil {
+		t.Fatalf("ошибка ротации: %v", err)
+	}
+
+	// После ротации: .1 - бывший активный файл, .2 - свежая копия, .3 и .4 - устаревшие
+	for _, name := range []string{config.LogFile, config.LogFile + ".1", config.LogFile + ".2", unrelated} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("файл %s должен сохраниться: %v", name, err)
+		}
+	}
+	for _, name := range []string{config.LogFile + ".3", config.LogFile + ".4"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("устаревший файл %s должен быть удален", name)
+		}
+	}
+
+	// Отрицательный возраст отклоняется
+	config.MaxFileAge = -time.Hour
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("ожидалась ошибка для отрицательного MaxFileAge")
+	}
+}
+
+// TestRotateEnforcesTotalSize проверяет удаление старых копий при превышении MaxTotalSize
+func TestRotateEnforcesTotalSize(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.MaxFiles = 10
+	config.MaxTotalSize = 250
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	chunk := []byte(strings.Repeat("x", 99) + "\n")
+	for _, suffix := range []string{".1", ".2", ".3", ".7.gz"} {
+		if err := os.WriteFile(config.LogFile+suffix, chunk, 0644); err != nil {
+			t.Fatalf("не удалось создать копию %s: %v", suffix, err)
+		}
+	}
+
+	server.mu.Lock()
+	if _, err := server.logFile.file.Write(chunk); err != nil {
+		server.mu.Unlock()
+		t.Fatalf("ошибка записи: %v", err)
+	}
+	server.currentSize = int64(len(chunk))
+	err = server.rotateIfNeeded()
+	server.mu.Unlock()
+	if err != nil {
+		t.Fatalf("ошибка ротации: %v", err)
+	}
+
+	// Было: активный файл и 4 копии по 100 байт, после ротации остаются две самые новые копии
+	for _, suffix := range []string{"", ".1", ".2"} {
+		if _, err := os.Stat(config.LogFile + suffix); err != nil {
+			t.Errorf("файл %s должен сохраниться: %v", suffix, err)
+		}
+	}
+	for _, suffix := range []string{".3", ".4", ".7.gz"} {
+		if _, err := os.Stat(config.LogFile + suffix); !os.IsNotExist(err) {
+			t.Errorf("старая копия %s должна быть удалена", suffix)
+		}
+	}
+}
+
+// TestLogServerBufferHighWater проверяет учет заполненности буфера
+func TestLogServerBufferHighWater(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	// Сервер не запущен, поэтому буфер никто не читает
+	for i := 0; i < 3; i++ {
+		server.handleLogMessage(rawJSON(t, map[string]interface{}{
+			"service": "TEST",
+			"level":   INFO,
+			"message": fmt.Sprintf("сообщение %d", i),
+		}), "client")
+	}
+	<-server.buffer
+
+	stats := server.Stats()
+	if stats.BufferHighWater != 3 {
+		t.Errorf("ожидался максимум заполненности 3, получено %d", stats.BufferHighWater)
+	}
+	if stats.BufferUsed != 2 {
+		t.Errorf("ожидалось 2 сообщения в буфере, получено %d", stats.BufferUsed)
+	}
+	if stats.BufferSize != config.BufferSize {
+		t.Errorf("ожидалась емкость буфера %d, получено %d", config.BufferSize, stats.BufferSize)
+	}
+
+	// Меньшая заполненность не уменьшает максимум
+	server.updateBufferHighWater(1)
+	if got := server.Stats().BufferHighWater; got != 3 {
+		t.Errorf("максимум заполненности не должен уменьшаться, получено %d", got)
+	}
+}
+
+// TestClientConnectionPool проверяет отправку сообщений через пул соединений
+func TestClientConnectionPool(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.ClientPoolSize = 3
+	config.RateLimit = 1000
+	server, client := startTestServerWithClient(t, config)
+
+	if len(client.pool) != 3 {
+		t.Fatalf("ожидалось 3 соединения в пуле, получено %d", len(client.pool))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := client.Info(fmt.Sprintf("сообщение пула %d", i)); err != nil {
+				t.Errorf("ошибка отправки сообщения: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Запросы идут через основное соединение и не смешиваются с потоком сообщений
+	if err := client.Ping(); err != nil {
+		t.Fatalf("ошибка ping: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.Flush()
+		content, _ := os.ReadFile(config.LogFile)
+		if count := strings.Count(string(content), "сообщение пула"); count == 30 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("ожидалось 30 сообщений в файле, найдено %d", count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if clients := atomic.LoadInt64(&server.stats.TotalClients); clients != 4 {
+		t.Errorf("ожидалось 4 подключения (пул и основное), получено %d", clients)
+	}
+}
+
+// TestServerSyncPolicy проверяет, когда сервер синхронизирует файл при разных политиках
+func TestServerSyncPolicy(t *testing.T) {
+	tests := []struct {
+		policy SyncPolicy
+		syncs  int64 // Ожидаемое число fsync после INFO, ERROR, пакета из INFO и пакета с ERROR
+	}{
+		{"", 2},
+		{SyncOnError, 2},
+		{SyncInterval, 0},
+		{SyncAlways, 4},
+		{SyncNever, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			config := createTestServerConfig(t)
+			config.SyncPolicy = tt.policy
+			server, err := NewLogServer(config)
+			if err != nil {
+				t.Fatalf("не удалось создать сервер: %v", err)
+			}
+			defer server.Stop()
+
+			now := time.Now()
+			server.writeMessage(LogMessage{Service: "TEST", Level: INFO, Message: "инфо", Timestamp: now})
+			server.writeMessage(LogMessage{Service: "TEST", Level: ERROR, Message: "ошибка", Timestamp: now})
+
+			server.batchMu.Lock()
+			server.writeBatch = append(server.writeBatch, &LogMessage{Service: "TEST", Level: INFO, Message: "пакет", Timestamp: now})
+			server.flushBatch()
+			server.writeBatch = append(server.writeBatch,
+				&LogMessage{Service: "TEST", Level: INFO, Message: "пакет", Timestamp: now},
+				&LogMessage{Service: "TEST", Level: ERROR, Message: "ошибка в пакете", Timestamp: now})
+			server.flushBatch()
+			server.batchMu.Unlock()
+
+			if syncs := server.Stats().FileSyncs; syncs != tt.syncs {
+				t.Errorf("ожидалось %d вызовов fsync, получено %d", tt.syncs, syncs)
+			}
+
+			// Явный сброс синхронизирует файл при любой политике
+			server.Flush()
+			if syncs := server.Stats().FileSyncs; syncs != tt.syncs+1 {
+				t.Errorf("Flush должен синхронизировать файл: ожидалось %d, получено %d", tt.syncs+1, syncs)
+			}
+		})
+	}
+
+	config := createTestServerConfig(t)
+	config.SyncPolicy = "sometimes"
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("ожидалась ошибка для неизвестной политики синхронизации")
+	}
+}
+
+// TestServerLevelsWithoutFileLock проверяет, что проверка уровня не ждет мьютекс записи в файл
+func TestServerLevelsWithoutFileLock(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	level := DEBUG
+	server.setServiceLevel("DB", &level)
+
+	// Мьютекс записи удерживается, как во время медленного fsync
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	done := make(chan LogLevel)
+	go func() {
+		done <- server.levelFor("DB")
+	}()
+
+	select {
+	case got := <-done:
+		if got != DEBUG {
+			t.Errorf("ожидался уровень DEBUG, получен %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("проверка уровня заблокирована мьютексом записи в файл")
+	}
+}
+
+// TestServerServiceLevels проверяет, что уровень сервиса переопределяет общий уровень сервера
+func TestServerServiceLevels(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	defer server.Stop()
+
+	var out bytes.Buffer
+	server.handleSetServiceLevel(rawJSON(t, ServiceLevelRequest{Service: "db", Level: "debug"}), json.NewEncoder(&out))
+
+	var response ProtocolMessage
+	if err := json.NewDecoder(&out).Decode(&response); err != nil || response.Type != MsgTypeResponse {
+		t.Fatalf("ожидался успешный ответ, получено %+v (%v)", response, err)
+	}
+
+	now := time.Now()
+	server.handleLogMessage(rawJSON(t, LogMessage{Service: "DB", Level: DEBUG, Message: "отладка DB", Timestamp: now}), "test")
+	server.handleLogMessage(rawJSON(t, LogMessage{Service: "API", Level: DEBUG, Message: "отладка API", Timestamp: now}), "test")
+	server.handleLogMessage(rawJSON(t, LogMessage{Service: "API", Level: INFO, Message: "инфо API", Timestamp: now}), "test")
+	waitForLogContent(t, server, "инфо API")
+
+	content, _ := os.ReadFile(config.LogFile)
+	if !strings.Contains(string(content), "отладка DB") {
+		t.Error("DEBUG сообщение DB должно быть записано")
+	}
+	if strings.Contains(string(content), "отладка API") {
+		t.Error("DEBUG сообщение API должно быть отброшено")
+	}
+
+	// Снятие переопределения возвращает DB к общему уровню
+	server.handleSetServiceLevel(rawJSON(t, ServiceLevelRequest{Service: "DB"}), json.NewEncoder(&out))
+	if level := server.levelFor("DB"); level != INFO {
+		t.Errorf("после сброса ожидался общий уровень INFO, получен %v", level)
+	}
+
+	// Некорректные запросы отклоняются
+	out.Reset()
+	server.handleSetServiceLevel(rawJSON(t, ServiceLevelRequest{Service: "DB", Level: "verbose"}), json.NewEncoder(&out))
+	if err := json.NewDecoder(&out).Decode(&response); err != nil || response.Type != MsgTypeError {
+		t.Errorf("ожидалась ошибка для неверного уровня, получено %+v", response)
+	}
+}
+
+// TestClientSetServiceLevel проверяет уровень сервиса через клиент
+func TestClientSetServiceLevel(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.FlushInterval = time.Hour
+	_, client := startTestServerWithClient(t, config)
+
+	if err := client.SetServiceLevel("db", DEBUG); err != nil {
+		t.Fatalf("ошибка SetServiceLevel: %v", err)
+	}
+
+	_ = client.SetService("DB").Debug("запрос к базе")
+	_ = client.SetService("API").Debug("запрос к API")
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка Flush: %v", err)
+	}
+
+	content, _ := os.ReadFile(config.LogFile)
+	if !strings.Contains(string(content), "запрос к базе") {
+		t.Error("DEBUG сообщение DB должно быть записано")
+	}
+	if strings.Contains(string(content), "запрос к API") {
+		t.Error("DEBUG сообщение API должно быть отброшено")
+	}
+
+	if err := client.ResetServiceLevel("DB"); err != nil {
+		t.Fatalf("ошибка ResetServiceLevel: %v", err)
+	}
+	if level := client.levelFor("DB"); level != INFO {
+		t.Errorf("после сброса ожидался локальный уровень INFO, получен %v", level)
+	}
+}
+
+// TestServerRegisterService проверяет регистрацию сервиса при RestrictServices и пересчет ширины колонки
+func TestServerRegisterService(t *testing.T) {
+	config := createTestServerConfig(t)
+	services := make([]string, 1, 4)
+	services[0] = "API"
+	config.Services = services
+	config.RestrictServices = true
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	if server.serviceAllowed("PLUGIN_LOADER") {
+		t.Fatal("незарегистрированный сервис не должен быть разрешен")
+	}
+
+	var out bytes.Buffer
+	server.handleRegisterService(rawJSON(t, "plugin_loader"), json.NewEncoder(&out))
+
+	var response ProtocolMessage
+	if err := json.NewDecoder(&out).Decode(&response); err != nil || response.Type != MsgTypeResponse {
+		t.Fatalf("ожидался успешный ответ, получено %+v (%v)", response, err)
+	}
+	if !server.serviceAllowed("PLUGIN_LOADER") {
+		t.Error("зарегистрированный сервис должен быть разрешен")
+	}
+	if server.maxServiceLen != len("PLUGIN_LOADER") {
+		t.Errorf("ширина колонки сервиса должна вырасти до %d, получено %d", len("PLUGIN_LOADER"), server.maxServiceLen)
+	}
+
+	// Повторная регистрация не дублирует сервис в списке
+	server.registerService("PLUGIN_LOADER")
+	if len(server.services) != 2 {
+		t.Errorf("ожидалось 2 разрешенных сервиса, получено %v", server.services)
+	}
+
+	// Конфигурация приложения и ее массив не меняются
+	if len(config.Services) != 1 || services[:2][1] != "" {
+		t.Errorf("регистрация не должна менять конфигурацию приложения: %v", services[:2])
+	}
+
+	// Недопустимое имя отклоняется
+	out.Reset()
+	server.handleRegisterService(rawJSON(t, "bad name!"), json.NewEncoder(&out))
+	if err := json.NewDecoder(&out).Decode(&response); err != nil || response.Type != MsgTypeError {
+		t.Errorf("ожидалась ошибка для недопустимого имени, получено %+v", response)
+	}
+}
+
+// TestClientRegisterService проверяет, что сообщения зарегистрированного сервиса принимаются сервером
+func TestClientRegisterService(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Services = []string{"API"}
+	config.RestrictServices = true
+	server, client := startTestServerWithClient(t, config)
+
+	_ = client.SetService("plugin").Info("до регистрации")
+	if err := client.RegisterService("plugin"); err != nil {
+		t.Fatalf("ошибка RegisterService: %v", err)
+	}
+	_ = client.SetService("plugin").Info("после регистрации")
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка Flush: %v", err)
+	}
+
+	content, _ := os.ReadFile(config.LogFile)
+	if strings.Contains(string(content), "до регистрации") {
+		t.Error("сообщение незарегистрированного сервиса должно быть отброшено")
+	}
+	if !strings.Contains(string(content), "[PLUGIN] ") || !strings.Contains(string(content), "после регистрации") {
+		t.Errorf("сообщение зарегистрированного сервиса должно быть записано:\n%s", content)
+	}
+	if got := atomic.LoadInt64(&server.stats.RejectedServiceMessages); got != 1 {
+		t.Errorf("ожидалось 1 отброшенное сообщение, получено %d", got)
+	}
+
+	if err := client.RegisterService("bad name!"); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("ожидалась ошибка ErrInvalidRequest для недопустимого имени, получено %v", err)
+	}
+}
+
+// TestClientRegisterServiceCustomNames проверяет, что RegisterService проверяет имя
+// по AllowedServiceChars из конфигурации, как и SetService
+func TestClientRegisterServiceCustomNames(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.Security = &SecurityConfig{AllowedServiceChars: regexp.MustCompile(`^[A-Z0-9.]+$`)}
+	config.RestrictServices = true
+	_, client := startTestServerWithClient(t, config)
+
+	if err := client.SetService("api.v2").Info("сообщение"); err != nil {
+		t.Errorf("SetService должен принимать имя по шаблону конфигурации: %v", err)
+	}
+	if err := client.RegisterService("api.v2"); err != nil {
+		t.Errorf("RegisterService должен принимать имя по шаблону конфигурации: %v", err)
+	}
+	if err := client.RegisterService("api_v2"); err == nil {
+		t.Error("RegisterService должен отклонять имя вне шаблона конфигурации")
+	}
+
+	logger, err := Connect(config)
+	if err != nil {
+		t.Fatalf("не удалось подключить логгер: %v", err)
+	}
+	defer logger.Close()
+	if err := logger.RegisterService("db.v1"); err != nil {
+		t.Errorf("Logger.RegisterService должен принимать имя по шаблону конфигурации: %v", err)
+	}
+	if err := logger.RegisterService("db_v1"); err == nil {
+		t.Error("Logger.RegisterService должен отклонять имя вне шаблона конфигурации")
+	}
+}
+
+// TestClientGetServerLevel проверяет чтение действующих уровней сервера
+func TestClientGetServerLevel(t *testing.T) {
+	config := createTestServerConfig(t)
+	_, client := startTestServerWithClient(t, config)
+
+	level, err := client.GetServerLevel()
+	if err != nil {
+		t.Fatalf("ошибка GetServerLevel: %v", err)
+	}
+	if level != INFO {
+		t.Errorf("ожидался уровень INFO из конфигурации, получен %v", level)
+	}
+
+	if err := client.SetServerLevel(WARN); err != nil {
+		t.Fatalf("ошибка SetServerLevel: %v", err)
+	}
+	if err := client.SetServiceLevel("DB", TRACE); err != nil {
+		t.Fatalf("ошибка SetServiceLevel: %v", err)
+	}
+
+	if level, err = client.GetServerLevel(); err != nil || level != WARN {
+		t.Errorf("ожидался уровень WARN после SetServerLevel, получен %v (%v)", level, err)
+	}
+
+	services, err := client.GetServiceLevels()
+	if err != nil {
+		t.Fatalf("ошибка GetServiceLevels: %v", err)
+	}
+	if len(services) != 1 || services["DB"] != TRACE {
+		t.Errorf("ожидался уровень TRACE для DB, получено %v", services)
+	}
+}
+
+// TestClientServerCapabilities проверяет обмен версией протокола и устаревший тип update_level
+func TestClientServerCapabilities(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	capabilities, err := client.ServerCapabilities()
+	if err != nil {
+		t.Fatalf("ошибка ServerCapabilities: %v", err)
+	}
+	if capabilities.ProtocolVersion != PROTOCOL_VERSION {
+		t.Errorf("ожидалась версия протокола %d, получена %d", PROTOCOL_VERSION, capabilities.ProtocolVersion)
+	}
+	for _, msgType := range []string{MsgTypeSetLevel, MsgTypeUpdateLevel, MsgTypeCapabilities} {
+		if !capabilities.Supports(msgType) {
+			t.Errorf("сервер должен поддерживать тип %q", msgType)
+		}
+	}
+	if capabilities.Supports("unknown") {
+		t.Error("неизвестный тип не должен поддерживаться")
+	}
+
+	// Старые клиенты отправляют update_level, сервер должен принимать его
+	response, err := client.sendRequest(MsgTypeUpdateLevel, "ERROR")
+	if err != nil {
+		t.Fatalf("ошибка отправки update_level: %v", err)
+	}
+	if response.Type == MsgTypeError {
+		t.Fatalf("сервер отклонил update_level: %v", response.Data)
+	}
+	if level := server.levelFor(""); level != ERROR {
+		t.Errorf("ожидался уровень ERROR после update_level, получен %v", level)
+	}
+}
+
+// TestClientHandshake проверяет обмен версиями протокола при подключении
+func TestClientHandshake(t *testing.T) {
+	config := createTestServerConfig(t)
+	_, client := startTestServerWithClient(t, config)
+
+	capabilities := client.serverCaps.Load()
+	if capabilities == nil {
+		t.Fatal("клиент должен сохранить ответ сервера на приветствие")
+	}
+	if capabilities.ProtocolVersion != PROTOCOL_VERSION || !capabilities.Supports(MsgTypeHello) {
+		t.Errorf("неожиданный ответ на приветствие: %+v", capabilities)
+	}
+
+	// Запрос, которого нет в списке сервера, отклоняется без отправки
+	_, err := client.sendRequest("future_request", nil)
+	if !errors.Is(err, ErrUnsupportedMessage) {
+		t.Errorf("ожидалась ErrUnsupportedMessage, получено: %v", err)
+	}
+
+	// Сервер отвечает на неизвестный тип понятной ошибкой с версией протокола
+	client.serverCaps.Store(nil)
+	response, err := client.sendRequest("future_request", nil)
+	if err != nil {
+		t.Fatalf("ошибка отправки запроса: %v", err)
+	}
+	text, _ := response.Data.(string)
+	if response.Type != MsgTypeError || !strings.Contains(text, `"future_request"`) ||
+		!strings.Contains(text, strconv.Itoa(PROTOCOL_VERSION)) {
+		t.Errorf("неожиданный ответ на неизвестный тип: %+v", response)
+	}
+}
+
+// TestClientHandshakeLegacyServer проверяет подключение к серверу без поддержки приветствия
+func TestClientHandshakeLegacyServer(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "legacy.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("не удалось создать сокет: %v", err)
+	}
+	defer listener.Close()
+
+	// Старый сервер отвечает на приветствие ошибкой неизвестного типа
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				decoder := json.NewDecoder(conn)
+				encoder := json.NewEncoder(conn)
+				for {
+					var msg ProtocolMessage
+					if err := decoder.Decode(&msg); err != nil {
+						return
+					}
+					if msg.Type == MsgTypePing {
+						_ = encoder.Encode(ProtocolMessage{Type: MsgTypePong})
+						continue
+					}
+					_ = encoder.Encode(ProtocolMessage{Type: MsgTypeError, Data: "Неизвестный тип сообщения: " + msg.Type})
+				}
+			}(conn)
+		}
+	}()
+
+	client, err := NewLogClient(&LoggingConfig{Level: "INFO", SocketPath: socketPath})
+	if err != nil {
+		t.Fatalf("клиент должен подключаться к старому серверу: %v", err)
+	}
+	defer client.Close()
+
+	if client.serverCaps.Load() != nil {
+		t.Error("для старого сервера возможности должны быть неизвестны")
+	}
+	if err := client.Ping(); err != nil {
+		t.Errorf("запросы к старому серверу должны работать: %v", err)
+	}
+}
+
+// TestClientStreamLogEntries проверяет получение записей частями и досрочную остановку
+func TestClientStreamLogEntries(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			config := createTestServerConfig(t)
+			config.CompressResponses = compress
+			server, client := startTestServerWithClient(t, config)
+			writeTestEntries(server, 250)
+
+			var messages []string
+			err := client.StreamLogEntries(FilterOptions{Service: "BENCH"}, func(entry LogEntry) bool {
+				messages = append(messages, entry.Message)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("ошибка StreamLogEntries: %v", err)
+			}
+			if len(messages) != 250 {
+				t.Fatalf("ожидалось 250 записей, получено %d", len(messages))
+			}
+			if !strings.Contains(messages[249], "номер 249 ") {
+				t.Errorf("нарушен порядок записей: %q", messages[249])
+			}
+
+			// Лимит действует так же, как в GetLogEntries
+			count := 0
+			err = client.StreamLogEntries(FilterOptions{Service: "BENCH", Limit: 120}, func(LogEntry) bool {
+				count++
+				return true
+			})
+			if err != nil || count != 120 {
+				t.Errorf("ожидалось 120 записей по лимиту, получено %d (%v)", count, err)
+			}
+
+			// Досрочная остановка не ломает последующие запросы
+			count = 0
+			err = client.StreamLogEntries(FilterOptions{Service: "BENCH"}, func(LogEntry) bool {
+				count++
+				return count < 10
+			})
+			if err != nil || count != 10 {
+				t.Errorf("ожидалась остановка после 10 записей, получено %d (%v)", count, err)
+			}
+			if err := client.Ping(); err != nil {
+				t.Errorf("запрос после досрочной остановки завершился ошибкой: %v", err)
+			}
+		})
+	}
+}
+
+// TestClientFlush проверяет, что Flush записывает сообщения без закрытия клиента
+func TestClientFlush(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.FlushInterval = time.Hour // Периодический сброс не должен помогать тесту
+	_, client := startTestServerWithClient(t, config)
+
+	if err := client.Info("до сброса"); err != nil {
+		t.Fatalf("ошибка отправки сообщения: %v", err)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка Flush: %v", err)
+	}
+
+	content, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл лога: %v", err)
+	}
+	if !strings.Contains(string(content), "до сброса") {
+		t.Error("сообщение должно быть в файле сразу после Flush")
+	}
+
+	// Клиент продолжает работать после сброса
+	if err := client.Info("после сброса"); err != nil {
+		t.Fatalf("ошибка отправки после Flush: %v", err)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatalf("ошибка повторного Flush: %v", err)
+	}
+	content, _ = os.ReadFile(config.LogFile)
+	if !strings.Contains(string(content), "после сброса") {
+		t.Error("второе сообщение должно быть в файле после повторного Flush")
+	}
+
+	_ = client.Close()
+	if err := client.Flush(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Flush после закрытия должен вернуть ErrClosed, получено %v", err)
+	}
+}
+
+// TestFlushStreamSkipsRateLimitErrors проверяет, что ошибка лимита для прежних сообщений не считается ответом на сброс
+func TestFlushStreamSkipsRateLimitErrors(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	replies := make(chan ProtocolMessage, 2)
+	go func() {
+		decoder := json.NewDecoder(serverConn)
+		encoder := json.NewEncoder(serverConn)
+		for reply := range replies {
+			var request ProtocolMessage
+			if err := decoder.Decode(&request); err != nil || request.Type != MsgTypeFlush {
+				return
+			}
+			_ = encoder.Encode(ProtocolMessage{Type: MsgTypeError, Code: ErrorCodeRateLimited, Data: "лимит"})
+			_ = encoder.Encode(reply)
+		}
+	}()
+
+	encoder := json.NewEncoder(clientConn)
+	decoder := json.NewDecoder(clientConn)
+
+	replies <- ProtocolMessage{Type: MsgTypeResponse, Data: "flushed"}
+	if err := flushStream(clientConn, encoder, decoder); err != nil {
+		t.Errorf("ошибка лимита до ответа не должна завершать сброс: %v", err)
+	}
+
+	replies <- ProtocolMessage{Type: MsgTypeError, Code: ErrorCodeTimeout, Data: "таймаут"}
+	close(replies)
+	if err := flushStream(clientConn, encoder, decoder); !errors.Is(err, ErrServerTimeout) {
+		t.Errorf("ожидалась ошибка сброса с сервера, получено %v", err)
+	}
+}
+
+// TestClientCloseAndFlush проверяет, что после CloseAndFlush все отправленные сообщения уже в файле
+func TestClientCloseAndFlush(t *testing.T) {
+	for _, poolSize := range []int{0, 3} {
+		t.Run(fmt.Sprintf("пул %d", poolSize), func(t *testing.T) {
+			config := createTestServerConfig(t)
+			config.ClientPoolSize = poolSize
+			config.RateLimit = 1000
+			config.FlushInterval = time.Hour // Периодический сброс не должен помогать тесту
+			_, client := startTestServerWithClient(t, config)
+
+			for i := 0; i < 20; i++ {
+				if err := client.Info(fmt.Sprintf("сообщение сброса %d", i)); err != nil {
+					t.Fatalf("ошибка отправки сообщения: %v", err)
+				}
+			}
+
+			if err := client.CloseAndFlush(); err != nil {
+				t.Fatalf("ошибка CloseAndFlush: %v", err)
+			}
+
+			content, err := os.ReadFile(config.LogFile)
+			if err != nil {
+				t.Fatalf("не удалось прочитать файл лога: %v", err)
+			}
+			if count := strings.Count(string(content), "сообщение сброса"); count != 20 {
+				t.Errorf("ожидалось 20 сообщений в файле сразу после CloseAndFlush, найдено %d", count)
+			}
+
+			if err := client.CloseAndFlush(); !errors.Is(err, ErrClosed) {
+				t.Errorf("повторный CloseAndFlush должен вернуть ErrClosed, получено %v", err)
+			}
+		})
+	}
+}
+
+// BenchmarkClientSendMessage сравнивает отправку через одно соединение и через пул
+func BenchmarkClientSendMessage(b *testing.B) {
+	for _, poolSize := range []int{0, 4} {
+		b.Run(fmt.Sprintf("pool=%d", poolSize), func(b *testing.B) {
+			config := createTestServerConfig(b)
+			config.ClientPoolSize = poolSize
+			config.BufferSize = 10000
+			config.RateLimit = 1 << 30
+			_, client := startTestServerWithClient(b, config)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = client.Info("сообщение бенчмарка")
+				}
+			})
+		})
+	}
+}
+
+// TestClientRequestsUseControlConnection проверяет, что запросы идут через отдельное соединение
+func TestClientRequestsUseControlConnection(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, client := startTestServerWithClient(t, config)
+
+	if err := client.Info("сообщение до запроса"); err != nil {
+		t.Fatalf("ошибка отправки сообщения: %v", err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatalf("ошибка ping: %v", err)
+	}
+
+	client.control.mu.Lock()
+	controlConn := client.control.conn
+	client.control.mu.Unlock()
+	if controlConn == nil || controlConn == client.conn {
+		t.Fatal("запросы должны идти через отдельное соединение")
+	}
+	if clients := atomic.LoadInt64(&server.stats.TotalClients); clients != 2 {
+		t.Errorf("ожидалось 2 подключения (сообщения и запросы), получено %d", clients)
+	}
+
+	// Разрыв соединения запросов не затрагивает отправку сообщений
+	client.control.mu.Lock()
+	_ = client.control.close()
+	client.control.mu.Unlock()
+
+	if err := client.Info("сообщение после разрыва"); err != nil {
+		t.Fatalf("ошибка отправки сообщения: %v", err)
+	}
+	waitForLogContent(t, server, "сообщение после разрыва")
+
+	if err := client.Ping(); err != nil {
+		t.Fatalf("соединение запросов должно восстанавливаться: %v", err)
+	}
+}
+
+// TestNewLogServerWithListener проверяет работу с переданными слушателем и файлом лога
+func TestNewLogServerWithListener(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.FileMode = 0600
+
+	if err := os.MkdirAll(filepath.Dir(config.LogFile), 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+	file, err := os.OpenFile(config.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		t.Fatalf("не удалось открыть файл лога: %v", err)
+	}
+	listener, err := net.Listen("unix", config.SocketPath)
+	if err != nil {
+		t.Fatalf("не удалось создать сокет: %v", err)
+	}
+
+	server, err := NewLogServerWithListener(config, listener, file)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	if server.listener != listener || server.logFile.file != file {
+		t.Fatal("сервер должен использовать переданные слушатель и файл")
+	}
+
+	client, err := NewLogClient(config)
+	if err != nil {
+		_ = server.Stop()
+		t.Fatalf("не удалось создать клиент: %v", err)
+	}
+	_ = client.Info("через переданный сокет")
+	waitForLogContent(t, server, "через переданный сокет")
+	_ = client.Close()
+
+	// Права переданного файла не меняются
+	if info, err := os.Stat(config.LogFile); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("права файла лога не должны меняться: %v, %v", info.Mode().Perm(), err)
+	}
+
+	// Сокет, созданный вызывающим кодом, не удаляется при остановке
+	if err := server.Stop(); err != nil {
+		t.Fatalf("ошибка остановки сервера: %v", err)
+	}
+	if _, err := os.Stat(config.SocketPath); err != nil {
+		t.Errorf("сокетный файл должен остаться после остановки: %v", err)
+	}
+}
+
+// TestNewLogServerWithListenerWithoutSocketPath проверяет, что путь к сокету не обязателен при переданном слушателе
+func TestNewLogServerWithListenerWithoutSocketPath(t *testing.T) {
+	config := createTestServerConfig(t)
+	listener, err := net.Listen("unix", config.SocketPath)
+	if err != nil {
+		t.Fatalf("не удалось создать сокет: %v", err)
+	}
+	config.SocketPath = ""
+
+	server, err := NewLogServerWithListener(config, listener, nil)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	if server.logFile.file == nil {
+		t.Error("без переданного файла сервер должен открыть файл лога сам")
+	}
+
+	if _, err := NewLogServerWithListener(config, nil, nil); err == nil {
+		t.Error("без слушателя путь к сокету обязателен")
+	}
+}
+
+// TestKeepSocketFile проверяет, что с KeepSocketFile сервер не удаляет сокетный файл
+func TestKeepSocketFile(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.KeepSocketFile = true
+
+	server, client := startTestServerWithClient(t, config)
+	_ = client.Info("без управления сокетом")
+	waitForLogContent(t, server, "без управления сокетом")
+	_ = client.Close()
+
+	if err := server.Stop(); err != nil {
+		t.Fatalf("ошибка остановки сервера: %v", err)
+	}
+	if _, err := os.Stat(config.SocketPath); err != nil {
+		t.Fatalf("сокетный файл должен остаться после остановки: %v", err)
+	}
+
+	// Существующий сокетный файл не удаляется, поэтому новый сервер не может занять путь
+	if _, err := NewLogServer(config); err == nil {
+		t.Error("ожидалась ошибка создания сокета на занятом пути")
+	}
+	if _, err := os.Stat(config.SocketPath); err != nil {
+		t.Errorf("сокетный файл не должен удаляться при запуске: %v", err)
+	}
+}
+
+// TestSocketFileRemoved проверяет удаление прежнего сокетного файла при запуске и сокета при остановке
+func TestSocketFileRemoved(t *testing.T) {
+	config := createTestServerConfig(t)
+	if err := os.MkdirAll(filepath.Dir(config.SocketPath), 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+	if err := os.WriteFile(config.SocketPath, nil, 0644); err != nil {
+		t.Fatalf("не удалось создать прежний сокетный файл: %v", err)
+	}
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("прежний сокетный файл должен удаляться: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	if err := server.Stop(); err != nil {
+		t.Fatalf("ошибка остановки сервера: %v", err)
+	}
+	if _, err := os.Stat(config.SocketPath); !os.IsNotExist(err) {
+		t.Errorf("сокетный файл должен удаляться при остановке: %v", err)
+	}
+}
+
+// TestGetLogEntriesQueryTimeout проверяет прерывание долгого чтения лога по сроку запроса
+func TestGetLogEntriesQueryTimeout(t *testing.T) {
+	config := createTestServerConfig(t)
+	if err := os.MkdirAll(filepath.Dir(config.LogFile), 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+	var content strings.Builder
+	for i := range DEFAULT_QUERY_CHECK_LINES * 2 {
+		fmt.Fprintf(&content, "[API ] 01-02-2024 10:00:00 [INFO ] \"запись %d\"\n", i)
+	}
+	if err := os.WriteFile(config.LogFile, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("не удалось записать лог: %v", err)
+	}
+
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{})
+	if err != nil || len(entries) != DEFAULT_QUERY_CHECK_LINES*2 {
+		t.Fatalf("ожидалось %d записей, получено %d (%v)", DEFAULT_QUERY_CHECK_LINES*2, len(entries), err)
+	}
+
+	// Истекший срок прерывает чтение ошибкой, а не частью записей
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	entries, err = server.getLogEntries(ctx, FilterOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) || entries != nil {
+		t.Fatalf("ожидалась ошибка истечения срока без записей, получено %d записей (%v)", len(entries), err)
+	}
+	if code := errorCodeFor(err); code != ErrorCodeTimeout {
+		t.Errorf("ожидался код ошибки %s, получен %s", ErrorCodeTimeout, code)
+	}
+
+	// QueryTimeout задает срок запроса; отрицательное значение снимает ограничение
+	config.QueryTimeout = -1
+	ctx, cancel = server.queryContext(context.Background())
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("отрицательный QueryTimeout не должен ограничивать запрос")
+	}
+	cancel()
+
+	config.QueryTimeout = 0
+	ctx, cancel = server.queryContext(context.Background())
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Duration(DEFAULT_QUERY_TIMEOUT)*time.Second {
+		t.Error("нулевой QueryTimeout должен означать DEFAULT_QUERY_TIMEOUT")
+	}
+	cancel()
+}
+
+// TestGetLogEntriesIncludeRaw проверяет, что исходные строки возвращаются только по запросу
+func TestGetLogEntriesIncludeRaw(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	now := time.Now()
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "запрос", Timestamp: now})
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "ответ", Timestamp: now})
+
+	// Без IncludeRaw строки не возвращаются ни из файла, ни из кеша
+	since := now.Add(-time.Minute)
+	for _, filter := range []FilterOptions{{Service: "API"}, {Service: "API", StartTime: &since}} {
+		entries, err := server.getLogEntries(context.Background(), filter)
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("ожидалось 2 записи, получено %d (%v)", len(entries), err)
+		}
+		if entries[0].Raw != "" || entries[0].Message != "запрос" {
+			t.Errorf("Raw должен быть пустым без IncludeRaw: %+v", entries[0])
+		}
+	}
+
+	entries, err := server.getLogRange(RangeRequest{Start: 1, Count: 10, Filter: FilterOptions{Service: "API", IncludeRaw: true}})
+	if err != nil || len(entries) != 2 || !strings.Contains(entries[1].Raw, "ответ") {
+		t.Errorf("с IncludeRaw Raw должен содержать строку лога: %+v (%v)", entries, err)
+	}
+}
+
+// TestGetLogEntriesResponseSize проверяет ограничение объема ответа на запрос записей
+func TestGetLogEntriesResponseSize(t *testing.T) {
+	config := createTestServerConfig(t)
+	config.MaxMessageSize = 64 * 1024
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	message := strings.Repeat("x", 32*1024)
+	for range DEFAULT_MAX_RESPONSE_SIZE / len(message) {
+		server.writeMessage(LogMessage{Service: "BIG", Level: INFO, Message: message, Timestamp: time.Now()})
+	}
+
+	_, err = server.getLogEntries(context.Background(), FilterOptions{Service: "BIG"})
+	if !errors.Is(err, errResponseTooLarge) {
+		t.Fatalf("ожидалась ошибка слишком большого ответа, получена: %v", err)
+	}
+	if code := errorCodeFor(err); code != ErrorCodeInvalidRequest {
+		t.Errorf("ожидался код %s, получен %s", ErrorCodeInvalidRequest, code)
+	}
+
+	// Ограниченная выборка помещается в ответ
+	entries, err := server.getLogEntries(context.Background(), FilterOptions{Service: "BIG", Limit: 10})
+	if err != nil || len(entries) != 10 {
+		t.Errorf("ожидалось 10 записей, получено %d (%v)", len(entries), err)
+	}
+}
+
+// TestGetLogEntriesSearchRotated проверяет поиск записей в резервных копиях лога
+func TestGetLogEntriesSearchRotated(t *testing.T) {
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	defer server.Stop()
+
+	now := time.Now().Truncate(time.Second)
+	line := func(message string, ts time.Time) string {
+		return server.formatMessageAsTXT(LogMessage{Service: "API", Level: INFO, Message: message, Timestamp: ts})
+	}
+
+	// Самая старая копия сжата, как после logrotate
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte(line("третья копия", now.Add(-3*time.Hour))))
+	_ = gz.Close()
+	oldest := config.LogFile + ".2.gz"
+	if err := os.WriteFile(oldest, compressed.Bytes(), 0644); err != nil {
+		t.Fatalf("не удалось записать копию: %v", err)
+	}
+	newest := config.LogFile + ".1"
+	if err := os.WriteFile(newest, []byte(line("первая копия", now.Add(-2*time.Hour))), 0644); err != nil {
+		t.Fatalf("не удалось записать копию: %v", err)
+	}
+	_ = os.Chtimes(oldest, now.Add(-3*time.Hour), now.Add(-3*time.Hour))
+	_ = os.Chtimes(newest, now.Add(-2*time.Hour), now.Add(-2*time.Hour))
+	server.writeMessage(LogMessage{Service: "API", Level: INFO, Message: "активный", Timestamp: now.Add(-time.Hour)})
+
+	messages := func(filter FilterOptions) []string {
+		t.Helper()
+		entries, err := server.getLogEntries(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("ошибка запроса: %v", err)
+		}
+		var result []string
+		for _, entry := range entries {
+			result = append(result, entry.Message)
+		}
+		return result
+	}
+
+	since := now.Add(-4 * time.Hour)
+	if got := messages(FilterOptions{Service: "API", StartTime: &since}); !slices.Equal(got, []string{"активный"}) {
+		t.Errorf("без SearchRotated копии не должны читаться: %v", got)
+	}
+
+	got := messages(FilterOptions{Service: "API", StartTime: &since, SearchRotated: true})
+	if want := []string{"третья копия", "первая копия", "активный"}; !slices.Equal(got, want) {
+		t.Errorf("ожидались записи %v от старых к новым, получены %v", want, got)
+	}
+
+	// Смещение и лимит применяются к общему потоку записей
+	got = messages(FilterOptions{Service: "API", StartTime: &since, SearchRotated: true, Offset: 1, Limit: 1})
+	if !slices.Equal(got, []string{"первая копия"}) {
+		t.Errorf("ожидалась вторая запись общего потока, получены %v", got)
+	}
+
+	// Копии, измененные до начала интервала, не читаются
+	since = now.Add(-90 * time.Minute)
+	if got := messages(FilterOptions{Service: "API", StartTime: &since, SearchRotated: true}); !slices.Equal(got, []string{"активный"}) {
+		t.Errorf("копии старше начала интервала не должны читаться: %v", got)
+	}
+}
+
+// TestServerClientIDByPeer проверяет, что переподключение процесса не меняет его идентификатор
+// Бан и его эскалация привязаны к идентификатору, поэтому новое соединение их не сбрасывает.
+func TestServerClientIDByPeer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("учетные данные клиента unix сокета определяются только в linux")
+	}
+
+	config := createTestServerConfig(t)
+	server, err := NewLogServer(config)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	defer server.Stop()
+
+	expected := fmt.Sprintf("uid_%d_pid_%d", os.Getuid(), os.Getpid())
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", config.SocketPath)
+		if err != nil {
+			t.Fatalf("не удалось подключиться: %v", err)
+		}
+		defer conn.Close()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.clientsMu.RLock()
+		var ids []string
+		for _, id := range server.clients {
+			ids = append(ids, id)
+		}
+		server.clientsMu.RUnlock()
+
+		if len(ids) == 2 {
+			if ids[0] != expected || ids[1] != expected {
+				t.Errorf("ожидался идентификатор %s для обоих подключений, получено %v", expected, ids)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ожидалось 2 подключения, зарегистрировано %d", len(ids))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -6,12 +6,74 @@ import (
 	"time"
 )
 
+// messageSender отправитель сообщений, через который пишет ServiceLogger
+// fatal записывает сообщение уровня FATAL и завершает программу так, как это
+// принято у клиента: FakeClient, например, программу не завершает. panic
+// записывает сообщение уровня PANIC и вызывает панику с текстом после обработчиков.
+type messageSender interface {
+	sendMessage(service string, level LogLevel, message string, fields map[string]string) error
+	fatal(service string, message string, fields map[string]string) error
+	panic(service string, message string, fields map[string]string) error
+}
+
+// stderrSender выводит сообщения в stderr, когда клиент логгера недоступен
+type stderrSender struct{}
+
+func (stderrSender) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	writeToStderr(service, level, message, time.Now(), fields)
+	return nil
+}
+
+func (stderrSender) fatal(service string, message string, fields map[string]string) error {
+	writeToStderr(service, FATAL, message, time.Now(), fields)
+	exitFunc(1)
+	return nil
+}
+
+func (stderrSender) panic(service string, message string, fields map[string]string) error {
+	writeToStderr(service, PANIC, message, time.Now(), fields)
+	panic(message)
+}
+
+// MessageSender получатель сообщений логгеров сервисов
+// Собственная реализация ClientInterface реализует MessageSender, чтобы ее
+// SetService возвращал рабочий логгер: NewServiceLogger(client, service).
+type MessageSender interface {
+	SendMessage(service string, level LogLevel, message string, fields map[string]string) error
+}
+
+// senderAdapter передает сообщения логгера сервиса внешнему MessageSender
+type senderAdapter struct {
+	MessageSender
+}
+
+func (a senderAdapter) sendMessage(service string, level LogLevel, message string, fields map[string]string) error {
+	return a.SendMessage(service, level, message, fields)
+}
+
+func (a senderAdapter) fatal(service string, message string, fields map[string]string) error {
+	_ = a.SendMessage(service, FATAL, message, fields)
+	exitFunc(1)
+	return nil
+}
+
+func (a senderAdapter) panic(service string, message string, fields map[string]string) error {
+	_ = a.SendMessage(service, PANIC, message, fields)
+	panic(message)
+}
+
 // ServiceLogger логгер для конкретного сервиса
+// Нулевое значение пригодно к использованию и выводит сообщения в stderr.
 type ServiceLogger struct {
-	client  LogClientInterface
+	client  messageSender
 	service string
+	err     error             // Ошибка проверки имени сервиса, возвращается при каждой записи
+	fields  map[string]string // Поля, добавляемые к каждому сообщению (см. WithTraceID)
 }
 
+// serviceNameConfig правила имен сервисов, совпадающие с проверкой на сервере
+var serviceNameConfig = DefaultSecurityConfig()
+
 // Ensure ServiceLogger implements logger.API
 // var _ API = (*ServiceLogger)(nil)
 
@@ -21,62 +83,166 @@ func (s *ServiceLogger) SetService(service string) *ServiceLogger {
 	return s
 }
 
-// newServiceLogger создает логгер для сервиса
-func newServiceLogger(client LogClientInterface, service string) *ServiceLogger {
-	return &ServiceLogger{
+// NewServiceLogger создает логгер сервиса, отправляющий сообщения sender
+// Имя сервиса приводится к верхнему регистру и проверяется, как в SetService.
+// При sender == nil сообщения выводятся в stderr.
+func NewServiceLogger(sender MessageSender, service string) *ServiceLogger {
+	if sender == nil {
+		return newServiceLogger(stderrSender{}, service)
+	}
+	return newServiceLogger(senderAdapter{sender}, service)
+}
+
+// newServiceLogger создает логгер для сервиса с правилами имен по умолчанию
+func newServiceLogger(client messageSender, service string) *ServiceLogger {
+	return newServiceLoggerWithRules(client, service, serviceNameConfig)
+}
+
+// newServiceLoggerWithRules создает логгер для сервиса (rules == nil - правила по умолчанию)
+// Имя сервиса приводится к верхнему регистру и проверяется по тем же правилам,
+// что и на сервере. Сервер отбрасывает сообщения с недопустимым именем, учитывая
+// их только в ServerStats.InvalidServiceNames, поэтому ошибка выводится в stderr
+// сразу и возвращается при каждой записи.
+func newServiceLoggerWithRules(client messageSender, service string, rules *SecurityConfig) *ServiceLogger {
+	normalized := NormalizeServiceName(service)
+	if rules == nil {
+		rules = serviceNameConfig
+	}
+
+	serviceLogger := &ServiceLogger{
 		client:  client,
-		service: service,
+		service: normalized,
+	}
+
+	if err := ValidateServiceName(normalized, rules); err != nil {
+		serviceLogger.err = fmt.Errorf("недопустимое имя сервиса %q: %w", service, err)
+		fmt.Fprintf(os.Stderr, "zlogger: %v\n", serviceLogger.err)
 	}
+
+	return serviceLogger
+}
+
+// checkServiceName нормализует имя сервиса и проверяет его (rules == nil - правила по умолчанию)
+// Ошибка оборачивает ErrInvalidRequest, как и отказ сервера для недопустимого имени.
+func checkServiceName(service string, rules *SecurityConfig) (string, error) {
+	if rules == nil {
+		rules = serviceNameConfig
+	}
+	normalized := NormalizeServiceName(service)
+	if err := ValidateServiceName(normalized, rules); err != nil {
+		return "", fmt.Errorf("%w: недопустимое имя сервиса %q: %w", ErrInvalidRequest, service, err)
+	}
+	return normalized, nil
+}
+
+// withFields добавляет к полям сообщения поля логгера
+// Поля, переданные в вызове, имеют приоритет над полями логгера.
+func (s *ServiceLogger) withFields(fields map[string]string) map[string]string {
+	if len(s.fields) == 0 {
+		return fields
+	}
+
+	merged := make(map[string]string, len(s.fields)+len(fields))
+	for key, value := range s.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged
+}
+
+// sender возвращает клиента логгера (без клиента - вывод в stderr)
+func (s *ServiceLogger) sender() messageSender {
+	if s.client == nil {
+		return stderrSender{}
+	}
+	return s.client
+}
+
+// send отправляет сообщение сервиса клиенту логгера
+func (s *ServiceLogger) send(level LogLevel, message string, fields map[string]string) error {
+	return s.sender().sendMessage(s.service, level, message, s.withFields(fields))
+}
+
+// Err возвращает ошибку проверки имени сервиса (nil, если имя допустимо)
+func (s *ServiceLogger) Err() error {
+	return s.err
+}
+
+// Trace записывает trace сообщение с поддержкой различных типов аргументов
+func (s *ServiceLogger) Trace(args ...interface{}) error {
+	// Обрабатываем аргументы и отправляем сообщение
+	if s.err != nil {
+		return s.err
+	}
+	message, fields := processArgs(args...)
+	return s.send(TRACE, message, fields)
+}
+
+// Tracef записывает форматированное trace сообщение
+// В отличие от Trace, аргументы всегда подставляются в формат и не
+// разбираются как дополнительные поля.
+func (s *ServiceLogger) Tracef(format string, args ...interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.send(TRACE, fmt.Sprintf(format, args...), nil)
 }
 
 // Debug записывает debug сообщение с поддержкой различных типов аргументов
 func (s *ServiceLogger) Debug(args ...interface{}) error {
 	// Обрабатываем аргументы и отправляем сообщение
+	if s.err != nil {
+		return s.err
+	}
 	message, fields := processArgs(args...)
-	return s.client.sendMessage(s.service, DEBUG, message, fields)
+	return s.send(DEBUG, message, fields)
 }
 
 // Info записывает info сообщение с поддержкой различных типов аргументов
 func (s *ServiceLogger) Info(args ...interface{}) error {
 	// Обрабатываем аргументы и отправляем сообщение
+	if s.err != nil {
+		return s.err
+	}
 	message, fields := processArgs(args...)
-	return s.client.sendMessage(s.service, INFO, message, fields)
+	return s.send(INFO, message, fields)
 }
 
 // Warn записывает warning сообщение с поддержкой различных типов аргументов
 func (s *ServiceLogger) Warn(args ...interface{}) error {
 	// Обрабатываем аргументы и отправляем сообщение
+	if s.err != nil {
+		return s.err
+	}
 	message, fields := processArgs(args...)
-	return s.client.sendMessage(s.service, WARN, message, fields)
+	return s.send(WARN, message, fields)
 }
 
 // Error записывает error сообщение с поддержкой различных типов аргументов
 func (s *ServiceLogger) Error(args ...interface{}) error {
 	// Обрабатываем аргументы и отправляем сообщение
+	if s.err != nil {
+		return s.err
+	}
 	message, fields := processArgs(args...)
-	return s.client.sendMessage(s.service, ERROR, message, fields)
+	return s.send(ERROR, message, fields)
 }
 
 // Fatal записывает fatal сообщение и завершает программу
+// Вывод в stderr и завершение выполняет клиент логгера, как и в его Fatal:
+// NewNop и NewMemory не пишут в stderr, а FakeClient программу не завершает.
 func (s *ServiceLogger) Fatal(args ...interface{}) error {
 	// Обрабатываем аргументы
 	message, fields := processArgs(args...)
-
-	// Немедленный вывод в stderr, чтобы тесты могли обнаружить "fatal"
-	serviceFormatted := fmt.Sprintf("%-5s", s.service)
-	levelFormatted := fmt.Sprintf("%-5s", FATAL.String())
-	fmt.Fprintf(os.Stderr, "[%s] %s [%s] \"%s\"\n", serviceFormatted, time.Now().Format(DEFAULT_TIME_FORMAT), levelFormatted, message)
-
-	_ = s.client.sendMessage(s.service, FATAL, message, fields)
-	fmt.Fprintln(os.Stderr, "fatal")
-	os.Exit(1)
-	return nil
+	return s.sender().fatal(s.service, message, s.withFields(fields))
 }
 
 // Panic записывает panic сообщение и вызывает панику
+// Текст паники - сообщение после обработчиков клиента (см. AddHook).
 func (s *ServiceLogger) Panic(args ...interface{}) error {
 	// Обрабатываем аргументы
 	message, fields := processArgs(args...)
-	_ = s.client.sendMessage(s.service, PANIC, message, fields)
-	panic(message)
+	return s.sender().panic(s.service, message, s.withFields(fields))
 }
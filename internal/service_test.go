@@ -2,7 +2,10 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -26,6 +29,67 @@ func TestNewServiceLogger(t *testing.T) {
 	}
 }
 
+// TestNewServiceLoggerValidation проверяет нормализацию и проверку имени сервиса
+func TestNewServiceLoggerValidation(t *testing.T) {
+	mockClient := &MockLogClient{}
+
+	// Имя в нижнем регистре приводится к принятому сервером виду
+	serviceLogger := newServiceLogger(mockClient, " api ")
+	if serviceLogger.service != "API" {
+		t.Errorf("ожидался сервис 'API', получили '%s'", serviceLogger.service)
+	}
+	if serviceLogger.Err() != nil {
+		t.Errorf("нормализованное имя должно быть допустимым: %v", serviceLogger.Err())
+	}
+
+	// Недопустимые имена дают ошибку при каждой записи, сообщение не отправляется
+	for _, service := range []string{"", "API.V2", "ИМЯ", strings.Repeat("A", 33)} {
+		serviceLogger := newServiceLogger(mockClient, service)
+		if serviceLogger.Err() == nil {
+			t.Errorf("ожидалась ошибка для имени сервиса %q", service)
+			continue
+		}
+		if err := serviceLogger.Info("сообщение"); !errors.Is(err, serviceLogger.Err()) {
+			t.Errorf("запись с недопустимым именем %q должна возвращать ошибку, получено: %v", service, err)
+		}
+	}
+	if len(mockClient.messages) != 0 {
+		t.Errorf("сообщения с недопустимым именем сервиса не должны отправляться, отправлено %d", len(mockClient.messages))
+	}
+}
+
+// TestNewServiceLoggerCustomPattern проверяет имена сервисов по шаблону AllowedServiceChars
+func TestNewServiceLoggerCustomPattern(t *testing.T) {
+	rules, err := securityConfigFor(&LoggingConfig{AllowedServiceChars: `^[A-Z][A-Z0-9.-]*$`})
+	if err != nil {
+		t.Fatalf("ошибка шаблона: %v", err)
+	}
+
+	mockClient := &MockLogClient{}
+	if err := newServiceLoggerWithRules(mockClient, "auth-service.v2", rules).Err(); err != nil {
+		t.Errorf("имя по шаблону должно быть допустимым: %v", err)
+	}
+	if newServiceLoggerWithRules(mockClient, "2fa", rules).Err() == nil {
+		t.Error("имя, не соответствующее шаблону, должно давать ошибку")
+	}
+
+	if _, err := securityConfigFor(&LoggingConfig{AllowedServiceChars: `^[A-Z`}); err == nil {
+		t.Error("ожидалась ошибка для некорректного шаблона")
+	}
+	if _, err := NewLogClient(&LoggingConfig{SocketPath: "/nonexistent.sock", AllowedServiceChars: "("}); err == nil || !strings.Contains(err.Error(), "шаблон") {
+		t.Errorf("клиент должен отклонять некорректный шаблон, получено: %v", err)
+	}
+}
+
+// TestClientSetServiceNormalizes проверяет, что разные написания имени дают один логгер
+func TestClientSetServiceNormalizes(t *testing.T) {
+	client := &LogClient{serviceLoggers: make(map[string]*ServiceLogger)}
+
+	if client.SetService("api") != client.SetService("API") {
+		t.Error("SetService должен возвращать один логгер для 'api' и 'API'")
+	}
+}
+
 // TestServiceLoggerMethods проверяет методы логирования ServiceLogger
 func TestServiceLoggerMethods(t *testing.T) {
 	mockClient := &MockLogClient{}
@@ -39,6 +103,13 @@ func TestServiceLoggerMethods(t *testing.T) {
 		message string
 		wantErr bool
 	}{
+		{
+			name:    "Trace",
+			method:  func() error { return serviceLogger.Trace("trace message") },
+			level:   TRACE,
+			message: "trace message",
+			wantErr: false,
+		},
 		{
 			name:    "Debug",
 			method:  func() error { return serviceLogger.Debug("debug message") },
@@ -119,6 +190,13 @@ func TestServiceLoggerFormattedMethods(t *testing.T) {
 		expected string
 		wantErr  bool
 	}{
+		{
+			name:     "Tracef",
+			method:   func() error { return serviceLogger.Tracef("кадр %s: %d байт", "user", 42) },
+			level:    TRACE,
+			expected: "кадр user: 42 байт",
+			wantErr:  false,
+		},
 		{
 			name:     "Debug с форматированием",
 			method:   func() error { return serviceLogger.Debug(fmt.Sprintf("debug: %s %d", "test", 123)) },
@@ -183,27 +261,30 @@ func TestServiceLoggerFormattedMethods(t *testing.T) {
 	}
 }
 
-// TestServiceLoggerFatal проверяет метод Fatal (без реального os.Exit)
+// TestServiceLoggerFatal проверяет, что Fatal отправляет сообщение до завершения процесса
 func TestServiceLoggerFatal(t *testing.T) {
-	// Примечание: реальный тест Fatal сложен из-за os.Exit(1)
-	// В реальном коде нужно было бы использовать dependency injection для os.Exit
-	// Здесь мы тестируем только отправку сообщения
-
 	mockClient := &MockLogClient{}
 	service := "FATAL_TEST"
-	_ = newServiceLogger(mockClient, service) // создаем для полноты теста
+	serviceLogger := newServiceLogger(mockClient, service)
 
-	// Мы не можем вызвать реальный Fatal из-за os.Exit(1)
-	// Вместо этого тестируем логику отправки сообщения напрямую
-	err := mockClient.sendMessage(service, FATAL, "fatal error", nil)
+	var exitCode int
+	callsBeforeExit := -1
+	origExit := exitFunc
+	t.Cleanup(func() { exitFunc = origExit })
+	exitFunc = func(code int) {
+		exitCode = code
+		callsBeforeExit = len(mockClient.calls)
+	}
 
-	if err != nil {
-		t.Errorf("неожиданная ошибка при отправке FATAL сообщения: %v", err)
+	if err := serviceLogger.Fatal("fatal error"); err != nil {
+		t.Errorf("неожиданная ошибка Fatal: %v", err)
 	}
 
-	if len(mockClient.calls) != 1 {
-		t.Errorf("ожидался 1 вызов, получили %d", len(mockClient.calls))
-		return
+	if exitCode != 1 {
+		t.Errorf("ожидался код завершения 1, получили %d", exitCode)
+	}
+	if callsBeforeExit != 1 {
+		t.Fatalf("сообщение должно быть отправлено до завершения, вызовов: %d", callsBeforeExit)
 	}
 
 	call := mockClient.calls[0]
@@ -517,3 +598,56 @@ func TestServiceLoggerFatalFormatted(t *testing.T) {
 		t.Errorf("ожидалось сообщение 'simple fatal message', получили '%s'", call.Message)
 	}
 }
+
+// TestServiceLoggerWithTraceID проверяет добавление trace_id в поля сообщений
+func TestServiceLoggerWithTraceID(t *testing.T) {
+	mockClient := &MockLogClient{}
+	base := newServiceLogger(mockClient, "API")
+
+	traced := base.WithTraceID("abc-123")
+	if traced == base {
+		t.Fatal("WithTraceID должен возвращать новый логгер")
+	}
+	if base.WithTraceID("") != base {
+		t.Error("пустой идентификатор должен возвращать исходный логгер")
+	}
+
+	callFields := map[string]string{"user": "admin"}
+	_ = traced.Info("запрос", callFields)
+	_ = traced.Tracef("шаг %d", 1)
+	_ = base.Info("без трассировки")
+	_ = traced.Info("свой идентификатор", "trace_id", "override")
+
+	if len(mockClient.calls) != 4 {
+		t.Fatalf("ожидалось 4 вызова, получили %d", len(mockClient.calls))
+	}
+	if fields := mockClient.calls[0].Fields; fields["trace_id"] != "abc-123" || fields["user"] != "admin" {
+		t.Errorf("неверные поля сообщения: %v", fields)
+	}
+	if _, ok := callFields[TRACE_ID_FIELD]; ok {
+		t.Error("поля вызывающего кода не должны изменяться")
+	}
+	if fields := mockClient.calls[1].Fields; fields["trace_id"] != "abc-123" {
+		t.Errorf("форматированное сообщение должно содержать trace_id: %v", fields)
+	}
+	if fields := mockClient.calls[2].Fields; len(fields) != 0 {
+		t.Errorf("исходный логгер не должен добавлять поля: %v", fields)
+	}
+	if fields := mockClient.calls[3].Fields; fields["trace_id"] != "override" {
+		t.Errorf("поле из вызова должно иметь приоритет: %v", fields)
+	}
+
+	// Идентификатор из контекста
+	ctx := ContextWithTraceID(context.Background(), "ctx-42")
+	if id, ok := TraceIDFromContext(ctx); !ok || id != "ctx-42" {
+		t.Errorf("TraceIDFromContext вернул %q, %v", id, ok)
+	}
+	if base.WithContext(context.Background()) != base {
+		t.Error("контекст без идентификатора должен возвращать исходный логгер")
+	}
+	mockClient.Reset()
+	_ = base.WithContext(ctx).Warn("из контекста")
+	if fields := mockClient.calls[0].Fields; fields["trace_id"] != "ctx-42" {
+		t.Errorf("ожидался trace_id из контекста: %v", fields)
+	}
+}
@@ -0,0 +1,129 @@
+// sink.go - Подключаемые приемники (sinks) для записей лога
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sink приемник записей лога
+// Позволяет направлять записи во внешние системы (HTTP, S3, syslog и т.д.)
+// без изменения ядра сервера. Сервер вызывает Write для каждой записи пакета
+// из отдельной горутины приемника, поэтому медленный приемник не задерживает
+// запись в файл лога и остальные приемники.
+type Sink interface {
+	Write(entry LogEntry) error // Записывает одну запись лога
+	Close() error               // Освобождает ресурсы приемника
+}
+
+// FileSink приемник, записывающий записи в текстовый файл в TXT формате
+// Основной файл лога сервера - тоже FileSink: приемник по умолчанию, в который
+// сервер пишет пакетами синхронно, чтобы Flush и Health отражали его состояние.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink создает приемник, дописывающий записи в указанный файл
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("не указан путь к файлу")
+	}
+
+	if err := ensureDir(filepath.Dir(path), os.FileMode(DEFAULT_DIR_PERMISSIONS)); err != nil {
+		return nil, fmt.Errorf("ошибка создания директории: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, os.FileMode(DEFAULT_FILE_PERMISSIONS))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла: %w", err)
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Write записывает строку записи (Raw) в файл
+func (f *FileSink) Write(entry LogEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return fmt.Errorf("приемник закрыт")
+	}
+
+	_, err := f.file.WriteString(entry.Raw + "\n")
+	return err
+}
+
+// writeString дописывает в файл готовый текст (пакет записей основного файла лога)
+func (f *FileSink) writeString(data string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, fmt.Errorf("приемник закрыт")
+	}
+
+	return f.file.WriteString(data)
+}
+
+// Close закрывает файл приемника (повторный вызов безопасен)
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// sinkQueue очередь записей дополнительного приемника с собственной горутиной
+type sinkQueue struct {
+	sink    Sink
+	entries chan LogEntry
+	done    chan struct{}
+}
+
+// newSinkQueue создает очередь приемника и запускает горутину записи
+// onError вызывается для каждой ошибки Write приемника
+func newSinkQueue(sink Sink, size int, onError func(Sink, error)) *sinkQueue {
+	q := &sinkQueue{
+		sink:    sink,
+		entries: make(chan LogEntry, size),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(q.done)
+		for entry := range q.entries {
+			if err := q.sink.Write(entry); err != nil {
+				onError(q.sink, err)
+			}
+		}
+	}()
+
+	return q
+}
+
+// put ставит запись в очередь без ожидания (false - очередь переполнена)
+func (q *sinkQueue) put(entry LogEntry) bool {
+	select {
+	case q.entries <- entry:
+		return true
+	default:
+		return false
+	}
+}
+
+// close дожидается записи оставшихся в очереди записей и закрывает приемник
+func (q *sinkQueue) close() error {
+	close(q.entries)
+	<-q.done
+	return q.sink.Close()
+}
@@ -0,0 +1,182 @@
+// sink_test.go - Тесты для подключаемых приемников записей
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink приемник для тестов, сохраняющий записи в памяти
+type memorySink struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	closed  bool
+	failErr error
+}
+
+func (m *memorySink) Write(entry LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failErr != nil {
+		return m.failErr
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memorySink) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// TestFileSink проверяет запись и закрытие файлового приемника
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "sink.log")
+
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("не удалось создать приемник: %v", err)
+	}
+
+	if err := sink.Write(LogEntry{Raw: "[TEST ] строка"}); err != nil {
+		t.Fatalf("ошибка записи: %v", err)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("ошибка закрытия: %v", err)
+	}
+	// Повторное закрытие должно быть безопасным
+	if err := sink.Close(); err != nil {
+		t.Errorf("повторное закрытие вернуло ошибку: %v", err)
+	}
+
+	if err := sink.Write(LogEntry{Raw: "после закрытия"}); err == nil {
+		t.Error("ожидалась ошибка записи в закрытый приемник")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл: %v", err)
+	}
+	if string(content) != "[TEST ] строка\n" {
+		t.Errorf("неожиданное содержимое файла: %q", content)
+	}
+
+	if _, err := NewFileSink(""); err == nil {
+		t.Error("ожидалась ошибка для пустого пути")
+	}
+}
+
+// TestServerSinksFanOut проверяет, что ошибка одного приемника не мешает остальным
+func TestServerSinksFanOut(t *testing.T) {
+	config := createTestServerConfig(t)
+
+	failing := &memorySink{failErr: errors.New("приемник недоступен")}
+	good := &memorySink{}
+
+	server, err := NewLogServer(config, failing, good)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+
+	server.batchMu.Lock()
+	server.writeBatch = append(server.writeBatch, &LogMessage{
+		Service:   "TEST",
+		Level:     INFO,
+		Message:   "сообщение для приемников",
+		Timestamp: time.Now(),
+	})
+	server.flushBatch()
+	server.batchMu.Unlock()
+
+	// Основной файл лога должен быть записан независимо от приемников
+	content, err := os.ReadFile(config.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать файл лога: %v", err)
+	}
+	if !strings.Contains(string(content), "сообщение для приемников") {
+		t.Error("основной файл лога должен содержать сообщение")
+	}
+
+	// Stop дожидается записи очередей приемников
+	_ = server.Stop()
+
+	good.mu.Lock()
+	if len(good.entries) != 1 {
+		t.Fatalf("ожидалась 1 запись в приемнике, получено %d", len(good.entries))
+	}
+	if !strings.Contains(good.entries[0].Raw, "сообщение для приемников") {
+		t.Errorf("запись приемника не содержит сообщение: %q", good.entries[0].Raw)
+	}
+	good.mu.Unlock()
+
+	if server.stats.SinkErrors != 1 {
+		t.Errorf("ожидалась 1 ошибка приемника, получено %d", server.stats.SinkErrors)
+	}
+
+	if !failing.closed || !good.closed {
+		t.Error("приемники должны быть закрыты при остановке сервера")
+	}
+}
+
+// blockingSink приемник, который ждет разрешения на каждую запись
+type blockingSink struct {
+	memorySink
+	release chan struct{}
+}
+
+func (b *blockingSink) Write(entry LogEntry) error {
+	<-b.release
+	return b.memorySink.Write(entry)
+}
+
+// TestServerSlowSink проверяет, что медленный приемник не задерживает запись в файл лога
+func TestServerSlowSink(t *testing.T) {
+	config := createTestServerConfig(t)
+
+	slow := &blockingSink{release: make(chan struct{})}
+	server, err := NewLogServer(config, slow)
+	if err != nil {
+		t.Fatalf("не удалось создать сервер: %v", err)
+	}
+
+	// Первая запись занимает горутину приемника, остальные заполняют очередь с избытком
+	total := DEFAULT_SINK_QUEUE_SIZE + 10
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < total; i++ {
+			server.writeMessage(LogMessage{Service: "TEST", Level: INFO, Message: "запись", Timestamp: time.Now()})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("запись в файл лога заблокирована медленным приемником")
+	}
+
+	dropped := server.Stats().SinkDropped
+	if dropped < 9 {
+		t.Errorf("ожидались отброшенные записи приемника, получено %d", dropped)
+	}
+
+	close(slow.release)
+	_ = server.Stop()
+
+	slow.mu.Lock()
+	defer slow.mu.Unlock()
+	if got := int64(len(slow.entries)); got+dropped != int64(total) {
+		t.Errorf("записано %d и отброшено %d из %d записей", got, dropped, total)
+	}
+	if !slow.closed {
+		t.Error("приемник должен быть закрыт при остановке сервера")
+	}
+}
@@ -0,0 +1,54 @@
+// trace.go - Передача идентификатора трассировки в поля сообщений
+package logger
+
+import "context"
+
+// TRACE_ID_FIELD имя поля с идентификатором трассировки
+const TRACE_ID_FIELD = "trace_id"
+
+// traceIDKey ключ идентификатора трассировки в context.Context
+type traceIDKey struct{}
+
+// ContextWithTraceID возвращает контекст с идентификатором трассировки
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceIDFromContext возвращает идентификатор трассировки из контекста
+// Второе значение false, если идентификатор не задан или пуст.
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(traceIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithTraceID возвращает производный логгер, добавляющий поле trace_id
+// к каждому сообщению. Исходный логгер не меняется, поэтому производный
+// удобно создавать на время обработки одного запроса. Пустой id возвращает
+// исходный логгер.
+func (s *ServiceLogger) WithTraceID(id string) *ServiceLogger {
+	if id == "" {
+		return s
+	}
+
+	fields := make(map[string]string, len(s.fields)+1)
+	for key, value := range s.fields {
+		fields[key] = value
+	}
+	fields[TRACE_ID_FIELD] = id
+
+	derived := *s
+	derived.fields = fields
+	return &derived
+}
+
+// WithContext возвращает логгер с идентификатором трассировки из контекста
+// Если в контексте нет идентификатора, возвращается исходный логгер.
+func (s *ServiceLogger) WithContext(ctx context.Context) *ServiceLogger {
+	if id, ok := TraceIDFromContext(ctx); ok {
+		return s.WithTraceID(id)
+	}
+	return s
+}
@@ -3,6 +3,10 @@
 package zlogger
 
 import (
+	"context"
+	"net"
+	"os"
+	"regexp"
 	"time"
 
 	logger "github.com/qzeleza/zlogger/internal"
@@ -13,30 +17,186 @@ type (
 	// Logger основной интерфейс логгера для клиентских приложений
 	Logger = logger.Logger
 
+	// ClientInterface методы логгера; принимайте его вместо *Logger, чтобы подставлять FakeClient в тестах
+	ClientInterface = logger.ClientInterface
+
+	// FakeClient подставной клиент для модульных тестов (см. NewFakeClient)
+	FakeClient = logger.FakeClient
+
 	// ServiceLogger логгер для конкретного сервиса
 	ServiceLogger = logger.ServiceLogger
 
+	// MessageSender получатель сообщений логгеров сервисов собственной реализации ClientInterface
+	MessageSender = logger.MessageSender
+
 	// LogLevel уровни логирования
 	LogLevel = logger.LogLevel
 
 	// Config конфигурация системы логирования
 	Config = logger.LoggingConfig
 
+	// SecurityConfig ограничения безопасности сервера и клиента (Config.Security)
+	SecurityConfig = logger.SecurityConfig
+
 	// LogEntry запись лога для чтения
 	LogEntry = logger.LogEntry
 
+	// LogMessage сообщение лога, передаваемое обработчикам перед отправкой
+	LogMessage = logger.LogMessage
+
+	// MessageHook обработчик сообщения перед отправкой (Logger.AddHook)
+	MessageHook = logger.MessageHook
+
 	// FilterOptions опции фильтрации логов
 	FilterOptions = logger.FilterOptions
+
+	// HealthStatus состояние записи лога на сервере
+	HealthStatus = logger.HealthStatus
+
+	// ServerStats снимок статистики сервера
+	ServerStats = logger.ServerStats
+
+	// Server сервер логгера для запуска отдельно от клиента
+	Server = logger.LogServer
+
+	// Sink приемник записей лога для подключения собственных хранилищ
+	Sink = logger.Sink
+
+	// FileSink приемник, дописывающий записи в текстовый файл
+	FileSink = logger.FileSink
+
+	// MemorySink приемник, сохраняющий записи в памяти (см. NewMemory)
+	MemorySink = logger.MemorySink
+
+	// SyncPolicy политика синхронизации файла лога с диском
+	SyncPolicy = logger.SyncPolicy
+
+	// Mode режим логгера, создаваемого New: через сокет или напрямую в файл
+	Mode = logger.Mode
+
+	// Capabilities версия протокола сервера и поддерживаемые им типы сообщений
+	Capabilities = logger.Capabilities
+
+	// CSVOptions параметры выгрузки записей в CSV
+	CSVOptions = logger.CSVOptions
+
+	// CSVFieldsMode способ выгрузки дополнительных полей в CSV
+	CSVFieldsMode = logger.CSVFieldsMode
+
+	// Clock источник времени сервера и клиента (Config.Clock)
+	Clock = logger.Clock
+
+	// Ticker периодический таймер, создаваемый Clock
+	Ticker = logger.Ticker
+
+	// FakeClock управляемые часы для детерминированных тестов
+	FakeClock = logger.FakeClock
+
+	// ErrorCode код ошибки в ответе сервера
+	ErrorCode = logger.ErrorCode
+
+	// ServerError ошибка, которую сервер вернул в ответ на запрос
+	ServerError = logger.ServerError
+)
+
+// SystemClock часы на основе пакета time, используются по умолчанию
+var SystemClock = logger.SystemClock
+
+// Способы выгрузки дополнительных полей в CSV (CSVOptions.Fields)
+const (
+	CSVFieldsNone    CSVFieldsMode = logger.CSVFieldsNone    // Поля не выгружаются
+	CSVFieldsJSON    CSVFieldsMode = logger.CSVFieldsJSON    // Поля одной колонкой в виде JSON объекта
+	CSVFieldsColumns CSVFieldsMode = logger.CSVFieldsColumns // Каждое поле отдельной колонкой
+)
+
+// Языки служебных сообщений сервера (Config.Locale)
+const (
+	LocaleRussian = logger.LocaleRussian // Русский (по умолчанию)
+	LocaleEnglish = logger.LocaleEnglish // Английский
+)
+
+// ServerServiceName имя сервиса, от которого сервер пишет служебные записи
+const ServerServiceName = logger.SERVER_LOGGER_NAME
+
+// EventField имя поля служебной записи сервера с типом события
+const EventField = logger.EVENT_FIELD
+
+// Типы служебных событий сервера (значения поля EventField)
+const (
+	EventServerStart      = logger.EventServerStart      // Сервер запущен
+	EventServerStop       = logger.EventServerStop       // Сервер останавливается
+	EventLevelChange      = logger.EventLevelChange      // Изменен общий уровень или уровень сервиса
+	EventConfigReload     = logger.EventConfigReload     // Конфигурация перезагружена
+	EventRotation         = logger.EventRotation         // Файл лога ротирован
+	EventStats            = logger.EventStats            // Периодическая статистика сервера
+	EventRejectedServices = logger.EventRejectedServices // Отброшены сообщения сервисов вне списка разрешенных
+	EventClientBanned     = logger.EventClientBanned     // Клиент заблокирован за превышение лимита скорости
+	EventClientUnbanned   = logger.EventClientUnbanned   // Бан клиента истек
+	EventServiceAdded     = logger.EventServiceAdded     // Сервис зарегистрирован во время работы
+)
+
+// TraceIDField имя поля, в которое ServiceLogger.WithTraceID записывает идентификатор трассировки
+const TraceIDField = logger.TRACE_ID_FIELD
+
+// ProtocolVersion версия протокола, которую использует эта версия библиотеки
+const ProtocolVersion = logger.PROTOCOL_VERSION
+
+// Политики синхронизации файла лога (Config.SyncPolicy)
+const (
+	SyncOnError  SyncPolicy = logger.SyncOnError  // После ERROR и выше и по таймеру (по умолчанию)
+	SyncInterval SyncPolicy = logger.SyncInterval // Только по таймеру FlushInterval
+	SyncAlways   SyncPolicy = logger.SyncAlways   // После каждой записи
+	SyncNever    SyncPolicy = logger.SyncNever    // Только при явном Flush и остановке
+)
+
+// Режимы логгера (Config.Mode)
+const (
+	ModeServer Mode = logger.ModeServer // Сервер с сокетом в том же процессе
+	ModeLocal  Mode = logger.ModeLocal  // Запись в файл лога без сокета (по умолчанию без SocketPath)
+)
+
+// Ошибки подключения к серверу, которые можно проверить через errors.Is
+var (
+	ErrServerUnavailable = logger.ErrServerUnavailable // Сокет отсутствует или сервер не отвечает
+	ErrServerAtCapacity  = logger.ErrServerAtCapacity  // Сервер отклонил подключение из-за лимита
+	ErrSocketPermission  = logger.ErrSocketPermission  // Недостаточно прав для подключения к сокету
+	ErrClosed            = logger.ErrClosed            // Клиент уже закрыт
+
+	ErrUnsupportedMessage = logger.ErrUnsupportedMessage // Сервер не поддерживает запрос
+)
+
+// Ошибки запросов к серверу, которые можно проверить через errors.Is
+var (
+	ErrInvalidRequest = logger.ErrInvalidRequest // Неверный формат или параметры запроса
+	ErrLogNotFound    = logger.ErrLogNotFound    // Файл лога не найден
+	ErrLogPermission  = logger.ErrLogPermission  // Нет прав доступа к файлу лога
+	ErrRateLimited    = logger.ErrRateLimited    // Превышен лимит скорости сообщений
+	ErrServerTimeout  = logger.ErrServerTimeout  // Истекло время ожидания на сервере
+	ErrServerInternal = logger.ErrServerInternal // Прочие ошибки сервера
+)
+
+// Коды ошибок сервера (ServerError.Code)
+const (
+	ErrorCodeInvalidRequest ErrorCode = logger.ErrorCodeInvalidRequest // Неверный формат или параметры запроса
+	ErrorCodeNotFound       ErrorCode = logger.ErrorCodeNotFound       // Файл лога не найден
+	ErrorCodePermission     ErrorCode = logger.ErrorCodePermission     // Нет прав доступа к файлу лога
+	ErrorCodeRateLimited    ErrorCode = logger.ErrorCodeRateLimited    // Превышен лимит скорости сообщений
+	ErrorCodeUnsupported    ErrorCode = logger.ErrorCodeUnsupported    // Тип сообщения не поддерживается сервером
+	ErrorCodeAtCapacity     ErrorCode = logger.ErrorCodeAtCapacity     // Достигнут лимит подключений
+	ErrorCodeTimeout        ErrorCode = logger.ErrorCodeTimeout        // Истекло время ожидания на сервере
+	ErrorCodeInternal       ErrorCode = logger.ErrorCodeInternal       // Прочие ошибки сервера
 )
 
 // Экспортируемые константы уровней логирования
 const (
+	TRACE LogLevel = logger.TRACE // Трассировка, подробнее DEBUG
 	DEBUG LogLevel = logger.DEBUG // Отладочная информация
 	INFO  LogLevel = logger.INFO  // Информационные сообщения
 	WARN  LogLevel = logger.WARN  // Предупреждения
 	ERROR LogLevel = logger.ERROR // Ошибки
 	FATAL LogLevel = logger.FATAL // Критические ошибки
 	PANIC LogLevel = logger.PANIC // Паника приложения
+	OFF   LogLevel = logger.OFF   // Логирование отключено
 )
 
 // New создает новый экземпляр логгера с указанной конфигурацией
@@ -65,6 +225,9 @@ const (
 //	    panic(err)
 //	}
 //	defer log.Close()
+//
+// Без SocketPath (или с Mode: ModeLocal) сокет не создается: сообщения
+// записываются в LogFile в том же процессе, а GetLogEntries читает файл напрямую.
 func New(config *Config, services ...string) (*Logger, error) {
 	var serviceList []string
 	if len(services) > 0 {
@@ -73,6 +236,100 @@ func New(config *Config, services ...string) (*Logger, error) {
 	return logger.New(config, serviceList)
 }
 
+// Connect создает логгер, подключенный к уже запущенному серверу
+//
+// В отличие от New сервер не запускается: используется, когда сервер работает
+// в отдельном процессе (например, zlogger serve), и в утилитах чтения логов.
+// Если сервер не запущен, возвращается ошибка ErrServerUnavailable.
+func Connect(config *Config) (*Logger, error) {
+	return logger.Connect(config)
+}
+
+// NewWithClient создает логгер, передающий вызовы клиенту client
+//
+// Позволяет подставить FakeClient в тестах или собственную реализацию
+// ClientInterface с другим транспортом. Логгеры сервисов из services создаются
+// сразу. Сервер не запускается, Close закрывает client. При nil client логгер
+// ничего не записывает, как NewNop.
+func NewWithClient(client ClientInterface, services ...string) *Logger {
+	return logger.NewWithClient(client, services...)
+}
+
+// NewServiceLogger создает логгер сервиса, отправляющий сообщения sender
+//
+// Используется в SetService собственной реализации ClientInterface, которая
+// реализует MessageSender: return zlogger.NewServiceLogger(c, service).
+// При nil sender сообщения выводятся в stderr.
+func NewServiceLogger(sender MessageSender, service string) *ServiceLogger {
+	return logger.NewServiceLogger(sender, service)
+}
+
+// NewNop создает логгер, который ничего не записывает
+//
+// Не подключается к серверу и не выводит сообщения в stderr. Запросы к серверу
+// (GetLogEntries, GetServiceLevels и другие) возвращают пустые результаты без
+// ошибок. Fatal и Panic по-прежнему завершают программу и вызывают панику.
+// Подходит для тестов и библиотек, которым логгер нужен только по типу.
+func NewNop() *Logger {
+	return logger.NewNop()
+}
+
+// NewMemory создает логгер, сохраняющий записи в памяти, для модульных тестов
+//
+// Сервер и сокет не используются. Записи попадают в возвращаемый MemorySink
+// с учетом уровней логирования, полей и обработчиков сообщений, а запросы
+// GetLogEntries, GetRange и экспорт выполняются по сохраненным записям.
+// Общий уровень логирования по умолчанию - INFO.
+func NewMemory() (*Logger, *MemorySink) {
+	return logger.NewMemory()
+}
+
+// NewFakeClient создает подставной клиент для тестов кода, принимающего ClientInterface
+//
+// Записи попадают в возвращаемый MemorySink так же, как у NewMemory. Calls
+// возвращает количество вызовов метода, а FailOn задает ошибку, которую метод
+// вернет вместо записи. Fatal записывает сообщение, но не завершает программу.
+func NewFakeClient() (*FakeClient, *MemorySink) {
+	return logger.NewFakeClient()
+}
+
+// NewServer создает сервер логгера с дополнительными приемниками записей
+//
+// Параметры:
+//   - config: конфигурация сервера (обязательный)
+//   - sinks: дополнительные приемники, получающие каждую запись (опционально)
+//
+// Основной файл лога - приемник FileSink по умолчанию, он пишется синхронно.
+// Дополнительные приемники получают записи через собственные очереди, поэтому
+// медленный приемник не задерживает сервер; при переполнении очереди запись
+// для этого приемника отбрасывается и учитывается в Stats().SinkDropped.
+//
+// Сервер необходимо запустить методом Start и остановить методом Stop.
+func NewServer(config *Config, sinks ...Sink) (*Server, error) {
+	return logger.NewLogServer(config, sinks...)
+}
+
+// NewServerWithListener создает сервер с уже открытыми слушателем и файлом лога
+//
+// Используется при активации сокетом systemd и при перезапуске без простоя с
+// передачей дескрипторов: сервер не создает сокет и не меняет его права, а
+// переданный файл лога (открытый на дозапись файл config.LogFile) не открывает
+// заново. Любой из параметров может быть nil. Сокет, созданный вызывающим
+// кодом, не удаляется при остановке сервера.
+func NewServerWithListener(config *Config, listener net.Listener, file *os.File, sinks ...Sink) (*Server, error) {
+	return logger.NewLogServerWithListener(config, listener, file, sinks...)
+}
+
+// NewFileSink создает приемник, дописывающий записи в указанный файл
+func NewFileSink(path string) (*FileSink, error) {
+	return logger.NewFileSink(path)
+}
+
+// NewMemorySink создает приемник, сохраняющий записи в памяти
+func NewMemorySink() *MemorySink {
+	return logger.NewMemorySink()
+}
+
 // NewConfig создает конфигурацию с настройками по умолчанию
 //
 // Параметры:
@@ -81,23 +338,62 @@ func New(config *Config, services ...string) (*Logger, error) {
 //
 // Возвращает готовую к использованию конфигурацию по умолчанию
 func NewConfig(logFile, socketPath string) *Config {
-	return &Config{
-		Level:            "info",      // Уровень логирования
-		LogFile:          logFile,     // Путь к файлу лога
-		SocketPath:       socketPath,  // Путь к Unix сокету
-		MaxFileSize:      1,           // 1 MB
-		BufferSize:       1000,        // 1000 сообщений
-		FlushInterval:    time.Second, // 1 секунда
-		Services:         []string{},  // Пустой список сервисов
-		RestrictServices: false,       // Не ограничивать сервисы
-		Dir:              "/tmp",      // Путь к директории логов
-		MaxFiles:         3,           // Максимальное количество файлов логов
-		MaxSize:          1,           // Максимальный размер файла логов в MB
-		MaxAge:           7,           // Максимальный возраст файла логов
-		Compress:         true,        // Сжатие файлов логов
-		Console:          true,        // Вывод логов в консоль
-		MaxBackups:       3,           // Максимальное количество резервных копий
-	}
+	return logger.NewConfig(logFile, socketPath)
+}
+
+// DefaultSecurityConfig возвращает ограничения безопасности по умолчанию
+//
+// Удобно как основа для Config.Security: достаточно изменить нужные поля.
+func DefaultSecurityConfig() *SecurityConfig {
+	return logger.DefaultSecurityConfig()
+}
+
+// LoadConfig загружает конфигурацию из файла YAML или JSON
+//
+// Параметры:
+//   - path: путь к файлу (.yaml, .yml или .json); пустой путь - только значения
+//     по умолчанию и переменные окружения
+//
+// Ключи файла совпадают с yaml именами полей Config (level, log_file,
+// socket_path, flush_interval и т.д.). Отсутствующие параметры получают
+// значения NewConfig, затем применяются переменные окружения ZLOGGER_<КЛЮЧ>
+// (например, ZLOGGER_LEVEL=debug), и проверяются уровень и пути к файлу и сокету.
+func LoadConfig(path string) (*Config, error) {
+	return logger.LoadConfig(path)
+}
+
+// NewFakeClock создает управляемые часы для тестов
+//
+// Время меняется только методом Advance, который также запускает наступившие
+// срабатывания таймеров. Часы передаются серверу и клиенту через Config.Clock.
+func NewFakeClock(now time.Time) *FakeClock {
+	return logger.NewFakeClock(now)
+}
+
+// RedactHook создает обработчик для Logger.AddHook, маскирующий совпадения шаблонов
+//
+// Совпадения в тексте сообщения и значениях полей заменяются на replacement.
+// Шаблоны компилируются вызывающим кодом один раз; готовый набор для
+// распространенных случаев возвращает DefaultRedactors.
+func RedactHook(patterns []*regexp.Regexp, replacement string) MessageHook {
+	return logger.RedactHook(patterns, replacement)
+}
+
+// DefaultRedactors возвращает шаблоны для номеров банковских карт, адресов
+// электронной почты и токенов Bearer
+func DefaultRedactors() []*regexp.Regexp {
+	return logger.DefaultRedactors()
+}
+
+// ContextWithTraceID возвращает контекст с идентификатором трассировки
+// для ServiceLogger.WithContext
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return logger.ContextWithTraceID(ctx, id)
+}
+
+// TraceIDFromContext возвращает идентификатор трассировки из контекста
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	return logger.TraceIDFromContext(ctx)
 }
 
 // ParseLevel парсит строковый уровень логирования
@@ -113,7 +409,43 @@ func ParseLevel(level string) (LogLevel, error) {
 }
 
 // Глобальные функции для быстрого логирования без создания экземпляра
-// Используют простой вывод в stdout/stderr
+// Отправляют сообщения через логгер по умолчанию (SetDefault), а если он
+// не задан - выводят их в stdout
+
+// SetDefault задает логгер, через который работают глобальные функции
+//
+// Передача nil возвращает вывод в stdout. Функцию можно вызывать из разных
+// горутин одновременно с глобальными функциями логирования.
+//
+// Пример использования:
+//
+//	log, err := zlogger.New(config)
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer log.Close()
+//	zlogger.SetDefault(log)
+//	zlogger.Info("сообщение попадет в файл лога")
+func SetDefault(l *Logger) {
+	logger.SetDefault(l)
+}
+
+// Service возвращает логгер сервиса из логгера по умолчанию
+//
+// Если логгер по умолчанию не задан, возвращается логгер, выводящий
+// сообщения сервиса в stderr.
+//
+// Пример использования:
+//
+//	zlogger.Service("HTTP").Info("запрос обработан")
+func Service(name string) *ServiceLogger {
+	return logger.Service(name)
+}
+
+// Default возвращает логгер по умолчанию или nil, если он не задан
+func Default() *Logger {
+	return logger.Default()
+}
 
 // Debug выводит отладочное сообщение с поддержкой различных типов аргументов
 func Debug(args ...interface{}) {